@@ -0,0 +1,12 @@
+// Package api встраивает спецификацию OpenAPI 3 в бинарный файл. Документ
+// генерируется scripts/gen_openapi.py (make openapi) и отдается сервисом по
+// /openapi.json; соответствие спецификации маршрутам и реальным ответам
+// проверяют контрактные тесты роутера.
+package api
+
+import _ "embed"
+
+// OpenAPI - спецификация HTTP API в формате JSON
+//
+//go:embed openapi.json
+var OpenAPI []byte
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"reviewer-appointment-service/internal/config"
+	"reviewer-appointment-service/internal/storage/postgresql"
+)
+
+// cliTimeout ограничивает время работы служебных команд
+const cliTimeout = 30 * time.Second
+
+// openStorage подключается к БД по конфигурации из CONFIG_PATH
+func openStorage(ctx context.Context) (*postgresql.Storage, error) {
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "./config/config.yaml"
+	}
+	cfg := config.MustConfig(configPath)
+
+	storage, err := postgresql.NewStorage(cfg, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return storage, nil
+}
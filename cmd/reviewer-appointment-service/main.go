@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,49 +11,95 @@ import (
 
 	"reviewer-appointment-service/internal"
 	"reviewer-appointment-service/internal/config"
+	"reviewer-appointment-service/internal/logger"
 	"reviewer-appointment-service/internal/storage/postgresql"
+	"reviewer-appointment-service/internal/tracing"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		if err := runToken(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "org" {
+		if err := runOrg(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		if err := runSync(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(); err != nil {
+		slog.Error("service stopped with error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run владеет жизненным циклом процесса: единственный обработчик
+// SIGINT/SIGTERM отменяет ctx, Server.Run завершает запросы и фоновые задачи,
+// после чего отложенные вызовы закрывают пул соединений и сбрасывают трассы
+func run() error {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
 		configPath = "./config/config.yaml"
 	}
 
-	log.Printf("Loading config from: %s (CONFIG_PATH env: %s)", configPath, os.Getenv("CONFIG_PATH"))
+	// До загрузки конфигурации пишем JSON-логи с уровнем info
+	bootLogger, _ := logger.New(os.Stdout, "info")
+	slog.SetDefault(bootLogger)
+
+	slog.Info("loading config", "path", configPath)
 	cfg := config.MustConfig(configPath)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	appLogger, err := logger.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(appLogger)
 
-	log.Printf("Connecting to database: host=%s, port=%d, database=%s, user=%s",
-		cfg.Host, cfg.PortDB, cfg.Data_base, cfg.User)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	storage, err := waitForDatabase(ctx, cfg, 30*time.Second)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
 	if err != nil {
-		log.Fatalf("Failed to connect to database after retries: %v\n"+
-			"Please ensure PostgreSQL is running and accessible.\n"+
-			"You can use: docker-compose up -d (to start PostgreSQL)\n"+
-			"Or set environment variables: DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME", err)
+		return fmt.Errorf("failed to set up tracing: %w", err)
 	}
-	defer storage.Close()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
 
-	log.Println("Successfully connected to database")
+	slog.Info("connecting to database",
+		"host", cfg.Host, "port", cfg.PortDB, "database", cfg.Data_base, "user", cfg.User)
 
-	port := fmt.Sprintf("%d", cfg.Server.PortServer)
-	server := internal.NewServer(port, storage)
+	storage, err := waitForDatabase(ctx, cfg, 30*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database after retries; ensure PostgreSQL is running and accessible "+
+			"(docker-compose up -d) or set DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME: %w", err)
+	}
+	defer func() {
+		storage.Close()
+		slog.Info("database pool closed")
+	}()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	slog.Info("successfully connected to database")
 
-	go func() {
-		if err := server.Run(); err != nil {
-			log.Fatalf("Server error: %v", err)
-		}
-	}()
+	server := internal.NewServer(cfg, storage)
 
-	<-quit
-	log.Println("Shutting down server...")
+	return server.Run(ctx)
 }
 
 func createDatabaseIfNotExists(cfg *config.Config) error {
@@ -81,7 +127,7 @@ func createDatabaseIfNotExists(cfg *config.Config) error {
 		if err != nil {
 			return fmt.Errorf("failed to create database: %w", err)
 		}
-		log.Printf("Database '%s' created successfully", cfg.Data_base)
+		slog.Info("database created", "database", cfg.Data_base)
 	}
 
 	return nil
@@ -104,12 +150,18 @@ func waitForDatabase(ctx context.Context, cfg *config.Config, timeout time.Durat
 
 		if i == 0 {
 			if createErr := createDatabaseIfNotExists(cfg); createErr != nil {
-				log.Printf("Warning: failed to create database if not exists: %v", createErr)
+				slog.Warn("failed to create database if not exists", "error", createErr)
 			}
 		}
 
-		log.Printf("Database connection attempt %d/%d failed: %v. Retrying in %v...", i+1, maxRetries, err, retryDelay)
-		time.Sleep(retryDelay)
+		slog.Warn("database connection attempt failed",
+			"attempt", i+1, "max_attempts", maxRetries, "error", err, "retry_in", retryDelay.String())
+
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 
 		if i < 5 {
 			retryDelay = 2 * time.Second
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage/postgresql"
+)
+
+const orgUsage = `Usage:
+  reviewer-appointment-service org create -slug SLUG [-name NAME]
+  reviewer-appointment-service org list
+
+The slug selects the organization in the X-Org header and in the -org flag
+of the token command. Teams, users and pull requests of different
+organizations are fully isolated.
+`
+
+// runOrg управляет организациями напрямую через БД
+func runOrg(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, orgUsage)
+		return fmt.Errorf("org: subcommand is required")
+	}
+
+	cliLogger, _ := logger.New(stderr, "warn")
+	slog.SetDefault(cliLogger)
+
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("org "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var (
+		slug = fs.String("slug", "", "organization slug: lowercase letters, digits and dashes")
+		name = fs.String("name", "", "display name, defaults to the slug")
+	)
+
+	switch cmd {
+	case "create", "list":
+	default:
+		fmt.Fprint(stderr, orgUsage)
+		return fmt.Errorf("org: unknown subcommand %q", cmd)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+
+	storage, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	orgService := services.NewOrgService(postgresql.NewOrgRepo(storage))
+
+	switch cmd {
+	case "create":
+		if *slug == "" {
+			return fmt.Errorf("org create: -slug is required")
+		}
+		org, err := orgService.Create(ctx, *slug, *name)
+		if err != nil {
+			return fmt.Errorf("org create: %w", err)
+		}
+		fmt.Fprintf(stdout, "created organization %d (%s)\n", org.ID, org.Slug)
+
+	case "list":
+		orgs, err := orgService.List(ctx)
+		if err != nil {
+			return fmt.Errorf("org list: %w", err)
+		}
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSLUG\tNAME\tCREATED")
+		for _, org := range orgs {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", org.ID, org.Slug, org.Name, org.CreatedAt.Format(time.RFC3339))
+		}
+		tw.Flush()
+	}
+
+	return nil
+}
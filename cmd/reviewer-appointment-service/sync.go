@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage/postgresql"
+	"reviewer-appointment-service/internal/tenant"
+)
+
+const syncUsage = `Usage:
+  reviewer-appointment-service sync -file PATH [-dry-run] [-org SLUG]
+
+Brings teams and members of the organization (-org, "default" if omitted)
+in line with a YAML or JSON document:
+
+  teams:
+    - name: backend
+      max_reviewers: 2
+      buddy_teams: [frontend]
+      members:
+        - {user_id: u1, username: Alice, role: lead}
+        - {user_id: u2, username: Bob, is_active: false}
+
+Teams are created or updated, members are created, updated or moved between
+teams. Active members missing from the document are deactivated and their
+open reviews are reassigned. All changes are applied in one transaction;
+-dry-run only prints the plan.
+`
+
+// runSync синхронизирует команды организации с документом напрямую через БД
+func runSync(args []string, stdout, stderr io.Writer) error {
+	cliLogger, _ := logger.New(stderr, "warn")
+	slog.SetDefault(cliLogger)
+
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var (
+		file   = fs.String("file", "", "path to the YAML or JSON document")
+		dryRun = fs.Bool("dry-run", false, "print the plan without applying it")
+		org    = fs.String("org", tenant.DefaultSlug, "organization slug")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		fmt.Fprint(stderr, syncUsage)
+		return fmt.Errorf("sync: -file is required")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	doc, err := services.ParseTeamsDocument(data)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+
+	storage, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	orgService := services.NewOrgService(postgresql.NewOrgRepo(storage))
+	organization, err := orgService.Resolve(ctx, *org)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	ctx = tenant.WithOrg(ctx, organization.ID)
+
+	teamRepo := postgresql.NewTeamRepo(storage)
+	userRepo := postgresql.NewUserStorage(storage)
+	prService := services.NewPRService(postgresql.NewPRRepo(storage), userRepo, teamRepo, services.WithTransactor(storage))
+	teamService := services.NewTeamService(teamRepo, userRepo, services.WithSync(storage, prService))
+
+	plan, err := teamService.Sync(ctx, doc, *dryRun)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	printSyncPlan(stdout, plan)
+	switch {
+	case plan.IsEmpty():
+		fmt.Fprintf(stderr, "organization %s is already in sync\n", organization.Slug)
+	case plan.DryRun:
+		fmt.Fprintf(stderr, "dry run: nothing was changed in organization %s\n", organization.Slug)
+	default:
+		fmt.Fprintf(stderr, "applied %d team, %d user and %d review changes to organization %s\n",
+			len(plan.Teams), len(plan.Users), len(plan.Reassignments), organization.Slug)
+	}
+
+	return nil
+}
+
+// printSyncPlan выводит план синхронизации таблицей
+func printSyncPlan(w io.Writer, plan *domain.SyncPlan) {
+	if plan.IsEmpty() {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tOBJECT\tTEAM\tDETAILS")
+	for _, change := range plan.Teams {
+		fmt.Fprintf(tw, "%s\tteam\t%s\t%s\n", change.Action, change.Team, strings.Join(change.Changes, "; "))
+	}
+	for _, change := range plan.Users {
+		details := change.Changes
+		if change.FromTeam != "" {
+			details = append([]string{"from " + change.FromTeam}, details...)
+		}
+		fmt.Fprintf(tw, "%s\tuser %s\t%s\t%s\n", change.Action, change.UserID, change.Team, strings.Join(details, "; "))
+	}
+	for _, r := range plan.Reassignments {
+		details := "reviewer " + r.OldReviewerID
+		if r.NewReviewerID != "" {
+			details += " -> " + r.NewReviewerID
+		} else if !plan.DryRun {
+			details += " removed"
+		}
+		fmt.Fprintf(tw, "reassign\tpr %s\t\t%s\n", r.PullRequestID, details)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage/postgresql"
+	"reviewer-appointment-service/internal/tenant"
+)
+
+const tokenUsage = `Usage:
+  reviewer-appointment-service token create -name NAME -scopes SCOPES [-user USER_ID] [-ttl DURATION] [-org SLUG]
+  reviewer-appointment-service token list [-org SLUG]
+  reviewer-appointment-service token revoke -id ID [-org SLUG]
+
+Scopes: admin, team:write, pr:write, read (comma separated).
+A token issued with -user acts on behalf of that user and is subject to
+the team lead and PR author rules. A token only gives access to the data
+of its organization (-org, "default" if omitted).
+`
+
+// runToken управляет токенами API напрямую через БД. Нужен в первую
+// очередь для выпуска первого admin-токена.
+func runToken(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, tokenUsage)
+		return fmt.Errorf("token: subcommand is required")
+	}
+
+	// Служебные логи не должны смешиваться с выводом команды
+	cliLogger, _ := logger.New(stderr, "warn")
+	slog.SetDefault(cliLogger)
+
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("token "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var (
+		name   = fs.String("name", "", "token name, e.g. the client it is issued to")
+		scopes = fs.String("scopes", "", "comma separated scopes")
+		user   = fs.String("user", "", "user_id the token acts on behalf of")
+		ttl    = fs.Duration("ttl", 0, "token lifetime, 0 - no expiry")
+		id     = fs.Int64("id", 0, "token id")
+		org    = fs.String("org", tenant.DefaultSlug, "organization slug")
+	)
+
+	switch cmd {
+	case "create", "list", "revoke":
+	default:
+		fmt.Fprint(stderr, tokenUsage)
+		return fmt.Errorf("token: unknown subcommand %q", cmd)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+
+	storage, err := openStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	orgService := services.NewOrgService(postgresql.NewOrgRepo(storage))
+	organization, err := orgService.Resolve(ctx, *org)
+	if err != nil {
+		return fmt.Errorf("token %s: %w", cmd, err)
+	}
+	ctx = tenant.WithOrg(ctx, organization.ID)
+
+	tokenService := services.NewTokenService(postgresql.NewTokenRepo(storage), postgresql.NewUserStorage(storage))
+
+	switch cmd {
+	case "create":
+		if *name == "" {
+			return fmt.Errorf("token create: -name is required")
+		}
+		var expiresAt *time.Time
+		if *ttl > 0 {
+			t := time.Now().Add(*ttl)
+			expiresAt = &t
+		}
+		created, err := tokenService.Create(ctx, *name, *user, splitScopes(*scopes), expiresAt)
+		if err != nil {
+			return fmt.Errorf("token create: %w", err)
+		}
+		fmt.Fprintf(stderr, "created token %d (%s) in organization %s with scopes %s; it will not be shown again\n",
+			created.APIToken.ID, created.APIToken.Name, organization.Slug, strings.Join(created.APIToken.Scopes, ","))
+		fmt.Fprintln(stdout, created.Token)
+
+	case "list":
+		tokens, err := tokenService.List(ctx)
+		if err != nil {
+			return fmt.Errorf("token list: %w", err)
+		}
+		printTokens(stdout, tokens)
+
+	case "revoke":
+		if *id == 0 {
+			return fmt.Errorf("token revoke: -id is required")
+		}
+		if err := tokenService.Revoke(ctx, *id); err != nil {
+			return fmt.Errorf("token revoke: %w", err)
+		}
+		fmt.Fprintf(stdout, "revoked token %d\n", *id)
+	}
+
+	return nil
+}
+
+func splitScopes(s string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func printTokens(w io.Writer, tokens []domain.APIToken) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tUSER\tCREATED\tEXPIRES\tLAST USED\tREVOKED")
+	for _, t := range tokens {
+		user := t.UserID
+		if user == "" {
+			user = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Name, t.Prefix, strings.Join(t.Scopes, ","), user,
+			t.CreatedAt.Format(time.RFC3339), formatTime(t.ExpiresAt),
+			formatTime(t.LastUsedAt), formatTime(t.RevokedAt))
+	}
+	tw.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
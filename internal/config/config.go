@@ -2,15 +2,23 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	DataBase `yaml:"postgres"`
-	Server   `yaml:"server"`
+	DataBase    `yaml:"postgres"`
+	Server      `yaml:"server"`
+	Assignment  `yaml:"assignment"`
+	ReviewSLA   `yaml:"review_sla"`
+	Tracing     `yaml:"tracing"`
+	Log         `yaml:"log"`
+	Auth        `yaml:"auth"`
+	Limits      `yaml:"limits"`
+	Idempotency `yaml:"idempotency"`
 }
 
 type DataBase struct {
@@ -23,6 +31,97 @@ type DataBase struct {
 
 type Server struct {
 	PortServer int `yaml:"port" env:"SERVER_PORT" env-default:"8081"`
+	// ReadinessTimeout - время на проверки /health/ready
+	ReadinessTimeout time.Duration `yaml:"readiness_timeout" env:"SERVER_READINESS_TIMEOUT" env-default:"2s"`
+	// ShutdownDelay - пауза между переводом /health/ready в 503 и остановкой
+	// приема запросов, чтобы балансировщик успел убрать экземпляр
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY" env-default:"5s"`
+	// ShutdownTimeout - время на завершение текущих запросов и фоновых задач
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"15s"`
+}
+
+// Assignment задает стратегию выбора ревьюверов
+type Assignment struct {
+	Strategy         string        `yaml:"strategy" env:"ASSIGNMENT_STRATEGY" env-default:"random"`
+	RotationLookback time.Duration `yaml:"rotation_lookback" env:"ASSIGNMENT_ROTATION_LOOKBACK" env-default:"720h"`
+	// Deterministic - выбор ревьюверов зависит только от ID PR и кандидатов
+	Deterministic bool `yaml:"deterministic" env:"ASSIGNMENT_DETERMINISTIC" env-default:"false"`
+	// Seed - фиксированное зерно генератора случайных чисел (0 - случайное)
+	Seed int64 `yaml:"seed" env:"ASSIGNMENT_SEED" env-default:"0"`
+}
+
+// ReviewSLA задает планировщик напоминаний и SLA ревью по умолчанию
+// (команда может переопределить пороги в минутах)
+type ReviewSLA struct {
+	Enabled         bool          `yaml:"enabled" env:"REVIEW_SLA_ENABLED" env-default:"false"`
+	Interval        time.Duration `yaml:"interval" env:"REVIEW_SLA_INTERVAL" env-default:"1m"`
+	ReminderAfter   time.Duration `yaml:"reminder_after" env:"REVIEW_SLA_REMINDER_AFTER" env-default:"24h"`
+	EscalationAfter time.Duration `yaml:"escalation_after" env:"REVIEW_SLA_ESCALATION_AFTER" env-default:"72h"`
+	BatchSize       int           `yaml:"batch_size" env:"REVIEW_SLA_BATCH_SIZE" env-default:"100"`
+}
+
+// Tracing задает экспорт трассировок OpenTelemetry
+type Tracing struct {
+	// Exporter - none, otlp (OTLP/HTTP) или stdout (для локальной отладки)
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_OTLP_INSECURE" env-default:"true"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"reviewer-appointment-service"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// Log задает уровень логирования: debug, info, warn или error
+type Log struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+}
+
+// Auth включает проверку bearer-токенов. По умолчанию выключена, чтобы
+// существующая установка после обновления продолжила работать и успела
+// выпустить первый admin-токен командой token create; в продакшене ее нужно
+// включить.
+type Auth struct {
+	Enabled bool `yaml:"enabled" env:"AUTH_ENABLED" env-default:"false"`
+}
+
+// Limits ограничивает частоту и размер запросов к API
+type Limits struct {
+	RateLimit RateLimit `yaml:"rate_limit"`
+	// MaxBodyBytes - максимальный размер тела запроса (0 - без ограничения)
+	MaxBodyBytes int64 `yaml:"max_body_bytes" env:"LIMITS_MAX_BODY_BYTES" env-default:"1048576"`
+	// MaxTeamMembers - максимальное число участников в одном запросе
+	// создания команды (0 - без ограничения)
+	MaxTeamMembers int `yaml:"max_team_members" env:"LIMITS_MAX_TEAM_MEMBERS" env-default:"500"`
+	// MaxBatchPRs - максимальное число PR в одном запросе пакетного
+	// создания (0 - без ограничения)
+	MaxBatchPRs int `yaml:"max_batch_prs" env:"LIMITS_MAX_BATCH_PRS" env-default:"500"`
+}
+
+// RateLimit задает token bucket для каждого клиента (токена API, а без
+// него - IP-адреса) на каждом маршруте: Rate запросов в секунду с
+// всплесками до Burst. Routes переопределяет лимит для отдельных маршрутов,
+// ключ - путь, например /pullRequest/create. IPRate и IPBurst - общий
+// лимит на IP-адрес по всем маршрутам API, он проверяется до
+// аутентификации; IPRate <= 0 - без него. По умолчанию выключен, как и
+// аутентификация.
+type RateLimit struct {
+	Enabled bool                 `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"false"`
+	Rate    float64              `yaml:"rate" env:"RATE_LIMIT_RATE" env-default:"20"`
+	Burst   int                  `yaml:"burst" env:"RATE_LIMIT_BURST" env-default:"40"`
+	IPRate  float64              `yaml:"ip_rate" env:"RATE_LIMIT_IP_RATE" env-default:"50"`
+	IPBurst int                  `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST" env-default:"100"`
+	Routes  map[string]RouteRate `yaml:"routes"`
+}
+
+// Idempotency задает, сколько хранится ответ на POST-запрос с заголовком
+// Idempotency-Key
+type Idempotency struct {
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+}
+
+// RouteRate - лимит отдельного маршрута
+type RouteRate struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
 }
 
 func MustConfig(config_path string) *Config {
@@ -35,14 +134,14 @@ func MustConfig(config_path string) *Config {
 		// Сначала читаем YAML (если файл существует)
 		if _, err := os.Stat(config_path); err == nil {
 			if err := cleanenv.ReadConfig(config_path, &cfg); err != nil {
-				log.Printf("Warning: failed to read config file: %v", err)
+				slog.Warn("failed to read config file", "path", config_path, "error", err)
 			}
 		}
 	}
 
 	// Читаем переменные окружения (они всегда имеют приоритет)
 	if err := cleanenv.ReadEnv(&cfg); err != nil {
-		log.Printf("Warning: failed to read environment variables: %v", err)
+		slog.Warn("failed to read environment variables", "error", err)
 	}
 
 	// Переменные окружения имеют ВЫСШИЙ приоритет - перезаписываем после чтения YAML
@@ -63,7 +162,7 @@ func MustConfig(config_path string) *Config {
 		if _, err := fmt.Sscanf(dbPort, "%d", &port); err == nil {
 			cfg.PortDB = port
 		} else {
-			log.Printf("Warning: invalid DB_PORT value: %s", dbPort)
+			slog.Warn("invalid DB_PORT value", "value", dbPort)
 		}
 	}
 	if serverPort := os.Getenv("SERVER_PORT"); serverPort != "" {
@@ -71,13 +170,13 @@ func MustConfig(config_path string) *Config {
 		if _, err := fmt.Sscanf(serverPort, "%d", &port); err == nil {
 			cfg.PortServer = port
 		} else {
-			log.Printf("Warning: invalid SERVER_PORT value: %s", serverPort)
+			slog.Warn("invalid SERVER_PORT value", "value", serverPort)
 		}
 	}
 
-	log.Printf("Config loaded - DB: host=%s, port=%d, user=%s, database=%s (env: DB_HOST=%s, DB_PORT=%s, useConfigFile=%v)",
-		cfg.Host, cfg.PortDB, cfg.User, cfg.Data_base,
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), useConfigFile)
+	slog.Info("config loaded",
+		"db_host", cfg.Host, "db_port", cfg.PortDB, "db_user", cfg.User, "db_name", cfg.Data_base,
+		"use_config_file", useConfigFile)
 
 	return &cfg
 }
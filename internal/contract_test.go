@@ -0,0 +1,536 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"reviewer-appointment-service/api"
+	"reviewer-appointment-service/internal/config"
+	"reviewer-appointment-service/internal/handlers"
+	"reviewer-appointment-service/internal/metrics"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage/memory"
+	"reviewer-appointment-service/internal/tenant"
+	"reviewer-appointment-service/migrations"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openAPISpec - часть документа OpenAPI, нужная контрактным тестам
+type openAPISpec struct {
+	Paths      map[string]map[string]*specOperation `json:"paths"`
+	Components struct {
+		Schemas    map[string]*specSchema    `json:"schemas"`
+		Parameters map[string]*specParameter `json:"parameters"`
+		Responses  map[string]*specResponse  `json:"responses"`
+		Headers    map[string]interface{}    `json:"headers"`
+	} `json:"components"`
+}
+
+type specOperation struct {
+	Parameters  []*specParameter         `json:"parameters"`
+	RequestBody *specRequestBody         `json:"requestBody"`
+	Responses   map[string]*specResponse `json:"responses"`
+}
+
+type specParameter struct {
+	Ref      string `json:"$ref"`
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+}
+
+type specRequestBody struct {
+	Required bool                     `json:"required"`
+	Content  map[string]specMediaType `json:"content"`
+}
+
+type specResponse struct {
+	Ref     string                   `json:"$ref"`
+	Headers map[string]interface{}   `json:"headers"`
+	Content map[string]specMediaType `json:"content"`
+}
+
+type specMediaType struct {
+	Schema *specSchema `json:"schema"`
+}
+
+type specSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Format               string                 `json:"format"`
+	Nullable             bool                   `json:"nullable"`
+	Enum                 []interface{}          `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*specSchema `json:"properties"`
+	Items                *specSchema            `json:"items"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+}
+
+func loadSpec(t *testing.T) *openAPISpec {
+	var spec openAPISpec
+	require.NoError(t, json.Unmarshal(api.OpenAPI, &spec))
+	return &spec
+}
+
+func refName(ref, prefix string) string {
+	return strings.TrimPrefix(ref, "#/components/"+prefix+"/")
+}
+
+func (s *openAPISpec) parameter(p *specParameter) *specParameter {
+	if p.Ref != "" {
+		return s.Components.Parameters[refName(p.Ref, "parameters")]
+	}
+	return p
+}
+
+func (s *openAPISpec) response(r *specResponse) *specResponse {
+	if r.Ref != "" {
+		return s.Components.Responses[refName(r.Ref, "responses")]
+	}
+	return r
+}
+
+// validate проверяет значение, полученное из JSON, на соответствие схеме и
+// возвращает описания несоответствий. Объекты со списком свойств закрыты:
+// недокументированное поле - ошибка.
+func (s *openAPISpec) validate(schema *specSchema, value interface{}, path string) []string {
+	if schema.Ref != "" {
+		return s.validate(s.Components.Schemas[refName(schema.Ref, "schemas")], value, path)
+	}
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []string{path + ": unexpected null"}
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, v := range schema.Enum {
+			if v == value {
+				found = true
+			}
+		}
+		if !found {
+			return []string{fmt.Sprintf("%s: %v is not in enum %v", path, value, schema.Enum)}
+		}
+	}
+
+	switch schema.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want string, got %T", path, value)}
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return []string{fmt.Sprintf("%s: %q is not a date-time", path, str)}
+			}
+		}
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			return []string{fmt.Sprintf("%s: want %s, got %T", path, schema.Type, value)}
+		}
+		if schema.Type == "integer" && num != float64(int64(num)) {
+			return []string{fmt.Sprintf("%s: %v is not an integer", path, num)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: want boolean, got %T", path, value)}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: want array, got %T", path, value)}
+		}
+		var errs []string
+		for i, item := range items {
+			errs = append(errs, s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: want object, got %T", path, value)}
+		}
+		return s.validateObject(schema, obj, path)
+	}
+	return nil
+}
+
+func (s *openAPISpec) validateObject(schema *specSchema, obj map[string]interface{}, path string) []string {
+	var errs []string
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, fmt.Sprintf("%s: missing required field %q", path, name))
+		}
+	}
+
+	var additional *specSchema
+	open := schema.Properties == nil
+	switch raw := string(schema.AdditionalProperties); raw {
+	case "":
+	case "true":
+		open = true
+	case "false":
+		open = false
+	default:
+		additional = &specSchema{}
+		if err := json.Unmarshal(schema.AdditionalProperties, additional); err != nil {
+			return append(errs, fmt.Sprintf("%s: bad additionalProperties: %v", path, err))
+		}
+	}
+
+	for name, value := range obj {
+		field := path + "." + name
+		switch prop, ok := schema.Properties[name]; {
+		case ok:
+			errs = append(errs, s.validate(prop, value, field)...)
+		case additional != nil:
+			errs = append(errs, s.validate(additional, value, field)...)
+		case !open:
+			errs = append(errs, field+": field is not documented")
+		}
+	}
+	return errs
+}
+
+// specPath - шаблон пути спецификации, скомпилированный для сопоставления с
+// реальными путями. Параметр не захватывает "/" и ":", поэтому
+// /pull-requests/{id} и /pull-requests/{id}:merge не пересекаются.
+type specPath struct {
+	template string
+	re       *regexp.Regexp
+}
+
+var specParamRe = regexp.MustCompile(`\{[^}]+\}`)
+
+func compilePaths(spec *openAPISpec) []specPath {
+	var paths []specPath
+	for template := range spec.Paths {
+		parts := specParamRe.Split(template, -1)
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		paths = append(paths, specPath{
+			template: template,
+			re:       regexp.MustCompile("^" + strings.Join(parts, "[^/:]+") + "$"),
+		})
+	}
+	return paths
+}
+
+func matchPath(paths []specPath, path string) (string, bool) {
+	for _, p := range paths {
+		if p.re.MatchString(path) {
+			return p.template, true
+		}
+	}
+	return "", false
+}
+
+// ginPath переводит шаблон спецификации в маршрут gin: {id} -> :id, а
+// метод ресурса после параметра (:merge) обрабатывается внутри хендлера
+func ginPath(template string) string {
+	path := regexp.MustCompile(`\}:[a-z]+$`).ReplaceAllString(template, "}")
+	return specParamRe.ReplaceAllStringFunc(path, func(param string) string {
+		return ":" + strings.Trim(param, "{}")
+	})
+}
+
+func TestOpenAPISpec_Valid(t *testing.T) {
+	spec := loadSpec(t)
+	require.NotEmpty(t, spec.Paths)
+
+	// Все ссылки документа разрешаются
+	refs := regexp.MustCompile(`"\$ref":\s*"#/components/(\w+)/(\w+)"`).FindAllStringSubmatch(string(api.OpenAPI), -1)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		var ok bool
+		switch ref[1] {
+		case "schemas":
+			_, ok = spec.Components.Schemas[ref[2]]
+		case "parameters":
+			_, ok = spec.Components.Parameters[ref[2]]
+		case "responses":
+			_, ok = spec.Components.Responses[ref[2]]
+		case "headers":
+			_, ok = spec.Components.Headers[ref[2]]
+		}
+		assert.True(t, ok, "unresolved $ref %s/%s", ref[1], ref[2])
+	}
+
+	for template, ops := range spec.Paths {
+		for method, op := range ops {
+			assert.NotEmpty(t, op.Responses, "%s %s has no responses", method, template)
+		}
+	}
+}
+
+// newMemoryRouter собирает роутер с аутентификацией на репозиториях в памяти
+func newMemoryRouter(t *testing.T) (*gin.Engine, *services.TokenService) {
+	schemaVersion, err := migrations.LatestVersion()
+	require.NoError(t, err)
+	store := memory.New(schemaVersion)
+	userRepo := memory.NewUserRepo(store)
+	teamRepo := memory.NewTeamRepo(store)
+	prRepo := memory.NewPRRepo(store)
+
+	tokenService := services.NewTokenService(memory.NewTokenRepo(store), userRepo)
+	prService := services.NewPRService(prRepo, userRepo, teamRepo, services.WithDeterministic(), services.WithTransactor(store))
+	h := handlers.NewHandler(
+		services.NewUserService(userRepo),
+		services.NewTeamService(teamRepo, userRepo, services.WithSync(store, prService)),
+		prService,
+		memory.NewStatsRepo(store),
+		services.NewHealthService(store, schemaVersion, "test", time.Second),
+		tokenService,
+		services.NewPolicy(userRepo, teamRepo, prRepo),
+		services.NewOrgService(memory.NewOrgRepo(store)),
+	)
+	return setupRouter(h, metrics.New(), "test", true, config.Limits{}, nil), tokenService
+}
+
+// contractCall - реальный запрос к роутеру и ожидаемый статус ответа
+type contractCall struct {
+	method string
+	path   string
+	body   string
+	status int
+}
+
+func TestRouter_Contract(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	spec := loadSpec(t)
+	paths := compilePaths(spec)
+
+	router, tokenService := newMemoryRouter(t)
+
+	admin, err := tokenService.Create(ctx, "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+	ci, err := tokenService.Create(ctx, "ci", "", []string{domain.ScopeRead}, nil)
+	require.NoError(t, err)
+
+	// Команды собраны так, чтобы выбор ревьюверов не зависел от случая:
+	// у автора ровно max_reviewers кандидатов, замены указываются явно
+	calls := []contractCall{
+		{http.MethodGet, "/health", "", http.StatusOK},
+		{http.MethodGet, "/health/live", "", http.StatusOK},
+		{http.MethodGet, "/health/ready", "", http.StatusOK},
+		{http.MethodGet, "/metrics", "", http.StatusOK},
+		{http.MethodGet, "/openapi.json", "", http.StatusOK},
+		{http.MethodGet, "/docs", "", http.StatusOK},
+
+		{http.MethodPost, "/team/add", `{"name":"backend","max_reviewers":2,"users":[{"user_id":"u1","username":"Alice","is_active":true,"role":"lead"},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Carol","is_active":true}]}`, http.StatusCreated},
+		{http.MethodPost, "/team/add", `{"name":"frontend","users":[{"user_id":"f1","username":"Dave","is_active":true},{"user_id":"f2","username":"Eve","is_active":true}]}`, http.StatusCreated},
+		{http.MethodPost, "/team/add", `{"name":"backend"}`, http.StatusInternalServerError},
+		{http.MethodPost, "/team/add", `{"name":`, http.StatusBadRequest},
+		{http.MethodGet, "/team/get?team_name=backend", "", http.StatusOK},
+		{http.MethodGet, "/team/get", "", http.StatusBadRequest},
+		{http.MethodGet, "/team/get?team_name=nope", "", http.StatusInternalServerError},
+		{http.MethodPost, "/team/setBuddies", `{"team_name":"backend","buddy_teams":["frontend"]}`, http.StatusOK},
+		{http.MethodPost, "/team/setBuddies", `{"team_name":"nope","buddy_teams":[]}`, http.StatusNotFound},
+
+		{http.MethodPost, "/users/setIsActive", `{"user_id":"f2","is_active":true}`, http.StatusOK},
+		{http.MethodPost, "/users/setIsActive", `{"user_id":"nope","is_active":false}`, http.StatusInternalServerError},
+		{http.MethodGet, "/users/getReview?user_id=u2", "", http.StatusOK},
+		{http.MethodGet, "/users/getReview", "", http.StatusBadRequest},
+
+		{http.MethodPost, "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`, http.StatusCreated},
+		{http.MethodPost, "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`, http.StatusInternalServerError},
+		{http.MethodPost, "/pullRequest/create", `{"pull_request_id":"pr-x","pull_request_name":"Orphan","author_id":"nope"}`, http.StatusInternalServerError},
+		{http.MethodPost, "/pullRequest/batchCreate", `{"pull_requests":[{"pull_request_id":"pr-b1","pull_request_name":"Import 1","author_id":"u1"},{"pull_request_id":"pr-1","pull_request_name":"Duplicate","author_id":"u1"}]}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/batchCreate", `{"atomic":true,"pull_requests":[{"pull_request_id":"pr-b2","pull_request_name":"Import 2","author_id":"u1"},{"pull_request_id":"pr-b3","pull_request_name":"Orphan","author_id":"nope"}]}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/batchCreate", `{"pull_requests":[]}`, http.StatusBadRequest},
+		{http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "", http.StatusOK},
+		{http.MethodGet, "/pullRequest/get?pull_request_id=nope", "", http.StatusNotFound},
+		{http.MethodGet, "/pullRequest/list", "", http.StatusOK},
+		{http.MethodGet, "/pullRequest/list?status=OPEN&team=backend&author_id=u1&sort=created_at&limit=1&created_after=2020-01-01", "", http.StatusOK},
+		{http.MethodGet, "/pullRequest/list?status=CLOSED", "", http.StatusBadRequest},
+		{http.MethodGet, "/pullRequest/list?cursor=not-a-cursor", "", http.StatusBadRequest},
+		{http.MethodGet, "/pullRequest/list?team=nope", "", http.StatusNotFound},
+		{http.MethodGet, "/pullRequest/list?author_id=nope", "", http.StatusNotFound},
+		{http.MethodGet, "/users/getReview?user_id=u2", "", http.StatusOK},
+		{http.MethodPost, "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_reviewer_id":"u2","new_reviewer_id":"f1"}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/removeReviewer", `{"pull_request_id":"pr-1","reviewer_id":"f1"}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/addReviewer", `{"pull_request_id":"pr-1","reviewer_id":"f2"}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/review", `{"pull_request_id":"pr-1","reviewer_id":"u3"}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/merge", `{"pull_request_id":"pr-1"}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/merge", `{"pull_request_id":"pr-1"}`, http.StatusOK},
+		{http.MethodPost, "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_reviewer_id":"u3"}`, http.StatusInternalServerError},
+		{http.MethodPost, "/pullRequest/merge", `{}`, http.StatusBadRequest},
+		{http.MethodGet, "/users/getReview?user_id=u3&status=MERGED&limit=1", "", http.StatusOK},
+
+		{http.MethodGet, "/stats", "", http.StatusOK},
+		{http.MethodGet, "/stats?team_name=backend&limit=5&from=2020-01-01", "", http.StatusOK},
+		{http.MethodGet, "/stats?limit=0", "", http.StatusBadRequest},
+		{http.MethodGet, "/stats/pairs?days=7", "", http.StatusOK},
+		{http.MethodGet, "/stats/latency", "", http.StatusOK},
+		{http.MethodGet, "/stats/latency?team_name=nope", "", http.StatusNotFound},
+		{http.MethodGet, "/stats/fairness?threshold=0.3", "", http.StatusOK},
+
+		{http.MethodPost, "/admin/tokens", `{"name":"bot","scopes":["pr:write"],"user_id":"u1","expires_at":"2030-01-01T00:00:00Z"}`, http.StatusCreated},
+		{http.MethodPost, "/admin/tokens", `{"name":"bot","scopes":["root"]}`, http.StatusBadRequest},
+		{http.MethodGet, "/admin/tokens", "", http.StatusOK},
+		{http.MethodPost, "/admin/tokens/revoke", fmt.Sprintf(`{"id":%d}`, ci.APIToken.ID), http.StatusOK},
+		{http.MethodPost, "/admin/tokens/revoke", `{"id":100000}`, http.StatusNotFound},
+		{http.MethodPost, "/admin/sync?dry_run=true", `{"teams":[{"name":"backend","max_reviewers":2,"buddy_teams":["frontend"],"members":[{"user_id":"u1","username":"Alice","role":"lead"},{"user_id":"u2","username":"Bob"},{"user_id":"f2","username":"Eve"}]},{"name":"qa","members":[{"user_id":"q1","username":"Ivan"}]}]}`, http.StatusOK},
+		{http.MethodPost, "/admin/sync?dry_run=true", `{"teams":[{"name":"backend","buddy_teams":["nope"]}]}`, http.StatusNotFound},
+		{http.MethodPost, "/admin/sync", `{"teams":[]}`, http.StatusBadRequest},
+
+		{http.MethodPost, "/api/v2/teams", `{"name":"platform","max_reviewers":2,"users":[{"user_id":"p1","username":"Frank","is_active":true},{"user_id":"p2","username":"Grace","is_active":true},{"user_id":"p3","username":"Heidi","is_active":true}]}`, http.StatusCreated},
+		{http.MethodPost, "/api/v2/teams", `{"name":"platform"}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/v2/teams/platform", "", http.StatusOK},
+		{http.MethodGet, "/api/v2/teams/nope", "", http.StatusNotFound},
+		{http.MethodPatch, "/api/v2/teams/platform", `{"buddy_teams":["backend"]}`, http.StatusOK},
+		{http.MethodPatch, "/api/v2/teams/platform", `{"buddy_teams":["nope"]}`, http.StatusNotFound},
+		{http.MethodGet, "/api/v2/teams/platform/members", "", http.StatusOK},
+		{http.MethodGet, "/api/v2/users/p2", "", http.StatusOK},
+		{http.MethodGet, "/api/v2/users/nope", "", http.StatusNotFound},
+		{http.MethodPatch, "/api/v2/users/p2", `{"is_active":true}`, http.StatusOK},
+		{http.MethodGet, "/api/v2/users/p2/reviews", "", http.StatusOK},
+
+		{http.MethodPost, "/api/v2/pull-requests", `{"pull_request_id":"pr-2","pull_request_name":"Fix cache","author_id":"p1"}`, http.StatusCreated},
+		{http.MethodGet, "/api/v2/pull-requests/pr-2", "", http.StatusOK},
+		{http.MethodGet, "/api/v2/pull-requests/nope", "", http.StatusNotFound},
+		{http.MethodGet, "/api/v2/pull-requests/pr-2/reviewers", "", http.StatusOK},
+		{http.MethodDelete, "/api/v2/pull-requests/pr-2/reviewers/p2", "", http.StatusOK},
+		{http.MethodPost, "/api/v2/pull-requests/pr-2/reviewers", `{"reviewer_id":"u2"}`, http.StatusCreated},
+		{http.MethodPost, "/api/v2/pull-requests/pr-2/reviewers/p3:reassign", "", http.StatusOK},
+		{http.MethodPost, "/api/v2/pull-requests/pr-2/reviewers/u2:reassign", `{"new_reviewer_id":"u3"}`, http.StatusOK},
+		{http.MethodPost, "/api/v2/pull-requests/pr-2/reviewers/u3:review", "", http.StatusOK},
+		{http.MethodPost, "/api/v2/pull-requests/pr-2:merge", "", http.StatusOK},
+		{http.MethodPost, "/api/v2/pull-requests/nope:merge", "", http.StatusNotFound},
+		{http.MethodGet, "/api/v2/users/u3/reviews?limit=1", "", http.StatusOK},
+		{http.MethodGet, "/api/v2/users/u3/reviews?sort=name", "", http.StatusBadRequest},
+	}
+
+	covered := make(map[string]bool)
+	for _, call := range calls {
+		name := call.method + " " + call.path
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(call.path)
+			require.NoError(t, err)
+
+			template, ok := matchPath(paths, u.Path)
+			require.True(t, ok, "path is not documented")
+			op := spec.Paths[template][strings.ToLower(call.method)]
+			require.NotNil(t, op, "method is not documented")
+			covered[call.method+" "+template] = true
+
+			checkContractRequest(t, spec, op, u.Query(), call)
+
+			req := httptest.NewRequest(call.method, call.path, strings.NewReader(call.body))
+			req.Header.Set("Authorization", "Bearer "+admin.Token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, call.status, rec.Code, rec.Body.String())
+			checkContractResponse(t, spec, op, rec)
+		})
+	}
+
+	t.Run("every operation is exercised", func(t *testing.T) {
+		for template, ops := range spec.Paths {
+			for method := range ops {
+				operation := strings.ToUpper(method) + " " + template
+				assert.True(t, covered[operation], "%s is not covered by the contract test", operation)
+			}
+		}
+	})
+
+	t.Run("routes match the spec", func(t *testing.T) {
+		documented := make(map[string]bool)
+		for template, ops := range spec.Paths {
+			for method := range ops {
+				documented[strings.ToUpper(method)+" "+ginPath(template)] = true
+			}
+		}
+
+		registered := make(map[string]bool)
+		for _, route := range router.Routes() {
+			registered[route.Method+" "+route.Path] = true
+		}
+
+		assert.Equal(t, sortedKeys(documented), sortedKeys(registered))
+	})
+}
+
+// checkContractRequest проверяет, что запрос сценария описан спецификацией.
+// Тело проверяется только у успешных запросов: остальные намеренно его
+// нарушают.
+func checkContractRequest(t *testing.T, spec *openAPISpec, op *specOperation, query url.Values, call contractCall) {
+	declared := make(map[string]*specParameter)
+	for _, p := range op.Parameters {
+		p = spec.parameter(p)
+		if p.In == "query" {
+			declared[p.Name] = p
+		}
+	}
+	for name := range query {
+		assert.Contains(t, declared, name, "query parameter is not documented")
+	}
+
+	if call.status >= http.StatusBadRequest {
+		return
+	}
+	for name, p := range declared {
+		if p.Required {
+			assert.True(t, query.Has(name), "required query parameter %q is missing", name)
+		}
+	}
+
+	if call.body == "" {
+		assert.True(t, op.RequestBody == nil || !op.RequestBody.Required, "request body is required")
+		return
+	}
+	require.NotNil(t, op.RequestBody, "request body is not documented")
+	var body interface{}
+	require.NoError(t, json.Unmarshal([]byte(call.body), &body))
+	assert.Empty(t, spec.validate(op.RequestBody.Content["application/json"].Schema, body, "request"))
+}
+
+func checkContractResponse(t *testing.T, spec *openAPISpec, op *specOperation, rec *httptest.ResponseRecorder) {
+	documented, ok := op.Responses[fmt.Sprint(rec.Code)]
+	require.True(t, ok, "status %d is not documented", rec.Code)
+	resp := spec.response(documented)
+
+	for name := range resp.Headers {
+		assert.NotEmpty(t, rec.Header().Get(name), "documented header %s is missing", name)
+	}
+
+	contentType := strings.TrimSpace(strings.Split(rec.Header().Get("Content-Type"), ";")[0])
+	media, ok := resp.Content[contentType]
+	require.True(t, ok, "content type %q is not documented", contentType)
+	if contentType != "application/json" {
+		return
+	}
+
+	var body interface{}
+	require.NoError(t, json.NewDecoder(bytes.NewReader(rec.Body.Bytes())).Decode(&body))
+	assert.Empty(t, spec.validate(media.Schema, body, "response"), rec.Body.String())
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
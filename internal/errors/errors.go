@@ -11,6 +11,32 @@ const (
 	NotAssigned ErrorCode = "NOT_ASSIGNED"
 	NoCandidate ErrorCode = "NO_CANDIDATE"
 	NotFound    ErrorCode = "NOT_FOUND"
+	Conflict    ErrorCode = "CONFLICT"
+
+	ReviewerInactive ErrorCode = "REVIEWER_INACTIVE"
+	ReviewerIsAuthor ErrorCode = "REVIEWER_IS_AUTHOR"
+	AlreadyAssigned  ErrorCode = "ALREADY_ASSIGNED"
+	ReviewerLimit    ErrorCode = "REVIEWER_LIMIT"
+
+	InvalidReviewerBounds ErrorCode = "INVALID_REVIEWER_BOUNDS"
+	InvalidBuddyTeam      ErrorCode = "INVALID_BUDDY_TEAM"
+	InvalidRole           ErrorCode = "INVALID_ROLE"
+	InvalidReviewSLA      ErrorCode = "INVALID_REVIEW_SLA"
+	InvalidScope          ErrorCode = "INVALID_SCOPE"
+	InvalidTokenExpiry    ErrorCode = "INVALID_TOKEN_EXPIRY"
+	TooManyMembers        ErrorCode = "TOO_MANY_MEMBERS"
+	TooManyPRs            ErrorCode = "TOO_MANY_PRS"
+	InvalidSyncDocument   ErrorCode = "INVALID_SYNC_DOCUMENT"
+
+	Unauthorized ErrorCode = "UNAUTHORIZED"
+	Forbidden    ErrorCode = "FORBIDDEN"
+
+	RateLimited     ErrorCode = "RATE_LIMITED"
+	PayloadTooLarge ErrorCode = "PAYLOAD_TOO_LARGE"
+
+	InvalidIdempotencyKey  ErrorCode = "INVALID_IDEMPOTENCY_KEY"
+	IdempotencyKeyMismatch ErrorCode = "IDEMPOTENCY_KEY_MISMATCH"
+	IdempotencyKeyInUse    ErrorCode = "IDEMPOTENCY_KEY_IN_USE"
 )
 
 type AppError struct {
@@ -36,4 +62,30 @@ var (
 	ErrNotAssigned = NewAppError(NotAssigned, "reviewer is not assigned to this PR")
 	ErrNoCandidate = NewAppError(NoCandidate, "no active replacement candidate in team")
 	ErrNotFound    = NewAppError(NotFound, "resource not found")
+	ErrConflict    = NewAppError(Conflict, "resource was modified by another request, fetch it and retry")
+
+	ErrReviewerInactive = NewAppError(ReviewerInactive, "reviewer is not active")
+	ErrReviewerIsAuthor = NewAppError(ReviewerIsAuthor, "author cannot review own PR")
+	ErrAlreadyAssigned  = NewAppError(AlreadyAssigned, "reviewer is already assigned to this PR")
+	ErrReviewerLimit    = NewAppError(ReviewerLimit, "team reviewer limit reached for this PR")
+
+	ErrInvalidReviewerBounds = NewAppError(InvalidReviewerBounds, "min_reviewers must be between 0 and max_reviewers")
+	ErrInvalidBuddyTeam      = NewAppError(InvalidBuddyTeam, "buddy team must be another team and listed once")
+	ErrInvalidRole           = NewAppError(InvalidRole, "role must be member or lead")
+	ErrInvalidReviewSLA      = NewAppError(InvalidReviewSLA, "review SLA must be non-negative and escalation must not precede reminder")
+	ErrInvalidScope          = NewAppError(InvalidScope, "scopes must be a non-empty subset of admin, team:write, pr:write, read")
+	ErrInvalidTokenExpiry    = NewAppError(InvalidTokenExpiry, "expires_at must be in the future")
+	ErrTooManyMembers        = NewAppError(TooManyMembers, "team has more members than allowed in one request")
+	ErrTooManyPRs            = NewAppError(TooManyPRs, "batch has more pull requests than allowed in one request")
+	ErrInvalidSyncDocument   = NewAppError(InvalidSyncDocument, "team sync document is malformed or inconsistent")
+
+	ErrUnauthorized = NewAppError(Unauthorized, "missing, invalid, expired or revoked bearer token")
+	ErrForbidden    = NewAppError(Forbidden, "caller is not allowed to perform this action")
+
+	ErrRateLimited     = NewAppError(RateLimited, "too many requests, retry later")
+	ErrPayloadTooLarge = NewAppError(PayloadTooLarge, "request body is too large")
+
+	ErrInvalidIdempotencyKey  = NewAppError(InvalidIdempotencyKey, "Idempotency-Key must be 1 to 255 characters long")
+	ErrIdempotencyKeyMismatch = NewAppError(IdempotencyKeyMismatch, "Idempotency-Key was already used with a different request")
+	ErrIdempotencyKeyInUse    = NewAppError(IdempotencyKeyInUse, "request with this Idempotency-Key is still in progress")
 )
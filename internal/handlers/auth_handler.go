@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope пропускает запрос только с действующим bearer-токеном,
+// у которого есть область доступа scope. Без токена или с недействительным
+// токеном - 401 UNAUTHORIZED, без нужной области - 403 FORBIDDEN.
+func (h *Handler) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := h.tokenService.Authenticate(c.Request.Context(), bearerToken(c))
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="reviewer-appointment-service"`)
+			status, resp := errorResponse(err)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+
+		if !token.HasScope(scope) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			status, resp := errorResponse(storage.ErrForbidden)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+
+		ctx := services.ContextWithToken(c.Request.Context(), token)
+		l := logger.FromContext(ctx).With("token_id", token.ID)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, l))
+
+		c.Next()
+	}
+}
+
+// ResolveOrg привязывает запрос к организации. Запрос с токеном работает
+// с организацией токена; заголовок X-Org, указывающий на другую
+// организацию, - 403 FORBIDDEN. Без токена (аутентификация выключена)
+// организация берется из X-Org, а без заголовка - организация по умолчанию.
+func (h *Handler) ResolveOrg() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		slug := c.GetHeader(tenant.Header)
+
+		orgID := tenant.DefaultOrgID
+		token, authenticated := services.TokenFromContext(ctx)
+		if authenticated {
+			orgID = token.OrgID
+		}
+
+		if slug != "" {
+			org, err := h.orgService.Resolve(ctx, slug)
+			if err == nil && authenticated && org.ID != orgID {
+				err = storage.ErrForbidden
+			}
+			if err != nil {
+				status, resp := errorResponse(err)
+				c.AbortWithStatusJSON(status, resp)
+				return
+			}
+			orgID = org.ID
+		}
+
+		ctx = tenant.WithOrg(ctx, orgID)
+		l := logger.FromContext(ctx).With("org_id", orgID)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, l))
+
+		c.Next()
+	}
+}
+
+// bearerToken достает токен из заголовка Authorization: Bearer <token>
+func bearerToken(c *gin.Context) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// CreateToken выпускает токен доступа к API
+// @Summary Выпустить токен доступа
+// @Description Создает токен с указанными областями доступа, при указании user_id - от имени пользователя. Токен возвращается только в этом ответе
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body CreateTokenRequest true "Имя и области доступа токена"
+// @Success 201 {object} domain.CreatedToken
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /admin/tokens [post]
+func (h *Handler) CreateToken(c *gin.Context) {
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	created, err := h.tokenService.Create(c.Request.Context(), req.Name, req.UserID, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListTokens возвращает выпущенные токены без их значений
+// @Summary Список токенов доступа
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]domain.APIToken
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Failure 500 {object} Response
+// @Router /admin/tokens [get]
+func (h *Handler) ListTokens(c *gin.Context) {
+	tokens, err := h.tokenService.List(c.Request.Context())
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"tokens": tokens,
+	})
+}
+
+// RevokeToken отзывает токен доступа
+// @Summary Отозвать токен доступа
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body RevokeTokenRequest true "ID токена"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /admin/tokens/revoke [post]
+func (h *Handler) RevokeToken(c *gin.Context) {
+	var req RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	if err := h.tokenService.Revoke(c.Request.Context(), req.ID); err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"revoked_id": req.ID,
+	})
+}
+
+// CreateTokenRequest представляет запрос на выпуск токена
+type CreateTokenRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	UserID    string     `json:"user_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// RevokeTokenRequest представляет запрос на отзыв токена
+type RevokeTokenRequest struct {
+	ID int64 `json:"id" binding:"required"`
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"reviewer-appointment-service/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIPage - страница Swagger UI. Статика загружается с CDN, чтобы не
+// встраивать ее в бинарный файл.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>Reviewer Appointment Service API</title>
+  <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// OpenAPISpec отдает спецификацию OpenAPI 3
+// @Summary Спецификация OpenAPI 3
+// @Tags Docs
+// @Produce json
+// @Success 200
+// @Router /openapi.json [get]
+func OpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", api.OpenAPI)
+}
+
+// SwaggerUI отдает страницу Swagger UI для /openapi.json
+// @Summary Swagger UI
+// @Tags Docs
+// @Produce html
+// @Success 200
+// @Router /docs [get]
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"reviewer-appointment-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IfMatch передает версию из заголовка If-Match в контекст запроса: сервисы
+// отклоняют изменение ресурса другой версии с 409 CONFLICT. Значение
+// заголовка - ETag из ответа API; "*" и пустой заголовок не ограничивают
+// запрос.
+func IfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := strings.TrimSpace(c.GetHeader("If-Match"))
+		if c.Request.Method == http.MethodGet || value == "" || value == "*" {
+			c.Next()
+			return
+		}
+
+		version, ok := parseETag(value)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, Response{
+				Error: &ErrorResponse{
+					Code:    "INVALID_REQUEST",
+					Message: "If-Match must be an ETag returned by the API",
+				},
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(services.ContextWithExpectedVersion(c.Request.Context(), version))
+		c.Next()
+	}
+}
+
+// setETag отдает версию ресурса в заголовке ETag
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseETag разбирает ETag вида "3" (слабый W/"3" тоже принимается)
+func parseETag(value string) (int, bool) {
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	stderrors "errors"
+
 	"reviewer-appointment-service/internal/errors"
 	"reviewer-appointment-service/internal/services"
 	"reviewer-appointment-service/internal/storage"
@@ -11,20 +13,36 @@ type Handler struct {
 	teamService *services.TeamService
 	prService   *services.PRService
 	statsRepo   storage.StatsRepository
+
+	healthService *services.HealthService
+	tokenService  *services.TokenService
+	policy        *services.Policy
+	orgService    *services.OrgService
 }
 
-func NewHandler(userService *services.UserService, teamService *services.TeamService, prService *services.PRService, statsRepo storage.StatsRepository) *Handler {
+func NewHandler(userService *services.UserService, teamService *services.TeamService, prService *services.PRService, statsRepo storage.StatsRepository, healthService *services.HealthService, tokenService *services.TokenService, policy *services.Policy, orgService *services.OrgService) *Handler {
 	return &Handler{
-		userService: userService,
-		teamService: teamService,
-		prService:   prService,
-		statsRepo:   statsRepo,
+		userService:   userService,
+		teamService:   teamService,
+		prService:     prService,
+		statsRepo:     statsRepo,
+		healthService: healthService,
+		tokenService:  tokenService,
+		policy:        policy,
+		orgService:    orgService,
 	}
 }
 
 type Response struct {
 	Data  interface{}    `json:"data,omitempty"`
 	Error *ErrorResponse `json:"error,omitempty"`
+	Meta  *PageMeta      `json:"meta,omitempty"`
+}
+
+// PageMeta - продолжение списка в ответах API v2: next_cursor нет на
+// последней странице
+type PageMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -32,7 +50,80 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// storageErrors сопоставляет ошибки слоя хранения с кодами ошибок API
+var storageErrors = []struct {
+	err    error
+	appErr *errors.AppError
+}{
+	{storage.ErrTeamExists, errors.ErrTeamExists},
+	{storage.ErrPRExists, errors.ErrPRExists},
+	{storage.ErrPRMerged, errors.ErrPRMerged},
+	{storage.ErrNotAssigned, errors.ErrNotAssigned},
+	{storage.ErrNoCandidate, errors.ErrNoCandidate},
+	{storage.ErrNotFound, errors.ErrNotFound},
+	{storage.ErrVersionConflict, errors.ErrConflict},
+	{storage.ErrReviewerInactive, errors.ErrReviewerInactive},
+	{storage.ErrReviewerIsAuthor, errors.ErrReviewerIsAuthor},
+	{storage.ErrAlreadyAssigned, errors.ErrAlreadyAssigned},
+	{storage.ErrReviewerLimit, errors.ErrReviewerLimit},
+	{storage.ErrInvalidReviewerBounds, errors.ErrInvalidReviewerBounds},
+	{storage.ErrInvalidBuddyTeam, errors.ErrInvalidBuddyTeam},
+	{storage.ErrInvalidRole, errors.ErrInvalidRole},
+	{storage.ErrInvalidReviewSLA, errors.ErrInvalidReviewSLA},
+	{storage.ErrInvalidScope, errors.ErrInvalidScope},
+	{storage.ErrInvalidTokenExpiry, errors.ErrInvalidTokenExpiry},
+	{storage.ErrTooManyMembers, errors.ErrTooManyMembers},
+	{storage.ErrTooManyPRs, errors.ErrTooManyPRs},
+	{storage.ErrInvalidSyncDocument, errors.ErrInvalidSyncDocument},
+	{storage.ErrUnauthorized, errors.ErrUnauthorized},
+	{storage.ErrForbidden, errors.ErrForbidden},
+	{storage.ErrRateLimited, errors.ErrRateLimited},
+	{storage.ErrBodyTooLarge, errors.ErrPayloadTooLarge},
+	{storage.ErrInvalidIdempotencyKey, errors.ErrInvalidIdempotencyKey},
+	{storage.ErrIdempotencyKeyMismatch, errors.ErrIdempotencyKeyMismatch},
+	{storage.ErrIdempotencyKeyInUse, errors.ErrIdempotencyKeyInUse},
+}
+
+func toAppError(err error) error {
+	for _, m := range storageErrors {
+		if stderrors.Is(err, m.err) {
+			return m.appErr
+		}
+	}
+	return err
+}
+
+// legacyErrors - ошибки хранения, на которые исходные маршруты v1
+// (/team/add, /team/get, /users/setIsActive, /users/getReview,
+// /pullRequest/create, /pullRequest/merge, /pullRequest/reassign) всегда
+// отвечали 500 INTERNAL_ERROR. Чтобы не менять их контракт, коды этих ошибок
+// отдают только v2 и новые маршруты.
+var legacyErrors = []error{
+	storage.ErrTeamExists,
+	storage.ErrPRExists,
+	storage.ErrPRMerged,
+	storage.ErrNotAssigned,
+	storage.ErrNoCandidate,
+	storage.ErrNotFound,
+	storage.ErrUserExists,
+}
+
+// legacyErrorResponse отвечает на ошибку исходного маршрута v1 так же, как
+// до появления кодов для ошибок хранения. Новые ошибки получают свои коды.
+func legacyErrorResponse(err error) (int, *Response) {
+	for _, legacy := range legacyErrors {
+		if stderrors.Is(err, legacy) {
+			return appErrorResponse(err)
+		}
+	}
+	return errorResponse(err)
+}
+
 func errorResponse(err error) (int, *Response) {
+	return appErrorResponse(toAppError(err))
+}
+
+func appErrorResponse(err error) (int, *Response) {
 	switch e := err.(type) {
 	case *errors.AppError:
 		return getHTTPStatus(e.Code), &Response{
@@ -57,7 +148,27 @@ func getHTTPStatus(code errors.ErrorCode) int {
 		return 404
 	case errors.TeamExists, errors.PRExists:
 		return 400
-	case errors.PRMerged, errors.NotAssigned, errors.NoCandidate:
+	case errors.ReviewerInactive, errors.ReviewerIsAuthor, errors.InvalidReviewerBounds, errors.InvalidBuddyTeam:
+		return 400
+	case errors.InvalidRole, errors.InvalidReviewSLA, errors.InvalidScope, errors.InvalidTokenExpiry, errors.TooManyMembers, errors.TooManyPRs:
+		return 400
+	case errors.InvalidSyncDocument:
+		return 400
+	case errors.InvalidIdempotencyKey:
+		return 400
+	case errors.Unauthorized:
+		return 401
+	case errors.Forbidden:
+		return 403
+	case errors.PayloadTooLarge:
+		return 413
+	case errors.IdempotencyKeyMismatch:
+		return 422
+	case errors.RateLimited:
+		return 429
+	case errors.PRMerged, errors.NotAssigned, errors.NoCandidate, errors.AlreadyAssigned, errors.ReviewerLimit:
+		return 409
+	case errors.Conflict, errors.IdempotencyKeyInUse:
 		return 409
 	default:
 		return 500
@@ -3,25 +3,57 @@ package handlers
 import (
 	"net/http"
 
+	"reviewer-appointment-service/internal/models/domain"
+
 	"github.com/gin-gonic/gin"
 )
 
 // HealthCheck проверяет работоспособность сервиса
 // @Summary Проверить работоспособность сервиса
-// @Description Возвращает статус работы сервиса
+// @Description Возвращает статус работы сервиса и версию сборки. Эквивалент /health/live
 // @Tags Health
 // @Produce json
 // @Success 200 {object} Response{data=HealthResponse}
 // @Router /health [get]
 func (h *Handler) HealthCheck(c *gin.Context) {
+	h.Liveness(c)
+}
+
+// Liveness сообщает, что процесс жив и обрабатывает запросы
+// @Summary Проверка liveness
+// @Description Не обращается к зависимостям: ответ 200 означает только, что процесс не завис
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Response{data=HealthResponse}
+// @Router /health/live [get]
+func (h *Handler) Liveness(c *gin.Context) {
 	c.JSON(http.StatusOK, Response{
 		Data: HealthResponse{
-			Status:  "ok",
-			Version: "1.0.0",
+			Status:  domain.HealthStatusOK,
+			Version: h.healthService.Version(),
 		},
 	})
 }
 
+// Readiness проверяет, готов ли сервис принимать трафик
+// @Summary Проверка readiness
+// @Description Проверяет доступность PostgreSQL (ping с таймаутом) и актуальность версии схемы, сообщает заполненность пула соединений. При неготовности отвечает 503 с результатами всех проверок
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Response{data=domain.HealthReport}
+// @Failure 503 {object} Response{data=domain.HealthReport}
+// @Router /health/ready [get]
+func (h *Handler) Readiness(c *gin.Context) {
+	report := h.healthService.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != domain.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, Response{Data: report})
+}
+
 // HealthResponse представляет ответ на запрос проверки здоровья
 type HealthResponse struct {
 	Status  string `json:"status"`
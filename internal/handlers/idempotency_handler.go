@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader - заголовок с ключом идемпотентности запроса
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader отмечает ответ, возвращенный из сохраненных
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// Idempotency делает POST-запросы с заголовком Idempotency-Key
+// идемпотентными: первый ответ сохраняется, а повтор с тем же ключом и телом
+// получает его без повторного выполнения. Тот же ключ с другим телом - 422
+// IDEMPOTENCY_KEY_MISMATCH, пока первый запрос выполняется - 409
+// IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются, такой запрос можно
+// повторить с тем же ключом.
+func Idempotency(service *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+
+		hash, err := requestHash(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, Response{
+				Error: &ErrorResponse{
+					Code:    "INVALID_REQUEST",
+					Message: "Invalid request body",
+				},
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		client := clientKey(c)
+		saved, err := service.Begin(ctx, client, key, hash)
+		if err != nil {
+			status, resp := errorResponse(err)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+		if saved != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(saved.StatusCode, saved.ContentType, saved.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		stop := service.Hold(ctx, client, key, hash)
+		c.Next()
+		stop()
+
+		// Клиент мог отключиться, не дождавшись ответа, - как раз тогда он
+		// повторит запрос, поэтому ответ сохраняется и после отмены запроса
+		ctx = context.WithoutCancel(ctx)
+		log := logger.FromContext(ctx)
+		if writer.Status() >= http.StatusInternalServerError {
+			if err := service.Release(ctx, client, key); err != nil {
+				log.Warn("failed to release idempotency key", "error", err)
+			}
+			return
+		}
+		err = service.Complete(ctx, client, key, hash, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+		if err != nil {
+			log.Warn("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// requestHash возвращает хеш метода, пути и тела запроса и возвращает тело
+// в запрос для обработчика
+func requestHash(c *gin.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordingWriter копирует тело ответа, чтобы его можно было сохранить
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reviewer-appointment-service/internal/ratelimit"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit ограничивает частоту запросов клиента к маршруту. Клиент -
+// токен API, а без токена (аутентификация выключена) - IP-адрес. В ответ
+// добавляются заголовки RateLimit-Limit, RateLimit-Remaining и
+// RateLimit-Reset; при превышении - 429 RATE_LIMITED с Retry-After.
+func RateLimit(limits *ratelimit.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := limits.Allow(c.FullPath(), clientKey(c), time.Now())
+
+		if d.Limit > 0 {
+			c.Header("RateLimit-Limit", strconv.Itoa(d.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+		}
+
+		if !d.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(d.RetryAfter), 1)))
+			status, resp := errorResponse(storage.ErrRateLimited)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitIP ограничивает частоту запросов с одного IP-адреса ко всем
+// маршрутам сразу. Стоит перед аутентификацией, поэтому запросы с неверным
+// или отсутствующим токеном тоже расходуют лимит и не нагружают проверку
+// токенов. Заголовки RateLimit-* выставляет лимит клиента после него.
+func RateLimitIP(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := limiter.Allow("ip:"+c.ClientIP(), time.Now())
+
+		if !d.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(d.RetryAfter), 1)))
+			status, resp := errorResponse(storage.ErrRateLimited)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if token, ok := services.TokenFromContext(c.Request.Context()); ok {
+		return "token:" + strconv.FormatInt(token.ID, 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// LimitBody отклоняет запросы с телом больше maxBytes: 413
+// PAYLOAD_TOO_LARGE. Тело читается заранее, поэтому лимит действует и
+// без Content-Length. maxBytes <= 0 - без ограничения.
+func LimitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			status, resp := errorResponse(storage.ErrBodyTooLarge)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, Response{
+				Error: &ErrorResponse{
+					Code:    "INVALID_REQUEST",
+					Message: "Invalid request body",
+				},
+			})
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			status, resp := errorResponse(storage.ErrBodyTooLarge)
+			c.AbortWithStatusJSON(status, resp)
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,16 +38,70 @@ func (h *Handler) CreatePR(c *gin.Context) {
 
 	pr, err := h.prService.CreatePR(c.Request.Context(), req.PRID, req.PRName, req.AuthorID)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
+	setETag(c, pr.Version)
 	c.JSON(http.StatusCreated, map[string]interface{}{
 		"pr": pr,
 	})
 }
 
+// BatchCreatePRs создает PR пакетом
+// @Summary Создать пакет PR с назначением ревьюверов
+// @Description Создает до limits.max_batch_prs PR. Ревьюверы выбираются с учетом нагрузки внутри пакета.
+// @Description С atomic=true PR создаются, только если ни у одного нет ошибки; иначе каждый PR создается независимо.
+// @Tags PullRequests
+// @Accept json
+// @Produce json
+// @Param input body BatchCreatePRRequest true "PR для создания"
+// @Success 200 {object} BatchCreatePRResponse
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /pullRequest/batchCreate [post]
+func (h *Handler) BatchCreatePRs(c *gin.Context) {
+	var req BatchCreatePRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	prs := make([]services.NewPR, 0, len(req.PullRequests))
+	for _, pr := range req.PullRequests {
+		prs = append(prs, services.NewPR{PRID: pr.PRID, PRName: pr.PRName, AuthorUserID: pr.AuthorID})
+	}
+
+	result, err := h.prService.CreatePRBatch(c.Request.Context(), prs, req.Atomic)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp := BatchCreatePRResponse{
+		Created: result.Created,
+		Failed:  result.Failed,
+		Results: make([]BatchCreatePRResult, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		r := BatchCreatePRResult{PRID: item.PRID, Status: item.Status, PR: item.PR}
+		if item.Err != nil {
+			_, errResp := errorResponse(item.Err)
+			r.Error = errResp.Error
+		}
+		resp.Results = append(resp.Results, r)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // MergePR помечает PR как MERGED
 // @Summary Пометить PR как MERGED (идемпотентная операция)
 // @Description Обновляет статус PR на MERGED. Операция идемпотентна.
@@ -68,11 +127,12 @@ func (h *Handler) MergePR(c *gin.Context) {
 
 	pr, err := h.prService.MergePR(c.Request.Context(), req.PRID)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
+	setETag(c, pr.Version)
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"pr": pr,
 	})
@@ -80,13 +140,15 @@ func (h *Handler) MergePR(c *gin.Context) {
 
 // ReassignReviewer переназначает ревьювера на PR
 // @Summary Переназначить конкретного ревьювера на другого из его команды
-// @Description Заменяет одного ревьювера на случайного активного участника из той же команды
+// @Description Заменяет одного ревьювера на случайного активного участника из той же команды.
+// @Description Можно явно указать замену (new_reviewer_id) или ограничить пул кандидатов (exclude, prefer, team_name)
 // @Tags PullRequests
 // @Accept json
 // @Produce json
 // @Param input body ReassignReviewerRequest true "Данные для переназначения ревьювера"
 // @Success 200 {object} Response{data=ReassignReviewerResponse}
 // @Failure 400 {object} Response
+// @Failure 403 {object} Response
 // @Failure 404 {object} Response
 // @Failure 409 {object} Response
 // @Failure 500 {object} Response
@@ -103,9 +165,20 @@ func (h *Handler) ReassignReviewer(c *gin.Context) {
 		return
 	}
 
-	newReviewerID, err := h.prService.ReassignReviewer(c.Request.Context(), req.PRID, req.OldReviewerID)
+	if err := h.policy.CanChangeReviewer(c.Request.Context(), req.PRID, req.OldReviewerID); err != nil {
+		status, resp := legacyErrorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	result, err := h.prService.ReassignReviewerWithOptions(c.Request.Context(), req.PRID, req.OldReviewerID, services.ReassignOptions{
+		NewReviewerID: req.NewReviewerID,
+		Exclude:       req.Exclude,
+		Prefer:        req.Prefer,
+		TeamName:      req.TeamName,
+	})
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
@@ -113,17 +186,305 @@ func (h *Handler) ReassignReviewer(c *gin.Context) {
 	// Получаем обновленный PR для ответа
 	pr, err := h.prService.GetPR(c.Request.Context(), req.PRID)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
+	setETag(c, pr.Version)
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"pr":          pr,
-		"replaced_by": newReviewerID,
+		"replaced_by": result.ReplacedBy,
+		"reviewers":   result.Reviewers,
+	})
+}
+
+// MarkReviewed отмечает ревью PR
+// @Summary Отметить ревью PR
+// @Description Фиксирует первое действие назначенного ревьювера по открытому PR (используется в метриках задержек). Отметить ревью может сам ревьювер или лид его команды
+// @Tags PullRequests
+// @Accept json
+// @Produce json
+// @Param input body ReviewerRequest true "Данные ревьювера"
+// @Success 200 {object} Response{data=domain.PullRequest}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /pullRequest/review [post]
+func (h *Handler) MarkReviewed(c *gin.Context) {
+	var req ReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	if err := h.policy.CanMarkReviewed(c.Request.Context(), req.ReviewerID); err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	pr, err := h.prService.MarkReviewed(c.Request.Context(), req.PRID, req.ReviewerID)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	setETag(c, pr.Version)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// AddReviewer вручную назначает ревьювера на PR
+// @Summary Назначить конкретного ревьювера на PR
+// @Description Добавляет указанного пользователя в ревьюверы открытого PR с учетом ограничений команды автора
+// @Tags PullRequests
+// @Accept json
+// @Produce json
+// @Param input body ReviewerRequest true "Данные ревьювера"
+// @Success 200 {object} Response{data=domain.PullRequest}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /pullRequest/addReviewer [post]
+func (h *Handler) AddReviewer(c *gin.Context) {
+	var req ReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	if err := h.policy.CanAddReviewer(c.Request.Context(), req.PRID); err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	pr, err := h.prService.AddReviewer(c.Request.Context(), req.PRID, req.ReviewerID)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	setETag(c, pr.Version)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// RemoveReviewer снимает ревьювера с PR
+// @Summary Снять ревьювера с PR
+// @Description Удаляет ревьювера из открытого PR. Если ревьюверов становится меньше минимума команды, недостающие назначаются автоматически; если назначить некого, ревьювер не снимается (409 NO_CANDIDATE)
+// @Tags PullRequests
+// @Accept json
+// @Produce json
+// @Param input body ReviewerRequest true "Данные ревьювера"
+// @Success 200 {object} Response{data=domain.PullRequest}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /pullRequest/removeReviewer [post]
+func (h *Handler) RemoveReviewer(c *gin.Context) {
+	var req ReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	if err := h.policy.CanChangeReviewer(c.Request.Context(), req.PRID, req.ReviewerID); err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	pr, err := h.prService.RemoveReviewer(c.Request.Context(), req.PRID, req.ReviewerID)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	setETag(c, pr.Version)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// GetPR возвращает PR с ревьюверами
+// @Summary Получить PR
+// @Description Возвращает PR с ревьюверами и назначениями. Заголовок ETag содержит версию PR для If-Match
+// @Tags PullRequests
+// @Produce json
+// @Param pull_request_id query string true "ID PR"
+// @Success 200 {object} Response{data=domain.PullRequest}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /pullRequest/get [get]
+func (h *Handler) GetPR(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "MISSING_PARAM",
+				Message: "pull_request_id is required",
+			},
+		})
+		return
+	}
+
+	pr, err := h.prService.GetPR(c.Request.Context(), prID)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	setETag(c, pr.Version)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"pr": pr,
+	})
+}
+
+// ListPRs возвращает страницу PR организации
+// @Summary Список PR
+// @Description Возвращает PR с авторами, по умолчанию сначала новые. Следующая страница запрашивается с cursor из next_cursor; next_cursor нет на последней странице
+// @Tags PullRequests
+// @Produce json
+// @Param status query string false "OPEN или MERGED"
+// @Param team query string false "Команда автора"
+// @Param author_id query string false "ID автора"
+// @Param created_after query string false "Созданные позже (RFC3339 или YYYY-MM-DD)"
+// @Param sort query string false "created_at или -created_at (по умолчанию)"
+// @Param limit query int false "Размер страницы (по умолчанию 50, максимум 100)"
+// @Param cursor query string false "next_cursor предыдущей страницы"
+// @Success 200 {object} PRPageResponse
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /pullRequest/list [get]
+func (h *Handler) ListPRs(c *gin.Context) {
+	filter, ok := h.parsePRFilter(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.prService.ListPRs(c.Request.Context(), filter)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, PRPageResponse{
+		PullRequests: page.PullRequests,
+		NextCursor:   page.NextCursor,
 	})
 }
 
+// parsePRFilter разбирает параметры списка PR: status, team, author_id,
+// created_after, sort, limit и cursor. Команда и автор должны существовать.
+// При ошибке отвечает клиенту и возвращает false.
+func (h *Handler) parsePRFilter(c *gin.Context) (domain.PRFilter, bool) {
+	filter := domain.PRFilter{
+		TeamName: c.Query("team"),
+		AuthorID: c.Query("author_id"),
+		Sort:     c.DefaultQuery("sort", domain.PRSortCreatedDesc),
+	}
+
+	switch status := c.Query("status"); status {
+	case "":
+	case domain.PRStatusOpen:
+		filter.StatusID = services.StatusOpenID
+	case domain.PRStatusMerged:
+		filter.StatusID = services.StatusMergedID
+	default:
+		invalidParam(c, "status must be OPEN or MERGED")
+		return filter, false
+	}
+
+	if filter.Sort != domain.PRSortCreatedDesc && filter.Sort != domain.PRSortCreatedAsc {
+		invalidParam(c, "sort must be created_at or -created_at")
+		return filter, false
+	}
+
+	if after := c.Query("created_after"); after != "" {
+		t, err := parseStatsTime(after)
+		if err != nil {
+			invalidParam(c, "created_after must be RFC3339 or YYYY-MM-DD")
+			return filter, false
+		}
+		filter.CreatedAfter = t
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > services.MaxPageLimit {
+			invalidParam(c, fmt.Sprintf("limit must be an integer between 1 and %d", services.MaxPageLimit))
+			return filter, false
+		}
+		filter.Limit = n
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := domain.ParsePRCursor(cursor)
+		if err != nil {
+			invalidParam(c, "cursor is invalid")
+			return filter, false
+		}
+		filter.After = after
+	}
+
+	if filter.TeamName != "" {
+		if _, err := h.teamService.GetTeam(c.Request.Context(), filter.TeamName); err != nil {
+			status, resp := errorResponse(err)
+			c.JSON(status, resp)
+			return filter, false
+		}
+	}
+
+	if filter.AuthorID != "" {
+		if _, err := h.userService.GetUser(c.Request.Context(), filter.AuthorID); err != nil {
+			status, resp := errorResponse(err)
+			c.JSON(status, resp)
+			return filter, false
+		}
+	}
+
+	return filter, true
+}
+
+// PRPageResponse - страница списка PR. next_cursor нет на последней странице.
+type PRPageResponse struct {
+	UserID       string               `json:"user_id,omitempty"`
+	PullRequests []domain.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
 // CreatePRRequest представляет запрос на создание PR
 type CreatePRRequest struct {
 	PRID     string `json:"pull_request_id" binding:"required"`
@@ -131,6 +492,28 @@ type CreatePRRequest struct {
 	AuthorID string `json:"author_id" binding:"required"`
 }
 
+// BatchCreatePRRequest представляет запрос на пакетное создание PR
+type BatchCreatePRRequest struct {
+	PullRequests []CreatePRRequest `json:"pull_requests" binding:"required,min=1,dive"`
+	Atomic       bool              `json:"atomic"`
+}
+
+// BatchCreatePRResponse - итоги пакетного создания в порядке запроса
+type BatchCreatePRResponse struct {
+	Created int                   `json:"created"`
+	Failed  int                   `json:"failed"`
+	Results []BatchCreatePRResult `json:"results"`
+}
+
+// BatchCreatePRResult - итог одного PR пакета: CREATED с PR, FAILED с
+// ошибкой или SKIPPED, если atomic-пакет не создан из-за другого PR
+type BatchCreatePRResult struct {
+	PRID   string              `json:"pull_request_id"`
+	Status string              `json:"status"`
+	PR     *domain.PullRequest `json:"pr,omitempty"`
+	Error  *ErrorResponse      `json:"error,omitempty"`
+}
+
 // MergePRRequest представляет запрос на слияние PR
 type MergePRRequest struct {
 	PRID string `json:"pull_request_id" binding:"required"`
@@ -138,7 +521,16 @@ type MergePRRequest struct {
 
 // ReassignReviewerRequest представляет запрос на переназначение ревьювера
 type ReassignReviewerRequest struct {
-	PRID          string `json:"pull_request_id" binding:"required"`
-	OldReviewerID string `json:"old_reviewer_id" binding:"required"`
+	PRID          string   `json:"pull_request_id" binding:"required"`
+	OldReviewerID string   `json:"old_reviewer_id" binding:"required"`
+	NewReviewerID string   `json:"new_reviewer_id,omitempty"`
+	Exclude       []string `json:"exclude,omitempty"`
+	Prefer        []string `json:"prefer,omitempty"`
+	TeamName      string   `json:"team_name,omitempty"`
 }
 
+// ReviewerRequest представляет запрос на ручное назначение или снятие ревьювера
+type ReviewerRequest struct {
+	PRID       string `json:"pull_request_id" binding:"required"`
+	ReviewerID string `json:"reviewer_id" binding:"required"`
+}
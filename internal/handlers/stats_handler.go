@@ -1,50 +1,83 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetStats возвращает статистику по сервису
 // @Summary Получить статистику по сервису
-// @Description Возвращает различную статистику по работе сервиса
+// @Description Возвращает различную статистику по работе сервиса. PR учитываются по времени создания, назначения ревьюверов - по времени назначения.
 // @Tags Stats
 // @Produce json
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Конец периода, не включая (RFC3339 или YYYY-MM-DD)"
+// @Param team_name query string false "Команда"
+// @Param limit query int false "Размер списка top_reviewers (по умолчанию 10, максимум 100)"
 // @Success 200 {object} Response{data=StatsResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
 // @Router /stats [get]
 func (h *Handler) GetStats(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	totalPRs, err := h.statsRepo.GetTotalPRs(ctx)
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	totalPRs, err := h.statsRepo.GetTotalPRs(ctx, filter)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	totalUsers, err := h.statsRepo.GetTotalUsers(ctx, filter)
 	if err != nil {
 		status, resp := errorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
-	totalUsers, err := h.statsRepo.GetTotalUsers(ctx)
+	activeUsers, err := h.statsRepo.GetActiveUsers(ctx, filter)
 	if err != nil {
 		status, resp := errorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
-	activeUsers, err := h.statsRepo.GetActiveUsers(ctx)
+	prsByStatus, err := h.statsRepo.GetPRsByStatus(ctx, filter)
 	if err != nil {
 		status, resp := errorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
-	prsByStatus, err := h.statsRepo.GetPRsByStatus(ctx)
+	topReviewers, err := h.statsRepo.GetTopReviewers(ctx, filter)
 	if err != nil {
 		status, resp := errorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
-	topReviewers, err := h.statsRepo.GetTopReviewers(ctx, 10)
+	borrowedReviews, err := h.statsRepo.GetBorrowedReviews(ctx, filter)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	teams, err := h.statsRepo.GetTeamStats(ctx, filter)
 	if err != nil {
 		status, resp := errorResponse(err)
 		c.JSON(status, resp)
@@ -67,10 +100,230 @@ func (h *Handler) GetStats(c *gin.Context) {
 			PRsByStatus:  prsByStatus,
 			ActiveUsers:  activeUsers,
 			TopReviewers: topReviewersData,
+
+			BorrowedReviews: borrowedReviews,
+			Teams:           teams,
 		},
 	})
 }
 
+// GetReviewPairs возвращает матрицу назначений "автор -> ревьювер"
+// @Summary Получить статистику пар автор-ревьювер
+// @Description Возвращает, сколько раз каждый ревьювер назначался на PR каждого автора за последние days дней
+// @Tags Stats
+// @Produce json
+// @Param days query int false "Окно в днях (по умолчанию assignment.rotation_lookback)"
+// @Success 200 {object} Response{data=ReviewPairsResponse}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /stats/pairs [get]
+func (h *Handler) GetReviewPairs(c *gin.Context) {
+	window := h.prService.RotationLookback()
+	if days := c.Query("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, Response{
+				Error: &ErrorResponse{
+					Code:    "INVALID_PARAM",
+					Message: "days must be a positive integer",
+				},
+			})
+			return
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	}
+
+	since := time.Now().Add(-window)
+	pairs, err := h.statsRepo.GetReviewPairs(c.Request.Context(), since)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Data: ReviewPairsResponse{
+			Since: since,
+			Pairs: pairs,
+		},
+	})
+}
+
+const (
+	// defaultStatsWindow - период метрик задержек и нагрузки, если from не задан
+	defaultStatsWindow = 30 * 24 * time.Hour
+
+	defaultStatsLimit = 10
+	maxStatsLimit     = 100
+)
+
+// GetLatencyStats возвращает метрики задержек ревью
+// @Summary Получить время до первого ревью и до мержа
+// @Description Медиана и p90 времени от создания PR до первого ревью и до мержа по PR, созданным в периоде [from, to): в целом, по командам и по ревьюверам
+// @Tags Stats
+// @Produce json
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD, по умолчанию to - 30 дней)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, по умолчанию текущий момент)"
+// @Param team_name query string false "Команда автора"
+// @Success 200 {object} Response{data=domain.LatencyReport}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /stats/latency [get]
+func (h *Handler) GetLatencyStats(c *gin.Context) {
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultStatsWindow)
+	}
+	if !filter.From.Before(filter.To) {
+		invalidParam(c, "from must be before to")
+		return
+	}
+
+	report, err := h.statsRepo.GetLatency(c.Request.Context(), filter)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Data: report})
+}
+
+// GetFairnessStats возвращает отчет о равномерности нагрузки ревьюверов
+// @Summary Получить распределение назначений внутри команд
+// @Description Для каждой команды: доля назначений каждого участника (включая снятые и переназначенные) против ожидаемой доли, пропорциональной времени активности в периоде [from, to), коэффициент Джини, отношение max/min и список выбросов
+// @Tags Stats
+// @Produce json
+// @Param from query string false "Начало периода (RFC3339 или YYYY-MM-DD, по умолчанию to - 30 дней)"
+// @Param to query string false "Конец периода (RFC3339 или YYYY-MM-DD, по умолчанию текущий момент)"
+// @Param team_name query string false "Команда"
+// @Param threshold query number false "Допустимое отклонение доли от ожидаемой, после которого участник считается выбросом (по умолчанию 0.5)"
+// @Success 200 {object} Response{data=domain.FairnessReport}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /stats/fairness [get]
+func (h *Handler) GetFairnessStats(c *gin.Context) {
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	threshold := services.DefaultFairnessThreshold
+	if value := c.Query("threshold"); value != "" {
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t <= 0 || math.IsInf(t, 0) {
+			invalidParam(c, "threshold must be a positive number")
+			return
+		}
+		threshold = t
+	}
+
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultStatsWindow)
+	}
+	if !filter.From.Before(filter.To) {
+		invalidParam(c, "from must be before to")
+		return
+	}
+
+	workloads, err := h.statsRepo.GetMemberWorkloads(c.Request.Context(), filter)
+	if err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Data: services.BuildFairnessReport(filter.From, filter.To, threshold, workloads),
+	})
+}
+
+// parseStatsFilter разбирает параметры from, to, team_name и limit и
+// проверяет, что команда существует. При ошибке отвечает клиенту и
+// возвращает false.
+func (h *Handler) parseStatsFilter(c *gin.Context) (domain.StatsFilter, bool) {
+	filter := domain.StatsFilter{
+		TeamName: c.Query("team_name"),
+		Limit:    defaultStatsLimit,
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseStatsTime(from)
+		if err != nil {
+			invalidParam(c, "from must be RFC3339 or YYYY-MM-DD")
+			return filter, false
+		}
+		filter.From = t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := parseStatsTime(to)
+		if err != nil {
+			invalidParam(c, "to must be RFC3339 or YYYY-MM-DD")
+			return filter, false
+		}
+		filter.To = t
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		invalidParam(c, "from must be before to")
+		return filter, false
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxStatsLimit {
+			invalidParam(c, fmt.Sprintf("limit must be an integer between 1 and %d", maxStatsLimit))
+			return filter, false
+		}
+		filter.Limit = n
+	}
+
+	if filter.TeamName != "" {
+		if _, err := h.teamService.GetTeam(c.Request.Context(), filter.TeamName); err != nil {
+			status, resp := errorResponse(err)
+			c.JSON(status, resp)
+			return filter, false
+		}
+	}
+
+	return filter, true
+}
+
+func parseStatsTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+func invalidParam(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, Response{
+		Error: &ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: message,
+		},
+	})
+}
+
+// ReviewPairsResponse представляет матрицу пар автор-ревьювер
+type ReviewPairsResponse struct {
+	Since time.Time                `json:"since"`
+	Pairs []domain.ReviewPairStats `json:"pairs"`
+}
+
 // StatsResponse представляет ответ с данными статистики
 type StatsResponse struct {
 	TotalPRs     int                        `json:"total_prs"`
@@ -78,4 +331,9 @@ type StatsResponse struct {
 	PRsByStatus  map[string]int             `json:"prs_by_status"`
 	ActiveUsers  int                        `json:"active_users"`
 	TopReviewers []map[string]interface{}   `json:"top_reviewers"`
+
+	// BorrowedReviews - число назначений ревьюверов в чужие команды по командам ревьюверов
+	BorrowedReviews map[string]int `json:"borrowed_reviews"`
+	// Teams - разбивка по командам
+	Teams []domain.TeamStats `json:"teams"`
 }
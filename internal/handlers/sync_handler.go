@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"reviewer-appointment-service/internal/errors"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyncTeams приводит команды и участников к документу из тела запроса
+// @Summary Синхронизировать команды с документом YAML или JSON
+// @Description Создает и меняет команды, создает, меняет и переводит участников, деактивирует участников,
+// @Description которых нет в документе, и переназначает их открытые ревью. Все изменения - в одной транзакции.
+// @Description С dry_run=true возвращает план без изменений.
+// @Tags Admin
+// @Accept json
+// @Accept x-yaml
+// @Produce json
+// @Param dry_run query bool false "Только показать план"
+// @Param input body domain.TeamsDocument true "Команды и участники"
+// @Success 200 {object} domain.SyncPlan
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /admin/sync [post]
+func (h *Handler) SyncTeams(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		invalidParam(c, "dry_run must be true or false")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			},
+		})
+		return
+	}
+
+	doc, err := services.ParseTeamsDocument(body)
+	if err != nil {
+		syncError(c, err)
+		return
+	}
+
+	plan, err := h.teamService.Sync(c.Request.Context(), doc, dryRun)
+	if err != nil {
+		syncError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// syncError отвечает на ошибку синхронизации. Для некорректного документа
+// сообщение содержит причину, чтобы ее можно было найти в файле.
+func syncError(c *gin.Context, err error) {
+	if stderrors.Is(err, storage.ErrInvalidSyncDocument) {
+		c.JSON(http.StatusBadRequest, Response{
+			Error: &ErrorResponse{
+				Code:    string(errors.InvalidSyncDocument),
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	status, resp := errorResponse(err)
+	c.JSON(status, resp)
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"reviewer-appointment-service/internal/models/domain"
 
@@ -18,6 +17,7 @@ import (
 // @Param team body domain.Team true "Данные команды"
 // @Success 201 {object} Response{data=domain.Team}
 // @Failure 400 {object} Response
+// @Failure 403 {object} Response
 // @Failure 500 {object} Response
 // @Router /team/add [post]
 func (h *Handler) CreateTeam(c *gin.Context) {
@@ -32,13 +32,20 @@ func (h *Handler) CreateTeam(c *gin.Context) {
 		return
 	}
 
+	if err := h.policy.CanCreateTeam(c.Request.Context(), &team); err != nil {
+		status, resp := legacyErrorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
 	createdTeam, err := h.teamService.CreateTeam(c.Request.Context(), &team)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
+	setETag(c, createdTeam.Version)
 	c.JSON(http.StatusCreated, map[string]interface{}{
 		"team": createdTeam,
 	})
@@ -68,55 +75,61 @@ func (h *Handler) GetTeam(c *gin.Context) {
 
 	team, err := h.teamService.GetTeam(c.Request.Context(), teamName)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
+	setETag(c, team.Version)
 	c.JSON(http.StatusOK, team)
 }
 
-// DeactivateTeamUsers массово деактивирует пользователей команды
-// @Summary Массовая деактивация пользователей команды
-// @Description Деактивирует всех пользователей указанной команды
+// SetBuddyTeams задает команды-партнеры
+// @Summary Задать команды-партнеры для заимствования ревьюверов
+// @Description Сохраняет упорядоченный по приоритету список команд, из которых назначаются ревьюверы, если в команде автора не хватает активных участников
 // @Tags Teams
 // @Accept json
 // @Produce json
-// @Param team_id path string true "ID команды"
-// @Success 200 {object} Response
+// @Param input body SetBuddyTeamsRequest true "Команда и её партнеры"
+// @Success 200 {object} Response{data=domain.Team}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
 // @Failure 404 {object} Response
 // @Failure 500 {object} Response
-// @Router /teams/{team_id}/deactivate [post]
-func (h *Handler) DeactivateTeamUsers(c *gin.Context) {
-	teamID := c.Param("team_id")
-	if teamID == "" {
+// @Router /team/setBuddies [post]
+func (h *Handler) SetBuddyTeams(c *gin.Context) {
+	var req SetBuddyTeamsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, Response{
 			Error: &ErrorResponse{
-				Code:    "MISSING_PARAM",
-				Message: "team_id is required",
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
 			},
 		})
 		return
 	}
-	ID, err := strconv.Atoi(teamID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Error: &ErrorResponse{
-				Code:    "INVALID_PARAM",
-				Message: "team_id is not a valid integer",
-			},
-		})
+
+	if err := h.policy.CanManageTeam(c.Request.Context(), req.TeamName); err != nil {
+		status, resp := errorResponse(err)
+		c.JSON(status, resp)
 		return
 	}
 
-	err = h.teamService.DeactivateTeamUsers(c.Request.Context(), int64(ID))
+	team, err := h.teamService.SetBuddyTeams(c.Request.Context(), req.TeamName, req.BuddyTeams)
 	if err != nil {
 		status, resp := errorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
-	c.JSON(http.StatusOK, Response{
-		Data: map[string]string{"status": "users deactivated"},
+	setETag(c, team.Version)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"team": team,
 	})
 }
+
+// SetBuddyTeamsRequest представляет запрос на изменение команд-партнеров
+type SetBuddyTeamsRequest struct {
+	TeamName   string   `json:"team_name" binding:"required"`
+	BuddyTeams []string `json:"buddy_teams"`
+}
@@ -15,6 +15,7 @@ import (
 // @Param input body SetIsActiveRequest true "Данные для установки активности"
 // @Success 200 {object} Response{data=domain.User}
 // @Failure 400 {object} Response
+// @Failure 403 {object} Response
 // @Failure 404 {object} Response
 // @Failure 500 {object} Response
 // @Router /users/setIsActive [post]
@@ -30,13 +31,20 @@ func (h *Handler) SetIsActive(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.SetIsActive(c.Request.Context(), req.UserID, req.IsActive)
+	if err := h.policy.CanSetIsActive(c.Request.Context(), req.UserID); err != nil {
+		status, resp := legacyErrorResponse(err)
+		c.JSON(status, resp)
+		return
+	}
+
+	user, err := h.userService.SetIsActive(c.Request.Context(), req.UserID, *req.IsActive)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"user": user,
 	})
@@ -44,11 +52,19 @@ func (h *Handler) SetIsActive(c *gin.Context) {
 
 // GetUserReviewPRs возвращает список PR, где пользователь назначен ревьювером
 // @Summary Получить PR'ы, где пользователь назначен ревьювером
-// @Description Возвращает список PR, где указанный пользователь является ревьювером
+// @Description Возвращает PR, где указанный пользователь является ревьювером, по умолчанию сначала новые.
+// @Description Без limit и cursor возвращает все PR; с ними - страницу и next_cursor следующей
 // @Tags Users
 // @Produce json
 // @Param user_id query string true "ID пользователя"
-// @Success 200 {object} Response{data=[]domain.PullRequest}
+// @Param status query string false "OPEN или MERGED"
+// @Param team query string false "Команда автора"
+// @Param author_id query string false "ID автора"
+// @Param created_after query string false "Созданные позже (RFC3339 или YYYY-MM-DD)"
+// @Param sort query string false "created_at или -created_at (по умолчанию)"
+// @Param limit query int false "Размер страницы (максимум 100; без limit и cursor - все PR)"
+// @Param cursor query string false "next_cursor предыдущей страницы"
+// @Success 200 {object} PRPageResponse
 // @Failure 400 {object} Response
 // @Failure 404 {object} Response
 // @Failure 500 {object} Response
@@ -65,21 +81,34 @@ func (h *Handler) GetUserReviewPRs(c *gin.Context) {
 		return
 	}
 
-	prs, err := h.userService.GetUserReviewPRs(c.Request.Context(), userID)
+	filter, ok := h.parsePRFilter(c)
+	if !ok {
+		return
+	}
+
+	// Без limit и cursor v1 отвечает всеми PR, как до появления страниц
+	getReviews := h.userService.GetAllUserReviewPRs
+	if c.Query("limit") != "" || c.Query("cursor") != "" {
+		getReviews = h.userService.GetUserReviewPRs
+	}
+
+	page, err := getReviews(c.Request.Context(), userID, filter)
 	if err != nil {
-		status, resp := errorResponse(err)
+		status, resp := legacyErrorResponse(err)
 		c.JSON(status, resp)
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"user_id":       userID,
-		"pull_requests": prs,
+	c.JSON(http.StatusOK, PRPageResponse{
+		UserID:       userID,
+		PullRequests: page.PullRequests,
+		NextCursor:   page.NextCursor,
 	})
 }
 
 // SetIsActiveRequest представляет запрос на установку флага активности пользователя
+// (указатель: binding:"required" отклоняет false как нулевое значение)
 type SetIsActiveRequest struct {
 	UserID   string `json:"user_id" binding:"required"`
-	IsActive bool   `json:"is_active" binding:"required"`
+	IsActive *bool  `json:"is_active" binding:"required"`
 }
@@ -0,0 +1,558 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API v2 строится вокруг ресурсов: команды, пользователи, PR и их ревьюверы.
+// Любой ответ - конверт Response: успешный в поле data, ошибка в поле
+// error. Действия, которые не укладываются в методы HTTP, вызываются как
+// пользовательские методы ресурса через двоеточие: POST
+// /pull-requests/{id}:merge.
+
+// Пользовательские методы ресурсов API v2
+const (
+	methodMerge    = "merge"
+	methodReassign = "reassign"
+	methodReview   = "review"
+)
+
+// V2NoRoute отвечает на неизвестный путь API v2 ошибкой в конверте
+func V2NoRoute(c *gin.Context) {
+	c.JSON(http.StatusNotFound, Response{
+		Error: &ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "route not found",
+		},
+	})
+}
+
+// V2CreateTeam создает команду с участниками
+// @Summary Создать команду
+// @Tags v2 Teams
+// @Accept json
+// @Produce json
+// @Param team body domain.Team true "Данные команды"
+// @Success 201 {object} Response{data=domain.Team}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/teams [post]
+func (h *Handler) V2CreateTeam(c *gin.Context) {
+	var team domain.Team
+	if err := c.ShouldBindJSON(&team); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	if err := h.policy.CanCreateTeam(c.Request.Context(), &team); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	created, err := h.teamService.CreateTeam(c.Request.Context(), &team)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, created.Version)
+	c.Header("Location", "/api/v2/teams/"+url.PathEscape(created.Name))
+	respond(c, http.StatusCreated, created)
+}
+
+// V2GetTeam возвращает команду с участниками
+// @Summary Получить команду
+// @Tags v2 Teams
+// @Produce json
+// @Param name path string true "Название команды"
+// @Success 200 {object} Response{data=domain.Team}
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/teams/{name} [get]
+func (h *Handler) V2GetTeam(c *gin.Context) {
+	team, err := h.teamService.GetTeam(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, team.Version)
+	respond(c, http.StatusOK, team)
+}
+
+// V2UpdateTeam меняет настройки команды. Пока изменяемы только
+// команды-партнеры
+// @Summary Изменить команду
+// @Tags v2 Teams
+// @Accept json
+// @Produce json
+// @Param name path string true "Название команды"
+// @Param input body V2UpdateTeamRequest true "Команды-партнеры"
+// @Success 200 {object} Response{data=domain.Team}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/teams/{name} [patch]
+func (h *Handler) V2UpdateTeam(c *gin.Context) {
+	var req V2UpdateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	teamName := c.Param("name")
+	if err := h.policy.CanManageTeam(c.Request.Context(), teamName); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	team, err := h.teamService.SetBuddyTeams(c.Request.Context(), teamName, *req.BuddyTeams)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, team.Version)
+	respond(c, http.StatusOK, team)
+}
+
+// V2ListTeamMembers возвращает участников команды
+// @Summary Участники команды
+// @Tags v2 Teams
+// @Produce json
+// @Param name path string true "Название команды"
+// @Success 200 {object} Response{data=[]domain.User}
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/teams/{name}/members [get]
+func (h *Handler) V2ListTeamMembers(c *gin.Context) {
+	team, err := h.teamService.GetTeam(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, nonNil(team.Users))
+}
+
+// V2GetUser возвращает пользователя
+// @Summary Получить пользователя
+// @Tags v2 Users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} Response{data=domain.User}
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/users/{id} [get]
+func (h *Handler) V2GetUser(c *gin.Context) {
+	user, err := h.userService.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
+	respond(c, http.StatusOK, user)
+}
+
+// V2UpdateUser меняет флаг активности пользователя
+// @Summary Изменить пользователя
+// @Tags v2 Users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param input body V2UpdateUserRequest true "Флаг активности"
+// @Success 200 {object} Response{data=domain.User}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/users/{id} [patch]
+func (h *Handler) V2UpdateUser(c *gin.Context) {
+	var req V2UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	userID := c.Param("id")
+	if err := h.policy.CanSetIsActive(c.Request.Context(), userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	user, err := h.userService.SetIsActive(c.Request.Context(), userID, *req.IsActive)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
+	respond(c, http.StatusOK, user)
+}
+
+// V2ListUserReviews возвращает страницу PR, где пользователь назначен
+// ревьювером. Параметры - как у /pullRequest/list, курсор следующей
+// страницы - в meta.next_cursor.
+// @Summary PR на ревью у пользователя
+// @Tags v2 Users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param status query string false "OPEN или MERGED"
+// @Param team query string false "Команда автора"
+// @Param author_id query string false "ID автора"
+// @Param created_after query string false "Созданные позже (RFC3339 или YYYY-MM-DD)"
+// @Param sort query string false "created_at или -created_at (по умолчанию)"
+// @Param limit query int false "Размер страницы (по умолчанию 50, максимум 100)"
+// @Param cursor query string false "meta.next_cursor предыдущей страницы"
+// @Success 200 {object} Response{data=[]domain.PullRequest}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/users/{id}/reviews [get]
+func (h *Handler) V2ListUserReviews(c *gin.Context) {
+	filter, ok := h.parsePRFilter(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.userService.GetUserReviewPRs(c.Request.Context(), c.Param("id"), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	resp := Response{Data: page.PullRequests}
+	if page.NextCursor != "" {
+		resp.Meta = &PageMeta{NextCursor: page.NextCursor}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// V2CreatePR создает PR и назначает ревьюверов
+// @Summary Создать PR
+// @Tags v2 PullRequests
+// @Accept json
+// @Produce json
+// @Param input body CreatePRRequest true "Данные для создания PR"
+// @Success 201 {object} Response{data=domain.PullRequest}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests [post]
+func (h *Handler) V2CreatePR(c *gin.Context) {
+	var req CreatePRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	pr, err := h.prService.CreatePR(c.Request.Context(), req.PRID, req.PRName, req.AuthorID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	c.Header("Location", "/api/v2/pull-requests/"+url.PathEscape(pr.PullRequestID))
+	respond(c, http.StatusCreated, pr)
+}
+
+// V2GetPR возвращает PR с ревьюверами
+// @Summary Получить PR
+// @Tags v2 PullRequests
+// @Produce json
+// @Param id path string true "ID PR"
+// @Success 200 {object} Response{data=domain.PullRequest}
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests/{id} [get]
+func (h *Handler) V2GetPR(c *gin.Context) {
+	pr, err := h.prService.GetPR(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusOK, pr)
+}
+
+// V2PRMethod вызывает пользовательский метод PR. Поддерживается только
+// :merge - пометить PR как MERGED (идемпотентно)
+// @Summary Пользовательский метод PR
+// @Tags v2 PullRequests
+// @Produce json
+// @Param id path string true "ID PR с методом, например pr-1:merge"
+// @Success 200 {object} Response{data=domain.PullRequest}
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests/{id} [post]
+func (h *Handler) V2PRMethod(c *gin.Context) {
+	prID, method := splitMethod(c.Param("id"))
+	if method != methodMerge {
+		V2NoRoute(c)
+		return
+	}
+
+	pr, err := h.prService.MergePR(c.Request.Context(), prID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusOK, pr)
+}
+
+// V2ListReviewers возвращает назначения ревьюверов PR
+// @Summary Ревьюверы PR
+// @Tags v2 PullRequests
+// @Produce json
+// @Param id path string true "ID PR"
+// @Success 200 {object} Response{data=[]domain.ReviewerAssignment}
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests/{id}/reviewers [get]
+func (h *Handler) V2ListReviewers(c *gin.Context) {
+	pr, err := h.prService.GetPR(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusOK, nonNil(pr.Assignments))
+}
+
+// V2AddReviewer вручную назначает ревьювера на PR
+// @Summary Назначить ревьювера
+// @Tags v2 PullRequests
+// @Accept json
+// @Produce json
+// @Param id path string true "ID PR"
+// @Param input body V2AddReviewerRequest true "Ревьювер"
+// @Success 201 {object} Response{data=[]domain.ReviewerAssignment}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests/{id}/reviewers [post]
+func (h *Handler) V2AddReviewer(c *gin.Context) {
+	var req V2AddReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	prID := c.Param("id")
+	if err := h.policy.CanAddReviewer(c.Request.Context(), prID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	pr, err := h.prService.AddReviewer(c.Request.Context(), prID, req.ReviewerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusCreated, nonNil(pr.Assignments))
+}
+
+// V2RemoveReviewer снимает ревьювера с PR. Если ревьюверов становится
+// меньше минимума команды, недостающие назначаются автоматически; если
+// назначить некого, ревьювер не снимается и возвращается NO_CANDIDATE
+// @Summary Снять ревьювера
+// @Tags v2 PullRequests
+// @Produce json
+// @Param id path string true "ID PR"
+// @Param user_id path string true "ID ревьювера"
+// @Success 200 {object} Response{data=[]domain.ReviewerAssignment}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests/{id}/reviewers/{user_id} [delete]
+func (h *Handler) V2RemoveReviewer(c *gin.Context) {
+	prID, reviewerID := c.Param("id"), c.Param("user_id")
+	if err := h.policy.CanChangeReviewer(c.Request.Context(), prID, reviewerID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	pr, err := h.prService.RemoveReviewer(c.Request.Context(), prID, reviewerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusOK, nonNil(pr.Assignments))
+}
+
+// V2ReviewerMethod вызывает пользовательский метод ревьювера PR:
+// :reassign - заменить ревьювера (тело как у /pullRequest/reassign без
+// идентификаторов, необязательно), :review - отметить ревью
+// @Summary Пользовательский метод ревьювера PR
+// @Tags v2 PullRequests
+// @Accept json
+// @Produce json
+// @Param id path string true "ID PR"
+// @Param user_id path string true "ID ревьювера с методом, например u2:reassign"
+// @Param input body V2ReassignRequest false "Параметры замены для :reassign"
+// @Success 200 {object} Response{data=V2ReassignResponse}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/v2/pull-requests/{id}/reviewers/{user_id} [post]
+func (h *Handler) V2ReviewerMethod(c *gin.Context) {
+	prID := c.Param("id")
+	reviewerID, method := splitMethod(c.Param("user_id"))
+
+	switch method {
+	case methodReassign:
+		h.v2Reassign(c, prID, reviewerID)
+	case methodReview:
+		h.v2MarkReviewed(c, prID, reviewerID)
+	default:
+		V2NoRoute(c)
+	}
+}
+
+func (h *Handler) v2Reassign(c *gin.Context, prID, reviewerID string) {
+	// Тело необязательно: без него замена выбирается как в /pullRequest/reassign
+	var req V2ReassignRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		invalidRequest(c)
+		return
+	}
+
+	if err := h.policy.CanChangeReviewer(c.Request.Context(), prID, reviewerID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	result, err := h.prService.ReassignReviewerWithOptions(c.Request.Context(), prID, reviewerID, services.ReassignOptions{
+		NewReviewerID: req.NewReviewerID,
+		Exclude:       req.Exclude,
+		Prefer:        req.Prefer,
+		TeamName:      req.TeamName,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	pr, err := h.prService.GetPR(c.Request.Context(), prID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusOK, V2ReassignResponse{
+		ReplacedBy: result.ReplacedBy,
+		Reviewers:  nonNil(result.Reviewers),
+	})
+}
+
+func (h *Handler) v2MarkReviewed(c *gin.Context, prID, reviewerID string) {
+	if err := h.policy.CanMarkReviewed(c.Request.Context(), reviewerID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	pr, err := h.prService.MarkReviewed(c.Request.Context(), prID, reviewerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pr.Version)
+	respond(c, http.StatusOK, nonNil(pr.Assignments))
+}
+
+// V2UpdateTeamRequest представляет изменение команды в API v2
+type V2UpdateTeamRequest struct {
+	BuddyTeams *[]string `json:"buddy_teams" binding:"required"`
+}
+
+// V2UpdateUserRequest представляет изменение пользователя в API v2
+type V2UpdateUserRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+// V2AddReviewerRequest представляет назначение ревьювера в API v2
+type V2AddReviewerRequest struct {
+	ReviewerID string `json:"reviewer_id" binding:"required"`
+}
+
+// V2ReassignRequest представляет параметры замены ревьювера в API v2
+type V2ReassignRequest struct {
+	NewReviewerID string   `json:"new_reviewer_id,omitempty"`
+	Exclude       []string `json:"exclude,omitempty"`
+	Prefer        []string `json:"prefer,omitempty"`
+	TeamName      string   `json:"team_name,omitempty"`
+}
+
+// V2ReassignResponse - итог замены ревьювера
+type V2ReassignResponse struct {
+	ReplacedBy string                      `json:"replaced_by"`
+	Reviewers  []domain.ReviewerAssignment `json:"reviewers"`
+}
+
+// splitMethod отделяет пользовательский метод от идентификатора ресурса:
+// "pr-1:merge" - ("pr-1", "merge")
+func splitMethod(value string) (string, string) {
+	i := strings.LastIndex(value, ":")
+	if i < 0 {
+		return value, ""
+	}
+	return value[:i], value[i+1:]
+}
+
+// nonNil подменяет nil-срез пустым, чтобы список в data всегда был массивом
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func respond(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, Response{Data: data})
+}
+
+func respondError(c *gin.Context, err error) {
+	status, resp := errorResponse(err)
+	c.JSON(status, resp)
+}
+
+func invalidRequest(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, Response{
+		Error: &ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		},
+	})
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys - ключи атрибутов, значения которых не попадают в лог
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+	"authorization": true,
+	"api_key":       true,
+}
+
+var (
+	dsnPasswordRe = regexp.MustCompile(`(?i)(password=)('[^']*'|\S+)`)
+	urlPasswordRe = regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`)
+)
+
+type ctxKey struct{}
+
+// New создает JSON-логгер с уровнем level (debug, info, warn, error).
+// Значения атрибутов с чувствительными ключами заменяются на [REDACTED].
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       lvl,
+		ReplaceAttr: redactAttr,
+	})
+	return slog.New(handler), nil
+}
+
+// ParseLevel разбирает уровень логирования; пустая строка - info
+func ParseLevel(level string) (slog.Level, error) {
+	var lvl slog.Level
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return lvl, fmt.Errorf("logger: invalid level %q", level)
+	}
+	return lvl, nil
+}
+
+// RedactDSN скрывает пароль в строке подключения (key=value или URL)
+func RedactDSN(dsn string) string {
+	dsn = dsnPasswordRe.ReplaceAllString(dsn, "${1}"+redacted)
+	return urlPasswordRe.ReplaceAllString(dsn, "${1}"+redacted+"@")
+}
+
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	switch {
+	case sensitiveKeys[key]:
+		return slog.String(a.Key, redacted)
+	case key == "dsn":
+		return slog.String(a.Key, RedactDSN(a.Value.String()))
+	}
+	return a
+}
+
+// WithContext возвращает контекст с логгером l
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext возвращает логгер запроса или логгер по умолчанию
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "reviewer_service"
+
+// collectTimeout ограничивает запросы к БД при сборе метрик
+const collectTimeout = 2 * time.Second
+
+// countsTTL - сколько сбор метрик отдает сохраненные счетчики PR, не
+// обращаясь к БД. Несколько Prometheus с частым scrape не нагружают БД.
+const countsTTL = 30 * time.Second
+
+// PRCountsSource возвращает счетчики открытых PR для gauge-метрик
+type PRCountsSource interface {
+	GetOpenPRCounts(ctx context.Context) (*domain.OpenPRCounts, error)
+}
+
+// Metrics хранит метрики сервиса в собственном реестре
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	assignments  *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .2, .3, .5, 1, 2.5, 5},
+		}, []string{"method", "route", "status"}),
+		assignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_assignments_total",
+			Help:      "Reviewer selection outcomes by strategy: assignment reason or NO_CANDIDATE.",
+		}, []string{"strategy", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.assignments,
+	)
+
+	return m
+}
+
+// Middleware считает запросы и их длительность. Маршрут берется из шаблона
+// Gin, чтобы параметры пути не раздували число серий.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveAssignment реализует services.AssignmentObserver
+func (m *Metrics) ObserveAssignment(strategy, outcome string, count int) {
+	m.assignments.WithLabelValues(strategy, outcome).Add(float64(count))
+}
+
+// RegisterPool добавляет статистику пула соединений pgxpool
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	m.registry.MustRegister(newPoolCollector(pool))
+}
+
+// RegisterPRCounts добавляет gauge-метрики открытых и недоукомплектованных
+// PR. Значения запрашиваются из source не чаще раза в countsTTL.
+func (m *Metrics) RegisterPRCounts(source PRCountsSource) {
+	m.registry.MustRegister(newPRCountsCollector(source))
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquireCount      *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+	emptyAcquireCount *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_conns", "Connections currently in use."),
+		idleConns:         desc("idle_conns", "Idle connections in the pool."),
+		totalConns:        desc("total_conns", "Total connections in the pool."),
+		maxConns:          desc("max_conns", "Maximum size of the pool."),
+		acquireCount:      desc("acquires_total", "Successful connection acquires."),
+		acquireDuration:   desc("acquire_duration_seconds_total", "Total time spent waiting for a connection."),
+		emptyAcquireCount: desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquires:  desc("canceled_acquires_total", "Acquires canceled by context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+type prCountsCollector struct {
+	source PRCountsSource
+	now    func() time.Time
+
+	mu        sync.Mutex
+	counts    *domain.OpenPRCounts
+	fetchedAt time.Time
+
+	open         *prometheus.Desc
+	understaffed *prometheus.Desc
+}
+
+func newPRCountsCollector(source PRCountsSource) *prCountsCollector {
+	return &prCountsCollector{
+		source: source,
+		now:    time.Now,
+		open: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "open_prs"),
+			"Open pull requests.", nil, nil),
+		understaffed: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "understaffed_prs"),
+			"Open pull requests with fewer reviewers than the author team minimum.", nil, nil),
+	}
+}
+
+func (c *prCountsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.understaffed
+}
+
+func (c *prCountsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.load()
+	if err != nil {
+		slog.Error("failed to collect PR counts for metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.open, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(counts.Open))
+	ch <- prometheus.MustNewConstMetric(c.understaffed, prometheus.GaugeValue, float64(counts.Understaffed))
+}
+
+// load возвращает сохраненные счетчики, пока они не старше countsTTL, иначе
+// запрашивает новые. Ошибка не сохраняется: следующий сбор повторит запрос.
+func (c *prCountsCollector) load() (*domain.OpenPRCounts, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.counts != nil && now.Sub(c.fetchedAt) < countsTTL {
+		return c.counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	counts, err := c.source.GetOpenPRCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.counts, c.fetchedAt = counts, now
+	return counts, nil
+}
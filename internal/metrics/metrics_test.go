@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCounts struct {
+	counts *domain.OpenPRCounts
+	err    error
+	calls  int
+}
+
+func (f *fakeCounts) GetOpenPRCounts(ctx context.Context) (*domain.OpenPRCounts, error) {
+	f.calls++
+	return f.counts, f.err
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/team/get", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/team/get?team_name=a", "/team/get?team_name=b", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/team/get", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `reviewer_service_http_request_duration_seconds_count{method="GET",route="/team/get",status="404"} 2`)
+}
+
+func TestObserveAssignment(t *testing.T) {
+	m := New()
+
+	m.ObserveAssignment("random", "AUTO", 2)
+	m.ObserveAssignment("random", "NO_CANDIDATE", 1)
+	m.ObserveAssignment("random", "AUTO", 1)
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.assignments.WithLabelValues("random", "AUTO")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.assignments.WithLabelValues("random", "NO_CANDIDATE")))
+}
+
+func TestPRCounts(t *testing.T) {
+	t.Run("counts", func(t *testing.T) {
+		m := New()
+		m.RegisterPRCounts(&fakeCounts{counts: &domain.OpenPRCounts{Open: 5, Understaffed: 2}})
+
+		body := scrape(t, m)
+		assert.Contains(t, body, "reviewer_service_open_prs 5")
+		assert.Contains(t, body, "reviewer_service_understaffed_prs 2")
+	})
+
+	t.Run("source error keeps other metrics", func(t *testing.T) {
+		m := New()
+		m.RegisterPRCounts(&fakeCounts{err: errors.New("db is down")})
+		m.ObserveAssignment("rotation", "AUTO", 1)
+
+		body := scrape(t, m)
+		assert.False(t, strings.Contains(body, "reviewer_service_open_prs"))
+		assert.Contains(t, body, `reviewer_service_reviewer_assignments_total{outcome="AUTO",strategy="rotation"} 1`)
+	})
+
+	t.Run("counts are cached between scrapes", func(t *testing.T) {
+		source := &fakeCounts{counts: &domain.OpenPRCounts{Open: 5}}
+		collector := newPRCountsCollector(source)
+		now := time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC)
+		collector.now = func() time.Time { return now }
+
+		m := New()
+		m.registry.MustRegister(collector)
+
+		scrape(t, m)
+		now = now.Add(countsTTL / 2)
+		scrape(t, m)
+		assert.Equal(t, 1, source.calls)
+
+		source.counts = &domain.OpenPRCounts{Open: 7}
+		now = now.Add(countsTTL)
+		assert.Contains(t, scrape(t, m), "reviewer_service_open_prs 7")
+		assert.Equal(t, 2, source.calls)
+	})
+}
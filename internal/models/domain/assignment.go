@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Причины назначения ревьювера на PR
+const (
+	AssignReasonAuto      = "AUTO"
+	AssignReasonManual    = "MANUAL"
+	AssignReasonBackfill  = "BACKFILL"
+	AssignReasonReassign  = "REASSIGN"
+	AssignReasonRequested = "REQUESTED"
+	AssignReasonPreferred = "PREFERRED"
+	AssignReasonBorrowed  = "BORROWED"
+	AssignReasonEscalated = "ESCALATED"
+)
+
+// ReviewerAssignment описывает назначение ревьювера на PR вместе с причиной
+type ReviewerAssignment struct {
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	TeamID     int64      `json:"team_id"`
+	Reason     string     `json:"reason"`
+	Borrowed   bool       `json:"borrowed"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+// Статусы проверок готовности
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// PoolStats - состояние пула соединений с БД
+type PoolStats struct {
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	MaxConns      int32 `json:"max_conns"`
+}
+
+// HealthCheck - результат одной проверки зависимости
+type HealthCheck struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthReport - результат проверки готовности сервиса
+type HealthReport struct {
+	Status  string                 `json:"status"`
+	Version string                 `json:"version"`
+	Checks  map[string]HealthCheck `json:"checks"`
+}
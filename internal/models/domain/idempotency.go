@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord - сохраненный результат запроса с Idempotency-Key.
+// StatusCode == 0 - запрос еще выполняется.
+type IdempotencyRecord struct {
+	OrgID        int64
+	Client       string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// Completed сообщает, что ответ на запрос уже сохранен
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Organization - организация (арендатор). Команды, пользователи и PR разных
+// организаций полностью изолированы; slug используется в заголовке X-Org и
+// в CLI.
+type Organization struct {
+	ID        int64     `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
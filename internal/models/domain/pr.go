@@ -1,6 +1,12 @@
 package domain
 
-import "time"
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 const (
 	PRStatusOpen   = "OPEN"
@@ -14,7 +20,83 @@ type PullRequest struct {
 	AuthorID        int64      `json:"author_id"`
 	StatusID        int        `json:"status_id"`
 	MergedAt        *time.Time `json:"merged_at"`
+	Version         int        `json:"version"`
 	CreatedAt       time.Time  `json:"created_at"`
 	Author          *User      `json:"author,omitempty"`
 	Reviewers       []User     `json:"reviewers,omitempty"`
+
+	Assignments []ReviewerAssignment `json:"assignments,omitempty"`
+}
+
+// Порядок списка PR: по времени создания, по умолчанию сначала новые
+const (
+	PRSortCreatedAsc  = "created_at"
+	PRSortCreatedDesc = "-created_at"
+)
+
+// PRFilter - фильтры, порядок и страница списка PR. Пустые поля не
+// ограничивают выборку.
+type PRFilter struct {
+	ReviewerID   string // user_id назначенного ревьювера
+	AuthorID     string // user_id автора
+	StatusID     int
+	TeamName     string // команда автора
+	CreatedAfter time.Time
+	Sort         string
+	Limit        int
+	// After - последний PR предыдущей страницы
+	After *PRCursor
+}
+
+// PRCursor - позиция в списке PR. Порядок (created_at, id) однозначен, поэтому
+// страницы не теряют и не повторяют PR, созданные в одну и ту же секунду.
+type PRCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// Encode возвращает непрозрачное для клиента представление курсора
+func (c PRCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParsePRCursor разбирает курсор, полученный из Encode
+func ParsePRCursor(cursor string) (*PRCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	prID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return &PRCursor{CreatedAt: time.Unix(0, n).UTC(), ID: prID}, nil
+}
+
+// PRPage - страница списка PR. NextCursor пуст на последней странице.
+type PRPage struct {
+	PullRequests []PullRequest
+	NextCursor   string
+}
+
+// PlannedPR - новый PR с заранее выбранными ревьюверами. Используется при
+// пакетном создании, когда PR и назначения сохраняются вместе.
+type PlannedPR struct {
+	PullRequest *PullRequest
+	Reviewers   []PlannedReviewer
+}
+
+// PlannedReviewer - выбранный ревьювер и причина назначения
+type PlannedReviewer struct {
+	ReviewerID int64
+	Reason     string
 }
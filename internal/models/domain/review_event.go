@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Виды событий SLA ревью
+const (
+	ReviewEventReminder   = "REMINDER"
+	ReviewEventEscalation = "ESCALATION"
+)
+
+// Действия, выполненные при эскалации
+const (
+	EscalationLeadAdded   = "LEAD_ADDED"
+	EscalationReassigned  = "REASSIGNED"
+	EscalationNoCandidate = "NO_CANDIDATE"
+)
+
+// StaleReview описывает назначение ревьювера, превысившее SLA команды автора.
+// OrgID - организация PR: планировщик обходит все организации сразу.
+type StaleReview struct {
+	OrgID          int64     `json:"-"`
+	PRID           int64     `json:"-"`
+	PullRequestID  string    `json:"pull_request_id"`
+	ReviewerID     int64     `json:"-"`
+	ReviewerUserID string    `json:"reviewer_id"`
+	TeamID         int64     `json:"team_id"`
+	AssignedAt     time.Time `json:"assigned_at"`
+}
+
+// ReviewEvent фиксирует напоминание или эскалацию по назначению ревьювера
+type ReviewEvent struct {
+	ID             int64     `json:"id"`
+	PRID           int64     `json:"-"`
+	PullRequestID  string    `json:"pull_request_id"`
+	ReviewerID     int64     `json:"-"`
+	ReviewerUserID string    `json:"reviewer_id"`
+	AssignedAt     time.Time `json:"assigned_at"`
+	Kind           string    `json:"kind"`
+	Action         string    `json:"action,omitempty"`
+	TargetUserID   string    `json:"target_user_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
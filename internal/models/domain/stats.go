@@ -1,7 +1,122 @@
 package domain
 
+import "time"
+
 type ReviewerStats struct {
 	UserID      string `json:"user_id"`
 	Username    string `json:"username"`
 	ReviewCount int    `json:"review_count"`
 }
+
+// ReviewPairStats - сколько раз ревьювер был назначен на PR автора
+type ReviewPairStats struct {
+	AuthorID         string `json:"author_id"`
+	AuthorUsername   string `json:"author_username"`
+	ReviewerID       string `json:"reviewer_id"`
+	ReviewerUsername string `json:"reviewer_username"`
+	ReviewCount      int    `json:"review_count"`
+}
+
+// StatsFilter ограничивает статистику периодом [From, To) и командой.
+// Нулевое время означает отсутствие границы.
+type StatsFilter struct {
+	From     time.Time
+	To       time.Time
+	TeamName string
+	Limit    int
+}
+
+// TeamStats - разбивка статистики по команде
+type TeamStats struct {
+	TeamName         string          `json:"team_name"`
+	OpenPRs          int             `json:"open_prs"`
+	MergedPRs        int             `json:"merged_prs"`
+	ActiveMembers    int             `json:"active_members"`
+	InactiveMembers  int             `json:"inactive_members"`
+	ReviewsPerMember []ReviewerStats `json:"reviews_per_member"`
+}
+
+// Latency - распределение длительностей в секундах. Медиана и p90 равны nil,
+// если в выборке нет значений.
+type Latency struct {
+	Count         int      `json:"count"`
+	MedianSeconds *float64 `json:"median_seconds"`
+	P90Seconds    *float64 `json:"p90_seconds"`
+}
+
+// TeamLatency - время до первого ревью и до мержа для PR авторов команды
+type TeamLatency struct {
+	TeamName          string  `json:"team_name,omitempty"`
+	TimeToFirstReview Latency `json:"time_to_first_review"`
+	TimeToMerge       Latency `json:"time_to_merge"`
+}
+
+// ReviewerLatency - время от создания PR до действия ревьювера и до мержа
+// для PR, которые он отревьюил
+type ReviewerLatency struct {
+	UserID       string  `json:"user_id"`
+	Username     string  `json:"username"`
+	TimeToReview Latency `json:"time_to_review"`
+	TimeToMerge  Latency `json:"time_to_merge"`
+}
+
+// LatencyReport - метрики задержек ревью за период
+type LatencyReport struct {
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Overall   TeamLatency       `json:"overall"`
+	Teams     []TeamLatency     `json:"teams"`
+	Reviewers []ReviewerLatency `json:"reviewers"`
+}
+
+// MemberWorkload - нагрузка участника команды за период. Assignments
+// включает назначения, которые позже были сняты или переназначены
+// (ReassignedAway).
+type MemberWorkload struct {
+	TeamName       string   `json:"-"`
+	UserID         string   `json:"user_id"`
+	Username       string   `json:"username"`
+	Assignments    int      `json:"assignments"`
+	ReassignedAway int      `json:"reassigned_away"`
+	ActiveSeconds  float64  `json:"active_seconds"`
+	ActualShare    float64  `json:"actual_share"`
+	ExpectedShare  float64  `json:"expected_share"`
+	Ratio          *float64 `json:"ratio"`
+}
+
+// TeamFairness - распределение назначений внутри команды. Ожидаемая доля
+// участника пропорциональна времени, когда он был активен в периоде.
+type TeamFairness struct {
+	TeamName         string           `json:"team_name"`
+	TotalAssignments int              `json:"total_assignments"`
+	Gini             *float64         `json:"gini"`
+	MaxMinRatio      *float64         `json:"max_min_ratio"`
+	Members          []MemberWorkload `json:"members"`
+	Outliers         []string         `json:"outliers"`
+}
+
+// FairnessReport - отчет о равномерности нагрузки за период
+type FairnessReport struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Threshold float64        `json:"threshold"`
+	Teams     []TeamFairness `json:"teams"`
+}
+
+// OpenPRCounts - число открытых PR и открытых PR, у которых ревьюверов
+// меньше минимума команды автора
+type OpenPRCounts struct {
+	Open         int `json:"open"`
+	Understaffed int `json:"understaffed"`
+}
+
+// OpenPRGroup - число открытых PR с одинаковым числом ревьюверов, авторы
+// которых состоят в командах с одинаковыми границами числа ревьюверов.
+// HasTeam равен false, если автор не состоит в команде.
+type OpenPRGroup struct {
+	HasTeam      bool
+	MinReviewers int
+	MaxReviewers int
+	Reviewers    int
+	Count        int
+}
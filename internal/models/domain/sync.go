@@ -0,0 +1,76 @@
+package domain
+
+// TeamsDocument - желаемый состав команд организации, например из файла в
+// git. Участники, которых нет ни в одной команде документа, деактивируются.
+type TeamsDocument struct {
+	Teams []TeamSpec `json:"teams" yaml:"teams"`
+}
+
+// TeamSpec - команда документа. Нулевые настройки означают значения по
+// умолчанию, как при создании команды.
+type TeamSpec struct {
+	Name                   string       `json:"name" yaml:"name"`
+	MinReviewers           int          `json:"min_reviewers" yaml:"min_reviewers"`
+	MaxReviewers           int          `json:"max_reviewers" yaml:"max_reviewers"`
+	ReminderAfterMinutes   int          `json:"reminder_after_minutes" yaml:"reminder_after_minutes"`
+	EscalationAfterMinutes int          `json:"escalation_after_minutes" yaml:"escalation_after_minutes"`
+	BuddyTeams             []string     `json:"buddy_teams" yaml:"buddy_teams"`
+	Members                []MemberSpec `json:"members" yaml:"members"`
+}
+
+// MemberSpec - участник команды документа. Без is_active участник активен.
+type MemberSpec struct {
+	UserID   string `json:"user_id" yaml:"user_id"`
+	Username string `json:"username" yaml:"username"`
+	IsActive *bool  `json:"is_active" yaml:"is_active"`
+	Role     string `json:"role" yaml:"role"`
+}
+
+// Действия плана синхронизации
+const (
+	SyncCreate     = "create"
+	SyncUpdate     = "update"
+	SyncMove       = "move"
+	SyncDeactivate = "deactivate"
+)
+
+// SyncPlan - изменения, которые приводят команды и участников к документу.
+// Reassignments - открытые ревью участников, которые перестают быть
+// активными; NewReviewerID в них известен только после применения.
+type SyncPlan struct {
+	DryRun        bool               `json:"dry_run"`
+	Teams         []TeamChange       `json:"teams"`
+	Users         []UserChange       `json:"users"`
+	Reassignments []SyncReassignment `json:"reassignments"`
+}
+
+// TeamChange - создание или изменение настроек команды. Changes описывает
+// измененные поля в виде "поле: было -> стало".
+type TeamChange struct {
+	Action  string   `json:"action"`
+	Team    string   `json:"team"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// UserChange - создание, изменение, перевод в другую команду или
+// деактивация участника
+type UserChange struct {
+	Action   string   `json:"action"`
+	UserID   string   `json:"user_id"`
+	Team     string   `json:"team"`
+	FromTeam string   `json:"from_team,omitempty"`
+	Changes  []string `json:"changes,omitempty"`
+}
+
+// SyncReassignment - ревью участника, который перестает быть активным.
+// NewReviewerID пуст до применения или если замены не нашлось.
+type SyncReassignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+// IsEmpty сообщает, что документ уже совпадает с БД
+func (p *SyncPlan) IsEmpty() bool {
+	return len(p.Teams) == 0 && len(p.Users) == 0 && len(p.Reassignments) == 0
+}
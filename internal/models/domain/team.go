@@ -3,8 +3,14 @@ package domain
 import "time"
 
 type Team struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	Users     []User    `json:"users,omitempty"`
+	ID                     int64     `json:"id"`
+	Name                   string    `json:"name"`
+	MinReviewers           int       `json:"min_reviewers"`
+	MaxReviewers           int       `json:"max_reviewers"`
+	ReminderAfterMinutes   int       `json:"reminder_after_minutes"`   // 0 - SLA по умолчанию
+	EscalationAfterMinutes int       `json:"escalation_after_minutes"` // 0 - SLA по умолчанию
+	BuddyTeams             []string  `json:"buddy_teams,omitempty"`
+	Version                int       `json:"version"`
+	CreatedAt              time.Time `json:"created_at"`
+	Users                  []User    `json:"users,omitempty"`
 }
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Области доступа токенов API. ScopeAdmin включает все остальные.
+const (
+	ScopeAdmin     = "admin"
+	ScopeTeamWrite = "team:write"
+	ScopePRWrite   = "pr:write"
+	ScopeRead      = "read"
+)
+
+// Scopes - все допустимые области доступа
+var Scopes = []string{ScopeAdmin, ScopeTeamWrite, ScopePRWrite, ScopeRead}
+
+// ValidScope проверяет, что область доступа известна
+func ValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// APIToken - токен доступа к API. Сам токен не хранится, только его хеш.
+// UserID - пользователь, от имени которого действует токен; у токенов
+// интеграций он пустой. OrgID - организация, к данным которой дает доступ
+// токен.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	OrgID      int64      `json:"org_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	UserID     string     `json:"user_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	Hash string `json:"-"`
+}
+
+// HasScope проверяет, что токен дает доступ к области scope
+func (t *APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, ScopeAdmin) || slices.Contains(t.Scopes, scope)
+}
+
+// Usable проверяет, что токен не отозван и не истек к моменту now
+func (t *APIToken) Usable(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// CreatedToken - результат выпуска токена: Token возвращается только один раз
+type CreatedToken struct {
+	Token    string    `json:"token"`
+	APIToken *APIToken `json:"api_token"`
+}
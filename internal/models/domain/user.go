@@ -2,11 +2,19 @@ package domain
 
 import "time"
 
+// Роли участника команды
+const (
+	RoleMember = "member"
+	RoleLead   = "lead"
+)
+
 type User struct {
 	ID        int64     `db:"id" json:"id"`
 	UserID    string    `db:"user_id" json:"user_id"`
 	Username  string    `db:"username" json:"username"`
 	IsActive  bool      `db:"is_active" json:"is_active"`
 	TeamID    int64     `db:"team_id" json:"team_id"`
+	Role      string    `db:"role" json:"role,omitempty"`
+	Version   int       `db:"version" json:"version,omitempty"`
 	CreatedAt time.Time `db:"created_at" json:"created_at,omitempty"`
 }
@@ -0,0 +1,155 @@
+// Package ratelimit ограничивает частоту запросов по алгоритму token bucket:
+// у каждого клиента своя корзина емкостью Burst, которая пополняется со
+// скоростью Rate токенов в секунду, а каждый запрос забирает один токен.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval - как часто удаляются корзины неактивных клиентов
+const sweepInterval = time.Minute
+
+// Limit - скорость пополнения (запросов в секунду) и емкость корзины.
+// Rate <= 0 - без ограничений.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited сообщает, что лимит не ограничивает запросы
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Decision - результат проверки запроса
+type Decision struct {
+	Allowed bool
+	// Limit - емкость корзины; 0 - лимит не задан
+	Limit int
+	// Remaining - целых токенов осталось после запроса
+	Remaining int
+	// Reset - через сколько корзина полностью пополнится
+	Reset time.Duration
+	// RetryAfter - при отказе: через сколько появится токен
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter хранит корзины клиентов одного лимита
+type Limiter struct {
+	limit Limit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(limit Limit) *Limiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &Limiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow забирает токен из корзины клиента key, если он есть
+func (l *Limiter) Allow(key string, now time.Time) Decision {
+	if l.limit.Unlimited() {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	burst := float64(l.limit.Burst)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	} else if now.After(b.last) {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+		b.last = now
+	}
+
+	d := Decision{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.refillTime(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.refillTime(burst - b.tokens)
+
+	return d
+}
+
+func (l *Limiter) refillTime(tokens float64) time.Duration {
+	return time.Duration(tokens / l.limit.Rate * float64(time.Second))
+}
+
+// sweep удаляет полностью пополнившиеся корзины: новая корзина клиента
+// ничем от них не отличается
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	burst := float64(l.limit.Burst)
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate >= burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Registry хранит отдельный Limiter для каждого маршрута. Маршруты без
+// собственного лимита получают лимит по умолчанию.
+type Registry struct {
+	def    Limit
+	routes map[string]Limit
+
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+func NewRegistry(def Limit, routes map[string]Limit) *Registry {
+	return &Registry{
+		def:      def,
+		routes:   routes,
+		limiters: make(map[string]*Limiter),
+	}
+}
+
+// Allow проверяет запрос клиента key к маршруту route
+func (r *Registry) Allow(route, key string, now time.Time) Decision {
+	return r.limiter(route).Allow(key, now)
+}
+
+func (r *Registry) limiter(route string) *Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.limiters[route]
+	if !ok {
+		limit, ok := r.routes[route]
+		if !ok {
+			limit = r.def
+		}
+		l = New(limit)
+		r.limiters[route] = l
+	}
+	return l
+}
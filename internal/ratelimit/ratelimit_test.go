@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(Limit{Rate: 2, Burst: 3})
+
+	t.Run("burst is spent and refilled", func(t *testing.T) {
+		for i := 2; i >= 0; i-- {
+			d := l.Allow("a", now)
+			assert.True(t, d.Allowed)
+			assert.Equal(t, 3, d.Limit)
+			assert.Equal(t, i, d.Remaining)
+		}
+
+		d := l.Allow("a", now)
+		assert.False(t, d.Allowed)
+		assert.Equal(t, 500*time.Millisecond, d.RetryAfter)
+		assert.Equal(t, 1500*time.Millisecond, d.Reset)
+
+		// Другой клиент не затронут
+		assert.True(t, l.Allow("b", now).Allowed)
+
+		d = l.Allow("a", now.Add(500*time.Millisecond))
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 0, d.Remaining)
+	})
+
+	t.Run("bucket does not overflow", func(t *testing.T) {
+		d := l.Allow("a", now.Add(time.Hour))
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 2, d.Remaining)
+	})
+
+	t.Run("idle buckets are swept", func(t *testing.T) {
+		l.Allow("c", now.Add(time.Hour))
+		l.Allow("d", now.Add(2*time.Hour))
+		assert.NotContains(t, l.buckets, "c")
+		assert.Contains(t, l.buckets, "d")
+	})
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := New(Limit{})
+	for i := 0; i < 100; i++ {
+		d := l.Allow("a", time.Now())
+		assert.True(t, d.Allowed)
+		assert.Zero(t, d.Limit)
+	}
+}
+
+func TestRegistry_RouteLimits(t *testing.T) {
+	now := time.Now()
+	r := NewRegistry(Limit{Rate: 1, Burst: 5}, map[string]Limit{
+		"/pullRequest/create": {Rate: 1, Burst: 1},
+	})
+
+	assert.True(t, r.Allow("/pullRequest/create", "a", now).Allowed)
+	assert.False(t, r.Allow("/pullRequest/create", "a", now).Allowed)
+
+	// У остальных маршрутов собственные корзины с лимитом по умолчанию
+	d := r.Allow("/team/get", "a", now)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 5, d.Limit)
+}
@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+)
+
+// slaLockKey - ключ advisory-блокировки, под которой выполняется проход
+// планировщика. Одновременно проход выполняет только одна реплика.
+const slaLockKey int64 = 0x52455649455753 // "REVIEWS"
+
+const (
+	DefaultInterval  = time.Minute
+	DefaultBatchSize = 100
+)
+
+// Locker берет advisory-блокировку в Postgres
+type Locker interface {
+	TryAdvisoryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error)
+}
+
+// Escalator выполняет эскалацию просроченного ревью
+type Escalator interface {
+	EscalateReview(ctx context.Context, prID, reviewerUserID string) (*services.EscalationResult, error)
+}
+
+// Notifier доставляет события SLA ревью
+type Notifier interface {
+	Notify(ctx context.Context, event domain.ReviewEvent)
+}
+
+// LogNotifier пишет события SLA в лог
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, event domain.ReviewEvent) {
+	logger.FromContext(ctx).Info("review SLA event",
+		"kind", event.Kind,
+		"pull_request_id", event.PullRequestID,
+		"reviewer_id", event.ReviewerUserID,
+		"action", event.Action,
+		"target_id", event.TargetUserID,
+	)
+}
+
+// Config задает период опроса и SLA по умолчанию для команд, у которых
+// собственный SLA не задан
+type Config struct {
+	Interval        time.Duration
+	ReminderAfter   time.Duration
+	EscalationAfter time.Duration
+	BatchSize       int
+}
+
+// SLAScheduler периодически ищет назначения ревьюверов, превысившие SLA:
+// на первом пороге отправляет напоминание, на втором - эскалирует
+type SLAScheduler struct {
+	cfg       Config
+	locker    Locker
+	repo      storage.ReviewSLARepository
+	escalator Escalator
+	notifier  Notifier
+	now       func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewSLAScheduler(cfg Config, locker Locker, repo storage.ReviewSLARepository, escalator Escalator, notifier Notifier) *SLAScheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+
+	return &SLAScheduler{
+		cfg:       cfg,
+		locker:    locker,
+		repo:      repo,
+		escalator: escalator,
+		notifier:  notifier,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Run выполняет проходы с периодом cfg.Interval, пока не вызван Stop или не
+// отменен ctx. Отмена ctx прерывает текущий проход, Stop дает ему завершиться.
+func (s *SLAScheduler) Run(ctx context.Context) {
+	log := logger.FromContext(ctx).With("component", "review_sla")
+	ctx = logger.WithContext(ctx, log)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Error("review SLA pass failed", "error", err)
+			}
+		}
+	}
+}
+
+// Stop просит Run завершиться после текущего прохода. Повторные вызовы
+// ничего не делают.
+func (s *SLAScheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// RunOnce выполняет один проход, если удалось стать лидером
+func (s *SLAScheduler) RunOnce(ctx context.Context) error {
+	unlock, ok, err := s.locker.TryAdvisoryLock(ctx, slaLockKey)
+	if err != nil {
+		return fmt.Errorf("failed to acquire leader lock: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	defer unlock()
+
+	now := s.now()
+
+	if err := s.remind(ctx, now); err != nil {
+		return err
+	}
+
+	return s.escalate(ctx, now)
+}
+
+func (s *SLAScheduler) remind(ctx context.Context, now time.Time) error {
+	reviews, err := s.repo.GetStaleReviews(ctx, domain.ReviewEventReminder, s.cfg.ReminderAfter, now, s.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get reviews for reminder: %w", err)
+	}
+
+	for _, review := range reviews {
+		if err := s.record(reviewContext(ctx, review), review, domain.ReviewEventReminder, "", ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SLAScheduler) escalate(ctx context.Context, now time.Time) error {
+	reviews, err := s.repo.GetStaleReviews(ctx, domain.ReviewEventEscalation, s.cfg.EscalationAfter, now, s.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get reviews for escalation: %w", err)
+	}
+
+	for _, review := range reviews {
+		ctx := reviewContext(ctx, review)
+		result, err := s.escalator.EscalateReview(ctx, review.PullRequestID, review.ReviewerUserID)
+		switch {
+		case errors.Is(err, storage.ErrNoCandidate):
+			// Эскалировать некому: фиксируем событие, чтобы не повторять
+			// попытку на каждом проходе
+			result = &services.EscalationResult{Action: domain.EscalationNoCandidate}
+		case err != nil:
+			// PR мог быть смержен или ревьювер снят между выборкой и эскалацией
+			logger.FromContext(ctx).Warn("failed to escalate review",
+				"pull_request_id", review.PullRequestID, "reviewer_id", review.ReviewerUserID, "error", err)
+			continue
+		}
+
+		if err := s.record(ctx, review, domain.ReviewEventEscalation, result.Action, result.TargetUserID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reviewContext привязывает контекст к организации PR: выборка идет по всем
+// организациям, а эскалация и запись события - в пределах одной
+func reviewContext(ctx context.Context, review domain.StaleReview) context.Context {
+	ctx = tenant.WithOrg(ctx, review.OrgID)
+	return logger.WithContext(ctx, logger.FromContext(ctx).With("org_id", review.OrgID))
+}
+
+// record сохраняет событие и отправляет уведомление, если событие новое
+func (s *SLAScheduler) record(ctx context.Context, review domain.StaleReview, kind, action, targetUserID string) error {
+	event := domain.ReviewEvent{
+		PRID:           review.PRID,
+		PullRequestID:  review.PullRequestID,
+		ReviewerID:     review.ReviewerID,
+		ReviewerUserID: review.ReviewerUserID,
+		AssignedAt:     review.AssignedAt,
+		Kind:           kind,
+		Action:         action,
+		TargetUserID:   targetUserID,
+	}
+
+	created, err := s.repo.CreateEvent(ctx, &event)
+	if err != nil {
+		return fmt.Errorf("failed to record review event: %w", err)
+	}
+
+	if created {
+		s.notifier.Notify(ctx, event)
+	}
+
+	return nil
+}
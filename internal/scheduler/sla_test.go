@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLocker struct {
+	mock.Mock
+}
+
+func (m *MockLocker) TryAdvisoryLock(ctx context.Context, key int64) (func(), bool, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(func()), args.Bool(1), args.Error(2)
+}
+
+type MockReviewSLARepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewSLARepository) GetStaleReviews(ctx context.Context, kind string, defaultAfter time.Duration, now time.Time, limit int) ([]domain.StaleReview, error) {
+	args := m.Called(ctx, kind, defaultAfter, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StaleReview), args.Error(1)
+}
+
+func (m *MockReviewSLARepository) CreateEvent(ctx context.Context, event *domain.ReviewEvent) (bool, error) {
+	args := m.Called(ctx, event)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockEscalator struct {
+	mock.Mock
+}
+
+func (m *MockEscalator) EscalateReview(ctx context.Context, prID, reviewerUserID string) (*services.EscalationResult, error) {
+	args := m.Called(ctx, prID, reviewerUserID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.EscalationResult), args.Error(1)
+}
+
+type recordingNotifier struct {
+	events []domain.ReviewEvent
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event domain.ReviewEvent) {
+	n.events = append(n.events, event)
+}
+
+func newTestScheduler(locker Locker, repo storage.ReviewSLARepository, escalator Escalator, notifier Notifier, now time.Time) *SLAScheduler {
+	s := NewSLAScheduler(Config{
+		ReminderAfter:   24 * time.Hour,
+		EscalationAfter: 72 * time.Hour,
+		BatchSize:       10,
+	}, locker, repo, escalator, notifier)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestSLAScheduler_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	stale := domain.StaleReview{PRID: 1, PullRequestID: "pr-1", ReviewerID: 2, ReviewerUserID: "u2", TeamID: 1, AssignedAt: now.Add(-100 * time.Hour)}
+
+	t.Run("another replica holds the lock", func(t *testing.T) {
+		locker := new(MockLocker)
+		repo := new(MockReviewSLARepository)
+		s := newTestScheduler(locker, repo, new(MockEscalator), &recordingNotifier{}, now)
+
+		locker.On("TryAdvisoryLock", ctx, slaLockKey).Return(nil, false, nil).Once()
+
+		assert.NoError(t, s.RunOnce(ctx))
+		repo.AssertNotCalled(t, "GetStaleReviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reminders and escalations are recorded once", func(t *testing.T) {
+		locker := new(MockLocker)
+		repo := new(MockReviewSLARepository)
+		escalator := new(MockEscalator)
+		notifier := &recordingNotifier{}
+		s := newTestScheduler(locker, repo, escalator, notifier, now)
+
+		unlocked := false
+		locker.On("TryAdvisoryLock", ctx, slaLockKey).Return(func() { unlocked = true }, true, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventReminder, 24*time.Hour, now, 10).
+			Return([]domain.StaleReview{stale, {PRID: 3, PullRequestID: "pr-3", ReviewerID: 4, ReviewerUserID: "u4"}}, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventEscalation, 72*time.Hour, now, 10).
+			Return([]domain.StaleReview{stale}, nil).Once()
+		repo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.ReviewEvent) bool {
+			return e.Kind == domain.ReviewEventReminder && e.PullRequestID == "pr-1" && e.AssignedAt.Equal(stale.AssignedAt)
+		})).Return(true, nil).Once()
+		// Напоминание уже записано другой репликой
+		repo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.ReviewEvent) bool {
+			return e.Kind == domain.ReviewEventReminder && e.PullRequestID == "pr-3"
+		})).Return(false, nil).Once()
+		escalator.On("EscalateReview", mock.Anything, "pr-1", "u2").
+			Return(&services.EscalationResult{Action: domain.EscalationLeadAdded, TargetUserID: "u9"}, nil).Once()
+		repo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.ReviewEvent) bool {
+			return e.Kind == domain.ReviewEventEscalation && e.Action == domain.EscalationLeadAdded && e.TargetUserID == "u9"
+		})).Return(true, nil).Once()
+
+		assert.NoError(t, s.RunOnce(ctx))
+		assert.True(t, unlocked)
+		assert.Len(t, notifier.events, 2)
+		assert.Equal(t, domain.ReviewEventReminder, notifier.events[0].Kind)
+		assert.Equal(t, domain.ReviewEventEscalation, notifier.events[1].Kind)
+		repo.AssertExpectations(t)
+		escalator.AssertExpectations(t)
+	})
+
+	t.Run("escalation without candidates is recorded", func(t *testing.T) {
+		locker := new(MockLocker)
+		repo := new(MockReviewSLARepository)
+		escalator := new(MockEscalator)
+		notifier := &recordingNotifier{}
+		s := newTestScheduler(locker, repo, escalator, notifier, now)
+
+		locker.On("TryAdvisoryLock", ctx, slaLockKey).Return(func() {}, true, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventReminder, 24*time.Hour, now, 10).Return([]domain.StaleReview{}, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventEscalation, 72*time.Hour, now, 10).Return([]domain.StaleReview{stale}, nil).Once()
+		escalator.On("EscalateReview", mock.Anything, "pr-1", "u2").Return(nil, storage.ErrNoCandidate).Once()
+		repo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.ReviewEvent) bool {
+			return e.Action == domain.EscalationNoCandidate
+		})).Return(true, nil).Once()
+
+		assert.NoError(t, s.RunOnce(ctx))
+		assert.Len(t, notifier.events, 1)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed escalation is retried on the next pass", func(t *testing.T) {
+		locker := new(MockLocker)
+		repo := new(MockReviewSLARepository)
+		escalator := new(MockEscalator)
+		s := newTestScheduler(locker, repo, escalator, &recordingNotifier{}, now)
+
+		locker.On("TryAdvisoryLock", ctx, slaLockKey).Return(func() {}, true, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventReminder, 24*time.Hour, now, 10).Return([]domain.StaleReview{}, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventEscalation, 72*time.Hour, now, 10).Return([]domain.StaleReview{stale}, nil).Once()
+		escalator.On("EscalateReview", mock.Anything, "pr-1", "u2").Return(nil, storage.ErrPRMerged).Once()
+
+		assert.NoError(t, s.RunOnce(ctx))
+		repo.AssertNotCalled(t, "CreateEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reviews are processed in their organization", func(t *testing.T) {
+		locker := new(MockLocker)
+		repo := new(MockReviewSLARepository)
+		escalator := new(MockEscalator)
+		s := newTestScheduler(locker, repo, escalator, &recordingNotifier{}, now)
+
+		inOrg := func(orgID int64) interface{} {
+			return mock.MatchedBy(func(ctx context.Context) bool { return tenant.OrgID(ctx) == orgID })
+		}
+		other := domain.StaleReview{OrgID: 2, PRID: 5, PullRequestID: "pr-1", ReviewerID: 6, ReviewerUserID: "u2"}
+
+		locker.On("TryAdvisoryLock", ctx, slaLockKey).Return(func() {}, true, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventReminder, 24*time.Hour, now, 10).Return([]domain.StaleReview{}, nil).Once()
+		repo.On("GetStaleReviews", ctx, domain.ReviewEventEscalation, 72*time.Hour, now, 10).Return([]domain.StaleReview{other}, nil).Once()
+		escalator.On("EscalateReview", inOrg(2), "pr-1", "u2").Return(nil, storage.ErrNoCandidate).Once()
+		repo.On("CreateEvent", inOrg(2), mock.Anything).Return(true, nil).Once()
+
+		assert.NoError(t, s.RunOnce(ctx))
+		repo.AssertExpectations(t)
+		escalator.AssertExpectations(t)
+	})
+
+	t.Run("lock error", func(t *testing.T) {
+		locker := new(MockLocker)
+		s := newTestScheduler(locker, new(MockReviewSLARepository), new(MockEscalator), &recordingNotifier{}, now)
+
+		locker.On("TryAdvisoryLock", ctx, slaLockKey).Return(nil, false, errors.New("connection refused")).Once()
+
+		assert.Error(t, s.RunOnce(ctx))
+	})
+}
+
+func TestSLAScheduler_Stop(t *testing.T) {
+	locker := new(MockLocker)
+	locker.On("TryAdvisoryLock", mock.Anything, slaLockKey).Return(nil, false, nil)
+
+	s := NewSLAScheduler(Config{Interval: time.Millisecond}, locker, nil, nil, nil)
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(context.Background())
+		close(done)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	s.Stop()
+	s.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Stop")
+	}
+}
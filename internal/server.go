@@ -2,90 +2,321 @@ package internal
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"strings"
+	"sync"
 	"time"
 
+	"reviewer-appointment-service/internal/config"
 	"reviewer-appointment-service/internal/handlers"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/metrics"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/ratelimit"
+	"reviewer-appointment-service/internal/scheduler"
 	"reviewer-appointment-service/internal/services"
 	"reviewer-appointment-service/internal/storage/postgresql"
+	"reviewer-appointment-service/internal/version"
+	"reviewer-appointment-service/migrations"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
-	httpServer *http.Server
-	handler    *handlers.Handler
+	httpServer    *http.Server
+	handler       *handlers.Handler
+	healthService *services.HealthService
+	scheduler     *scheduler.SLAScheduler
+
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+	workers         sync.WaitGroup
 }
 
-func NewServer(port string, storage *postgresql.Storage) *Server {
+func NewServer(cfg *config.Config, storage *postgresql.Storage) *Server {
 	userStorage := postgresql.NewUserStorage(storage)
 	teamStorage := postgresql.NewTeamRepo(storage)
 	prStorage := postgresql.NewPRRepo(storage)
 
+	statsRepo := postgresql.NewStatsRepo(storage)
+
+	m := metrics.New()
+	m.RegisterPool(storage.DB)
+	m.RegisterPRCounts(services.NewOpenPRCounter(statsRepo))
+
+	prOpts := append(prServiceOptions(cfg.Assignment), services.WithAssignmentObserver(m), services.WithMaxBatchPRs(cfg.Limits.MaxBatchPRs), services.WithTransactor(storage))
+	prService := services.NewPRService(prStorage, userStorage, teamStorage, prOpts...)
+
 	userService := services.NewUserService(userStorage)
-	teamService := services.NewTeamService(teamStorage, userStorage)
-	prService := services.NewPRService(prStorage, userStorage, teamStorage)
+	teamService := services.NewTeamService(teamStorage, userStorage,
+		services.WithMaxTeamMembers(cfg.Limits.MaxTeamMembers), services.WithSync(storage, prService))
 
-	statsRepo := postgresql.NewStatsRepo(storage)
+	schemaVersion, err := migrations.LatestVersion()
+	if err != nil {
+		// Без ожидаемой версии схема не проверяется, и /health/ready отвечает 503
+		slog.Error("failed to read embedded migrations, the service will not report ready", "error", err)
+		schemaVersion = 0
+	}
+	healthService := services.NewHealthService(storage, schemaVersion, version.Version, cfg.Server.ReadinessTimeout)
 
-	handler := handlers.NewHandler(userService, teamService, prService, statsRepo)
+	tokenService := services.NewTokenService(postgresql.NewTokenRepo(storage), userStorage)
+	policy := services.NewPolicy(userStorage, teamStorage, prStorage)
+	orgService := services.NewOrgService(postgresql.NewOrgRepo(storage))
 
-	router := setupRouter(handler)
+	handler := handlers.NewHandler(userService, teamService, prService, statsRepo, healthService, tokenService, policy, orgService)
 
-	return &Server{
+	if !cfg.Auth.Enabled {
+		slog.Warn("API authentication is disabled; every endpoint is open. Issue tokens with `token create` and set auth.enabled to enforce them")
+	}
+	idempotencyService := services.NewIdempotencyService(postgresql.NewIdempotencyRepo(storage), cfg.Idempotency.TTL)
+	router := setupRouter(handler, m, cfg.Tracing.ServiceName, cfg.Auth.Enabled, cfg.Limits, idempotencyService)
+
+	server := &Server{
 		httpServer: &http.Server{
-			Addr:    ":" + port,
+			Addr:    fmt.Sprintf(":%d", cfg.PortServer),
 			Handler: router,
 		},
-		handler: handler,
+		handler:         handler,
+		healthService:   healthService,
+		shutdownDelay:   cfg.Server.ShutdownDelay,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+	}
+
+	if cfg.ReviewSLA.Enabled {
+		server.scheduler = scheduler.NewSLAScheduler(scheduler.Config{
+			Interval:        cfg.ReviewSLA.Interval,
+			ReminderAfter:   cfg.ReviewSLA.ReminderAfter,
+			EscalationAfter: cfg.ReviewSLA.EscalationAfter,
+			BatchSize:       cfg.ReviewSLA.BatchSize,
+		}, storage, postgresql.NewReviewSLARepo(storage), prService, scheduler.LogNotifier{})
+	}
+
+	return server
+}
+
+func prServiceOptions(cfg config.Assignment) []services.PRServiceOption {
+	opts := []services.PRServiceOption{services.WithRotationLookback(cfg.RotationLookback)}
+
+	switch cfg.Strategy {
+	case "", services.StrategyRandom:
+	case services.StrategyRotation:
+		opts = append(opts, services.WithRotation(cfg.RotationLookback))
+	default:
+		slog.Warn("unknown assignment strategy, falling back to default",
+			"strategy", cfg.Strategy, "fallback", services.StrategyRandom)
+	}
+
+	if cfg.Seed != 0 {
+		opts = append(opts, services.WithRand(rand.New(rand.NewSource(cfg.Seed))))
+	}
+	if cfg.Deterministic {
+		opts = append(opts, services.WithDeterministic())
 	}
+
+	return opts
 }
 
-func setupRouter(h *handlers.Handler) *gin.Engine {
+func setupRouter(h *handlers.Handler, m *metrics.Metrics, serviceName string, authEnabled bool, limits config.Limits, idempotency *services.IdempotencyService) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(otelgin.Middleware(serviceName))
+	r.Use(logger.Middleware(slog.Default()))
+	r.Use(m.Middleware())
 
+	// Проверки состояния, метрики и документация доступны без токена
 	r.GET("/health", h.HealthCheck)
+	r.GET("/health/live", h.Liveness)
+	r.GET("/health/ready", h.Readiness)
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	r.GET("/openapi.json", handlers.OpenAPISpec)
+	r.GET("/docs", handlers.SwaggerUI)
+
+	scope := func(scope string) gin.HandlerFunc {
+		if !authEnabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return h.RequireScope(scope)
+	}
+
+	rateLimit := func(c *gin.Context) { c.Next() }
+	ipRateLimit := func(c *gin.Context) { c.Next() }
+	if limits.RateLimit.Enabled {
+		rateLimit = handlers.RateLimit(newRateLimits(limits.RateLimit))
+		ipRateLimit = handlers.RateLimitIP(ratelimit.New(ratelimit.Limit{
+			Rate:  limits.RateLimit.IPRate,
+			Burst: limits.RateLimit.IPBurst,
+		}))
+	}
+
+	idempotent := func(c *gin.Context) { c.Next() }
+	if idempotency != nil {
+		idempotent = handlers.Idempotency(idempotency)
+	}
+
+	// Лимит на IP проверяется до аутентификации, чтобы перебор токенов не
+	// доходил до БД. Лимиты маршрутов считаются после нее, чтобы ключом был
+	// токен, а организация определяется по токену или X-Org. Ключи
+	// идемпотентности хранятся в пределах клиента и организации.
+	api := func(scopeName string) []gin.HandlerFunc {
+		return []gin.HandlerFunc{ipRateLimit, scope(scopeName), rateLimit, handlers.LimitBody(limits.MaxBodyBytes), h.ResolveOrg(), idempotent, handlers.IfMatch()}
+	}
+
+	read := r.Group("", api(domain.ScopeRead)...)
+	teamWrite := r.Group("", api(domain.ScopeTeamWrite)...)
+	prWrite := r.Group("", api(domain.ScopePRWrite)...)
+	admin := r.Group("/admin", api(domain.ScopeAdmin)...)
 
-	r.POST("/team/add", h.CreateTeam)
-	r.GET("/team/get", h.GetTeam)
+	teamWrite.POST("/team/add", h.CreateTeam)
+	read.GET("/team/get", h.GetTeam)
+	teamWrite.POST("/team/setBuddies", h.SetBuddyTeams)
 
-	r.POST("/users/setIsActive", h.SetIsActive)
-	r.GET("/users/getReview", h.GetUserReviewPRs)
+	teamWrite.POST("/users/setIsActive", h.SetIsActive)
+	read.GET("/users/getReview", h.GetUserReviewPRs)
 
-	r.POST("/pullRequest/create", h.CreatePR)
-	r.POST("/pullRequest/merge", h.MergePR)
-	r.POST("/pullRequest/reassign", h.ReassignReviewer)
+	read.GET("/pullRequest/get", h.GetPR)
+	read.GET("/pullRequest/list", h.ListPRs)
+	prWrite.POST("/pullRequest/create", h.CreatePR)
+	prWrite.POST("/pullRequest/batchCreate", h.BatchCreatePRs)
+	prWrite.POST("/pullRequest/merge", h.MergePR)
+	prWrite.POST("/pullRequest/reassign", h.ReassignReviewer)
+	prWrite.POST("/pullRequest/addReviewer", h.AddReviewer)
+	prWrite.POST("/pullRequest/removeReviewer", h.RemoveReviewer)
+	prWrite.POST("/pullRequest/review", h.MarkReviewed)
 
-	r.GET("/stats", h.GetStats)
+	read.GET("/stats", h.GetStats)
+	read.GET("/stats/pairs", h.GetReviewPairs)
+	read.GET("/stats/latency", h.GetLatencyStats)
+	read.GET("/stats/fairness", h.GetFairnessStats)
+
+	admin.POST("/tokens", h.CreateToken)
+	admin.GET("/tokens", h.ListTokens)
+	admin.POST("/tokens/revoke", h.RevokeToken)
+	admin.POST("/sync", h.SyncTeams)
+
+	// API v2: ресурсы вместо RPC-методов и единый конверт ответа. v1 выше
+	// работает без изменений
+	v2read := r.Group("/api/v2", api(domain.ScopeRead)...)
+	v2teamWrite := r.Group("/api/v2", api(domain.ScopeTeamWrite)...)
+	v2prWrite := r.Group("/api/v2", api(domain.ScopePRWrite)...)
+
+	v2teamWrite.POST("/teams", h.V2CreateTeam)
+	v2read.GET("/teams/:name", h.V2GetTeam)
+	v2teamWrite.PATCH("/teams/:name", h.V2UpdateTeam)
+	v2read.GET("/teams/:name/members", h.V2ListTeamMembers)
+
+	v2read.GET("/users/:id", h.V2GetUser)
+	v2teamWrite.PATCH("/users/:id", h.V2UpdateUser)
+	v2read.GET("/users/:id/reviews", h.V2ListUserReviews)
+
+	v2prWrite.POST("/pull-requests", h.V2CreatePR)
+	v2read.GET("/pull-requests/:id", h.V2GetPR)
+	v2prWrite.POST("/pull-requests/:id", h.V2PRMethod)
+	v2read.GET("/pull-requests/:id/reviewers", h.V2ListReviewers)
+	v2prWrite.POST("/pull-requests/:id/reviewers", h.V2AddReviewer)
+	v2prWrite.DELETE("/pull-requests/:id/reviewers/:user_id", h.V2RemoveReviewer)
+	v2prWrite.POST("/pull-requests/:id/reviewers/:user_id", h.V2ReviewerMethod)
+
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") {
+			handlers.V2NoRoute(c)
+		}
+	})
 
 	return r
 }
 
-func (s *Server) Run() error {
+func newRateLimits(cfg config.RateLimit) *ratelimit.Registry {
+	routes := make(map[string]ratelimit.Limit, len(cfg.Routes))
+	for route, limit := range cfg.Routes {
+		routes[route] = ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+	return ratelimit.NewRegistry(ratelimit.Limit{Rate: cfg.Rate, Burst: cfg.Burst}, routes)
+}
+
+// Run запускает HTTP-сервер и фоновые задачи и блокируется до отмены ctx
+// (сигнала остановки) или ошибки запуска. После этого выполняет
+// корректную остановку: readiness начинает отвечать 503, сервер перестает
+// принимать соединения и дожидается текущих запросов, фоновые задачи
+// завершают начатый проход - все в пределах shutdownTimeout.
+func (s *Server) Run(ctx context.Context) error {
+	workersCtx, stopWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWorkers()
+
+	if s.scheduler != nil {
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.scheduler.Run(workersCtx)
+		}()
+	}
+
+	serveErr := make(chan error, 1)
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
 		}
 	}()
 
-	log.Printf("Server is running on %s", s.httpServer.Addr)
+	slog.Info("server is running", "addr", s.httpServer.Addr)
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down server")
+	case runErr = <-serveErr:
+		slog.Error("failed to start server", "error", runErr)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
+	if err := s.shutdown(shutdownCtx, stopWorkers); err != nil {
+		return errors.Join(runErr, err)
+	}
+
+	slog.Info("server stopped")
+	return runErr
+}
+
+func (s *Server) shutdown(ctx context.Context, stopWorkers context.CancelFunc) error {
+	s.healthService.SetShuttingDown()
+
+	if s.shutdownDelay > 0 {
+		select {
+		case <-time.After(s.shutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	var errs []error
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return err
+		errs = append(errs, fmt.Errorf("failed to drain HTTP requests: %w", err))
+	}
+
+	if s.scheduler != nil {
+		s.scheduler.Stop()
+	}
+
+	workersDone := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		// Прерываем незавершенный проход, чтобы освободить соединения пула
+		stopWorkers()
+		<-workersDone
+		errs = append(errs, fmt.Errorf("background workers did not stop in time: %w", ctx.Err()))
 	}
 
-	log.Println("Server stopped")
-	return nil
+	return errors.Join(errs...)
 }
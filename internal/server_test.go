@@ -0,0 +1,814 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"reviewer-appointment-service/internal/config"
+	"reviewer-appointment-service/internal/handlers"
+	"reviewer-appointment-service/internal/metrics"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/services"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHealthRepo struct{}
+
+func (fakeHealthRepo) Ping(context.Context) error                   { return nil }
+func (fakeHealthRepo) SchemaVersion(context.Context) (int64, error) { return 1, nil }
+func (fakeHealthRepo) PoolStats() domain.PoolStats                  { return domain.PoolStats{} }
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestServer_RunDrainsInFlightRequests(t *testing.T) {
+	addr := freeAddr(t)
+	started := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	healthService := services.NewHealthService(fakeHealthRepo{}, 1, "test", 0)
+	server := &Server{
+		httpServer:      &http.Server{Addr: addr, Handler: mux},
+		healthService:   healthService,
+		shutdownTimeout: 5 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- server.Run(ctx) }()
+
+	// Ждем, пока сервер начнет принимать соединения
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://%s/slow", addr))
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, http.StatusOK, <-status)
+	require.NoError(t, <-runErr)
+	assert.Equal(t, domain.HealthStatusUnavailable, healthService.Ready(context.Background()).Status)
+
+	_, err := net.Dial("tcp", addr)
+	assert.Error(t, err)
+}
+
+// fakeTokenRepo хранит токены в памяти по хешу
+type fakeTokenRepo struct {
+	tokens map[string]*domain.APIToken
+}
+
+func (r *fakeTokenRepo) Create(ctx context.Context, token *domain.APIToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	token.OrgID = tenant.OrgID(ctx)
+	r.tokens[token.Hash] = token
+	return nil
+}
+
+func (r *fakeTokenRepo) GetByHash(_ context.Context, hash string) (*domain.APIToken, error) {
+	if token, ok := r.tokens[hash]; ok {
+		return token, nil
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (r *fakeTokenRepo) List(ctx context.Context) ([]domain.APIToken, error) {
+	tokens := []domain.APIToken{}
+	for _, token := range r.tokens {
+		if token.OrgID == tenant.OrgID(ctx) {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeTokenRepo) Revoke(_ context.Context, id int64, at time.Time) error {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			token.RevokedAt = &at
+			return nil
+		}
+	}
+	return storage.ErrNotFound
+}
+
+func (r *fakeTokenRepo) TouchLastUsed(context.Context, int64, time.Time) error { return nil }
+
+// fakeOrgRepo - организации default (1) и acme (2)
+type fakeOrgRepo struct{}
+
+var fakeOrgs = []domain.Organization{
+	{ID: tenant.DefaultOrgID, Slug: tenant.DefaultSlug, Name: "Default"},
+	{ID: 2, Slug: "acme", Name: "Acme"},
+}
+
+func (fakeOrgRepo) Create(context.Context, *domain.Organization) error { return nil }
+
+func (fakeOrgRepo) GetBySlug(_ context.Context, slug string) (*domain.Organization, error) {
+	for _, org := range fakeOrgs {
+		if org.Slug == slug {
+			return &org, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (fakeOrgRepo) List(context.Context) ([]domain.Organization, error) { return fakeOrgs, nil }
+
+func TestRouter_RequiresScopedToken(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	tokenService := services.NewTokenService(&fakeTokenRepo{tokens: map[string]*domain.APIToken{}}, nil)
+
+	admin, err := tokenService.Create(ctx, "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+	reader, err := tokenService.Create(ctx, "reader", "", []string{domain.ScopeRead}, nil)
+	require.NoError(t, err)
+	revoked, err := tokenService.Create(ctx, "revoked", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+	require.NoError(t, tokenService.Revoke(ctx, revoked.APIToken.ID))
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+	router := setupRouter(h, metrics.New(), "test", true, config.Limits{}, nil)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		auth       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"metrics are open", http.MethodGet, "/metrics", "", http.StatusOK, ""},
+		{"missing token", http.MethodPost, "/team/add", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"wrong scheme", http.MethodPost, "/team/add", "Basic " + admin.Token, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"unknown token", http.MethodGet, "/stats", "Bearer ras_unknown", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"revoked token", http.MethodGet, "/admin/tokens", "Bearer " + revoked.Token, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"insufficient scope", http.MethodPost, "/pullRequest/create", "Bearer " + reader.Token, http.StatusForbidden, "FORBIDDEN"},
+		{"read token on admin route", http.MethodGet, "/admin/tokens", "Bearer " + reader.Token, http.StatusForbidden, "FORBIDDEN"},
+		{"admin token", http.MethodGet, "/admin/tokens", "Bearer " + admin.Token, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantCode != "" {
+				assert.Contains(t, rec.Body.String(), `"code":"`+tt.wantCode+`"`)
+				assert.True(t, strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer"))
+			}
+		})
+	}
+}
+
+func TestRouter_ResolvesOrganization(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	acmeCtx := tenant.WithOrg(ctx, 2)
+	tokenService := services.NewTokenService(&fakeTokenRepo{tokens: map[string]*domain.APIToken{}}, nil)
+
+	_, err := tokenService.Create(ctx, "default-admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+	acme, err := tokenService.Create(acmeCtx, "acme-admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+
+	tests := []struct {
+		name        string
+		authEnabled bool
+		auth        string
+		org         string
+		wantStatus  int
+		wantTokens  []string
+	}{
+		{"token org", true, "Bearer " + acme.Token, "", http.StatusOK, []string{"acme-admin"}},
+		{"header matches token org", true, "Bearer " + acme.Token, "acme", http.StatusOK, []string{"acme-admin"}},
+		{"header names another org", true, "Bearer " + acme.Token, tenant.DefaultSlug, http.StatusForbidden, nil},
+		{"unknown org", true, "Bearer " + acme.Token, "globex", http.StatusNotFound, nil},
+		{"auth disabled, default org", false, "", "", http.StatusOK, []string{"default-admin"}},
+		{"auth disabled, header org", false, "", "acme", http.StatusOK, []string{"acme-admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(h, metrics.New(), "test", tt.authEnabled, config.Limits{}, nil)
+			req := httptest.NewRequest(http.MethodGet, "/admin/tokens", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.org != "" {
+				req.Header.Set(tenant.Header, tt.org)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			for _, name := range tt.wantTokens {
+				assert.Contains(t, rec.Body.String(), `"name":"`+name+`"`)
+			}
+			if tt.wantTokens != nil {
+				assert.Equal(t, len(tt.wantTokens), strings.Count(rec.Body.String(), `"name":`))
+			}
+		})
+	}
+}
+
+func TestRouter_Limits(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	tokenService := services.NewTokenService(&fakeTokenRepo{tokens: map[string]*domain.APIToken{}}, nil)
+	first, err := tokenService.Create(ctx, "first", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+	second, err := tokenService.Create(ctx, "second", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+	router := setupRouter(h, metrics.New(), "test", true, config.Limits{
+		RateLimit: config.RateLimit{
+			Enabled: true,
+			Rate:    100,
+			Burst:   100,
+			Routes:  map[string]config.RouteRate{"/admin/tokens": {Rate: 0.001, Burst: 2}},
+		},
+		MaxBodyBytes: 64,
+	}, nil)
+
+	do := func(method, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/tokens", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("per-token route limit", func(t *testing.T) {
+		rec := do(http.MethodGet, "", first.Token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "", first.Token).Code)
+
+		rec = do(http.MethodGet, "", first.Token)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"RATE_LIMITED"`)
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+		// У другого токена своя корзина
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "", second.Token).Code)
+	})
+
+	t.Run("body size", func(t *testing.T) {
+		body := `{"id": 1, "padding": "` + strings.Repeat("x", 64) + `"}`
+		// С Content-Length и без него (chunked)
+		for _, contentLength := range []int64{int64(len(body)), -1} {
+			// Маршрут /admin/tokens/revoke не исчерпан: у него своя корзина
+			req := httptest.NewRequest(http.MethodPost, "/admin/tokens/revoke", strings.NewReader(body))
+			req.ContentLength = contentLength
+			req.Header.Set("Authorization", "Bearer "+second.Token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"code":"PAYLOAD_TOO_LARGE"`)
+		}
+	})
+}
+
+func TestRouter_IPRateLimit(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	tokenService := services.NewTokenService(&fakeTokenRepo{tokens: map[string]*domain.APIToken{}}, nil)
+	valid, err := tokenService.Create(ctx, "valid", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+	router := setupRouter(h, metrics.New(), "test", true, config.Limits{
+		RateLimit: config.RateLimit{
+			Enabled: true,
+			Rate:    100,
+			Burst:   100,
+			IPRate:  0.001,
+			IPBurst: 2,
+		},
+	}, nil)
+
+	do := func(token, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/tokens", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Запросы с неверным токеном расходуют лимит IP до аутентификации
+	assert.Equal(t, http.StatusUnauthorized, do("wrong", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("wrong", "192.0.2.1:1234").Code)
+
+	rec := do(valid.Token, "192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"RATE_LIMITED"`)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// У другого адреса своя корзина
+	assert.Equal(t, http.StatusOK, do(valid.Token, "192.0.2.2:1234").Code)
+}
+
+// fakeIdempotencyRepo хранит ключи идемпотентности в памяти
+type fakeIdempotencyRepo struct {
+	records map[string]*domain.IdempotencyRecord
+}
+
+func (r *fakeIdempotencyRepo) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	id := fmt.Sprintf("%d/%s/%s", tenant.OrgID(ctx), record.Client, record.Key)
+	if existing, ok := r.records[id]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return false, nil
+	}
+	stored := *record
+	r.records[id] = &stored
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepo) Get(ctx context.Context, client, key string) (*domain.IdempotencyRecord, error) {
+	if record, ok := r.records[fmt.Sprintf("%d/%s/%s", tenant.OrgID(ctx), client, key)]; ok {
+		return record, nil
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (r *fakeIdempotencyRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stored := *record
+	r.records[fmt.Sprintf("%d/%s/%s", tenant.OrgID(ctx), record.Client, record.Key)] = &stored
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Extend(ctx context.Context, client, key, _ string, expiresAt time.Time) error {
+	if record, ok := r.records[fmt.Sprintf("%d/%s/%s", tenant.OrgID(ctx), client, key)]; ok {
+		record.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Delete(ctx context.Context, client, key string) error {
+	delete(r.records, fmt.Sprintf("%d/%s/%s", tenant.OrgID(ctx), client, key))
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) DeleteExpired(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func TestRouter_IdempotencyKey(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	tokenRepo := &fakeTokenRepo{tokens: map[string]*domain.APIToken{}}
+	tokenService := services.NewTokenService(tokenRepo, nil)
+	admin, err := tokenService.Create(ctx, "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+	idempotency := services.NewIdempotencyService(&fakeIdempotencyRepo{records: map[string]*domain.IdempotencyRecord{}}, time.Hour)
+	router := setupRouter(h, metrics.New(), "test", true, config.Limits{}, idempotency)
+
+	do := func(body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/tokens", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		req.Header.Set(handlers.IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"name": "ci", "scopes": ["read"]}`
+	first := do(body, "key-1")
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(handlers.IdempotentReplayedHeader))
+
+	// Повтор возвращает тот же токен и не выпускает новый
+	retry := do(body, "key-1")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Len(t, tokenRepo.tokens, 2)
+
+	mismatch := do(`{"name": "other", "scopes": ["read"]}`, "key-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+	assert.Contains(t, mismatch.Body.String(), `"code":"IDEMPOTENCY_KEY_MISMATCH"`)
+
+	// Новый ключ - новый запрос
+	assert.Equal(t, http.StatusCreated, do(body, "key-2").Code)
+	assert.Len(t, tokenRepo.tokens, 3)
+
+	// Клиент отключился, не дождавшись ответа: ответ все равно сохраняется,
+	// и повтор не выпускает токен второй раз
+	ctx, cancel := context.WithCancel(ctx)
+	req := httptest.NewRequest(http.MethodPost, "/admin/tokens", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+admin.Token)
+	req.Header.Set(handlers.IdempotencyKeyHeader, "key-3")
+	cancel()
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Len(t, tokenRepo.tokens, 4)
+	assert.Equal(t, "true", do(body, "key-3").Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Len(t, tokenRepo.tokens, 4)
+
+	long := do(body, strings.Repeat("k", services.MaxIdempotencyKeyLength+1))
+	assert.Equal(t, http.StatusBadRequest, long.Code)
+	assert.Contains(t, long.Body.String(), `"code":"INVALID_IDEMPOTENCY_KEY"`)
+}
+
+func TestRouter_IfMatch(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	tokenService := services.NewTokenService(&fakeTokenRepo{tokens: map[string]*domain.APIToken{}}, nil)
+	admin, err := tokenService.Create(ctx, "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+	router := setupRouter(h, metrics.New(), "test", true, config.Limits{}, nil)
+
+	tests := []struct {
+		ifMatch string
+		want    int
+	}{
+		// Корректный заголовок доходит до обработчика: токена 99 нет
+		{ifMatch: `"1"`, want: http.StatusNotFound},
+		{ifMatch: `W/"1"`, want: http.StatusNotFound},
+		{ifMatch: `*`, want: http.StatusNotFound},
+		{ifMatch: `1`, want: http.StatusBadRequest},
+		{ifMatch: `"abc"`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ifMatch, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/tokens/revoke", strings.NewReader(`{"id": 99}`))
+			req.Header.Set("Authorization", "Bearer "+admin.Token)
+			req.Header.Set("If-Match", tt.ifMatch)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestRouter_V2(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	tokenService := services.NewTokenService(&fakeTokenRepo{tokens: map[string]*domain.APIToken{}}, nil)
+	admin, err := tokenService.Create(ctx, "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+	reader, err := tokenService.Create(ctx, "reader", "", []string{domain.ScopeRead}, nil)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(nil, nil, nil, nil, nil, tokenService, nil, services.NewOrgService(fakeOrgRepo{}))
+	router := setupRouter(h, metrics.New(), "test", true, config.Limits{}, nil)
+
+	// Запросы не доходят до сервисов: ошибки маршрутизации, доступа и тела
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantCode   string
+	}{
+		{"unknown resource", http.MethodGet, "/api/v2/widgets", "", admin.Token, http.StatusNotFound, "NOT_FOUND"},
+		{"unknown PR method", http.MethodPost, "/api/v2/pull-requests/pr-1:close", "", admin.Token, http.StatusNotFound, "NOT_FOUND"},
+		{"PR without method", http.MethodPost, "/api/v2/pull-requests/pr-1", "", admin.Token, http.StatusNotFound, "NOT_FOUND"},
+		{"unknown reviewer method", http.MethodPost, "/api/v2/pull-requests/pr-1/reviewers/u2:approve", "", admin.Token, http.StatusNotFound, "NOT_FOUND"},
+		{"missing is_active", http.MethodPatch, "/api/v2/users/u1", `{}`, admin.Token, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"missing buddy_teams", http.MethodPatch, "/api/v2/teams/backend", `{}`, admin.Token, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"read token on write route", http.MethodPost, "/api/v2/pull-requests/pr-1:merge", "", reader.Token, http.StatusForbidden, "FORBIDDEN"},
+		{"missing token", http.MethodGet, "/api/v2/teams/backend", "", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			var resp handlers.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tt.wantCode, resp.Error.Code)
+			assert.Nil(t, resp.Data)
+		})
+	}
+
+	t.Run("v1 unknown route is unchanged", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/widgets", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "404 page not found", rec.Body.String())
+	})
+}
+
+func TestRouter_PRListPages(t *testing.T) {
+	router, tokenService := newMemoryRouter(t)
+	admin, err := tokenService.Create(tenant.WithOrg(context.Background(), tenant.DefaultOrgID), "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/team/add", `{"name":"backend","users":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	for i := 1; i <= 5; i++ {
+		rec = call(http.MethodPost, "/pullRequest/create", fmt.Sprintf(`{"pull_request_id":"pr-%d","pull_request_name":"PR %d","author_id":"u1"}`, i, i))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec = call(http.MethodPost, "/pullRequest/merge", `{"pull_request_id":"pr-2"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	walk := func(query string) []string {
+		var ids []string
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			rec := call(http.MethodGet, "/pullRequest/list?limit=2&"+query+"&cursor="+cursor, "")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var page handlers.PRPageResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+			require.LessOrEqual(t, len(page.PullRequests), 2)
+			for _, pr := range page.PullRequests {
+				ids = append(ids, pr.PullRequestID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			cursor = page.NextCursor
+		}
+		t.Fatal("pagination did not finish")
+		return nil
+	}
+
+	assert.Equal(t, []string{"pr-5", "pr-4", "pr-3", "pr-2", "pr-1"}, walk(""))
+	assert.Equal(t, []string{"pr-1", "pr-2", "pr-3", "pr-4", "pr-5"}, walk("sort=created_at"))
+	assert.Equal(t, []string{"pr-5", "pr-4", "pr-3", "pr-1"}, walk("status=OPEN"))
+
+	t.Run("reviewer pages in v2 carry the cursor in meta", func(t *testing.T) {
+		rec := call(http.MethodGet, "/api/v2/users/u2/reviews?limit=4", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp struct {
+			Data []domain.PullRequest `json:"data"`
+			Meta *handlers.PageMeta   `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 4)
+		require.NotNil(t, resp.Meta)
+
+		rec = call(http.MethodGet, "/api/v2/users/u2/reviews?limit=4&cursor="+resp.Meta.NextCursor, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.NotContains(t, rec.Body.String(), `"meta"`)
+		assert.Contains(t, rec.Body.String(), `"pr-1"`)
+	})
+
+	t.Run("v1 reviewer list is a page only on request", func(t *testing.T) {
+		var page handlers.PRPageResponse
+		rec := call(http.MethodGet, "/users/getReview?user_id=u2", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Len(t, page.PullRequests, 5)
+		assert.Empty(t, page.NextCursor)
+
+		page = handlers.PRPageResponse{}
+		rec = call(http.MethodGet, "/users/getReview?user_id=u2&limit=2", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Len(t, page.PullRequests, 2)
+		assert.NotEmpty(t, page.NextCursor)
+	})
+}
+
+// is_active - указатель: binding:"required" на bool отклонял бы false как
+// нулевое значение, и деактивировать пользователя было бы нельзя
+func TestRouter_SetIsActive(t *testing.T) {
+	router, tokenService := newMemoryRouter(t)
+	admin, err := tokenService.Create(tenant.WithOrg(context.Background(), tenant.DefaultOrgID), "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/team/add", `{"name":"backend","users":[{"user_id":"u1","username":"Alice","is_active":true}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	t.Run("false deactivates the user", func(t *testing.T) {
+		var resp struct {
+			User domain.User `json:"user"`
+		}
+		rec := call(http.MethodPost, "/users/setIsActive", `{"user_id":"u1","is_active":false}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.False(t, resp.User.IsActive)
+	})
+
+	t.Run("missing is_active is rejected", func(t *testing.T) {
+		rec := call(http.MethodPost, "/users/setIsActive", `{"user_id":"u1"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRouter_BatchCreatePRs(t *testing.T) {
+	router, tokenService := newMemoryRouter(t)
+	admin, err := tokenService.Create(tenant.WithOrg(context.Background(), tenant.DefaultOrgID), "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/team/add", `{"name":"backend","users":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Carol","is_active":true},{"user_id":"u4","username":"Dave","is_active":true},{"user_id":"u5","username":"Eve","is_active":true}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	batch := func(atomic bool, ids ...string) *handlers.BatchCreatePRResponse {
+		req := handlers.BatchCreatePRRequest{Atomic: atomic}
+		for _, id := range ids {
+			req.PullRequests = append(req.PullRequests, handlers.CreatePRRequest{PRID: id, PRName: "PR " + id, AuthorID: "u1"})
+		}
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rec := call(http.MethodPost, "/pullRequest/batchCreate", string(body))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp handlers.BatchCreatePRResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return &resp
+	}
+
+	t.Run("reviewers are spread across the batch", func(t *testing.T) {
+		resp := batch(false, "pr-1", "pr-2", "pr-3", "pr-4")
+		assert.Equal(t, 4, resp.Created)
+
+		load := make(map[string]int)
+		for _, result := range resp.Results {
+			require.Equal(t, services.BatchItemCreated, result.Status)
+			for _, reviewer := range result.PR.Reviewers {
+				load[reviewer.UserID]++
+			}
+		}
+		assert.Equal(t, map[string]int{"u2": 2, "u3": 2, "u4": 2, "u5": 2}, load)
+
+		rec := call(http.MethodGet, "/pullRequest/get?pull_request_id=pr-4", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("atomic batch with an existing PR creates nothing", func(t *testing.T) {
+		resp := batch(true, "pr-5", "pr-1")
+		assert.Equal(t, 0, resp.Created)
+		assert.Equal(t, 1, resp.Failed)
+		assert.Equal(t, services.BatchItemSkipped, resp.Results[0].Status)
+		require.NotNil(t, resp.Results[1].Error)
+		assert.Equal(t, "PR_EXISTS", resp.Results[1].Error.Code)
+
+		rec := call(http.MethodGet, "/pullRequest/get?pull_request_id=pr-5", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRouter_SyncTeams(t *testing.T) {
+	router, tokenService := newMemoryRouter(t)
+	admin, err := tokenService.Create(tenant.WithOrg(context.Background(), tenant.DefaultOrgID), "admin", "", []string{domain.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/team/add", `{"name":"backend","users":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Carol","is_active":true}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = call(http.MethodPost, "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	// u3 уходит из файла, u4 приходит в backend, u2 переходит в новую qa
+	doc := `
+teams:
+  - name: backend
+    members:
+      - {user_id: u1, username: Alice, role: lead}
+      - {user_id: u4, username: Dave}
+      - {user_id: u5, username: Eve}
+  - name: qa
+    buddy_teams: [backend]
+    members:
+      - {user_id: u2, username: Bob}
+`
+	sync := func(path, body string) *domain.SyncPlan {
+		rec := call(http.MethodPost, path, body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var plan domain.SyncPlan
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &plan))
+		return &plan
+	}
+
+	t.Run("dry run only shows the plan", func(t *testing.T) {
+		plan := sync("/admin/sync?dry_run=true", doc)
+		assert.True(t, plan.DryRun)
+		assert.Equal(t, []domain.TeamChange{{Action: domain.SyncCreate, Team: "qa"}}, plan.Teams)
+		assert.Equal(t, []domain.UserChange{
+			{Action: domain.SyncUpdate, UserID: "u1", Team: "backend", Changes: []string{"role: member -> lead"}},
+			{Action: domain.SyncCreate, UserID: "u4", Team: "backend"},
+			{Action: domain.SyncCreate, UserID: "u5", Team: "backend"},
+			{Action: domain.SyncMove, UserID: "u2", Team: "qa", FromTeam: "backend"},
+			{Action: domain.SyncDeactivate, UserID: "u3", Team: "backend"},
+		}, plan.Users)
+		assert.Equal(t, []domain.SyncReassignment{{PullRequestID: "pr-1", OldReviewerID: "u3"}}, plan.Reassignments)
+
+		rec := call(http.MethodGet, "/api/v2/teams/qa", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("apply changes teams and reassigns reviews", func(t *testing.T) {
+		plan := sync("/admin/sync", doc)
+		assert.False(t, plan.DryRun)
+		require.Len(t, plan.Reassignments, 1)
+		assert.Contains(t, []string{"u4", "u5"}, plan.Reassignments[0].NewReviewerID)
+
+		rec := call(http.MethodGet, "/api/v2/users/u3", "")
+		assert.Contains(t, rec.Body.String(), `"is_active":false`)
+		rec = call(http.MethodGet, "/api/v2/teams/qa/members", "")
+		assert.Contains(t, rec.Body.String(), `"user_id":"u2"`)
+
+		rec = call(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"user_id":"u3"`)
+		assert.Contains(t, rec.Body.String(), `"user_id":"`+plan.Reassignments[0].NewReviewerID+`"`)
+
+		assert.True(t, sync("/admin/sync", doc).IsEmpty())
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		rec := call(http.MethodPost, "/admin/sync", "teams:\n  - name: backend\n    reviewers: 3\n")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "INVALID_SYNC_DOCUMENT")
+
+		rec = call(http.MethodPost, "/admin/sync?dry_run=maybe", doc)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"sort"
+)
+
+// DefaultMaxBatchPRs - максимальное число PR в запросе пакетного создания
+// по умолчанию
+const DefaultMaxBatchPRs = 500
+
+// Исходы создания PR в пакете
+const (
+	BatchItemCreated = "CREATED"
+	BatchItemFailed  = "FAILED"
+	// BatchItemSkipped - PR без ошибок, не созданный, потому что в режиме
+	// "все или ничего" ошибка была у другого PR пакета
+	BatchItemSkipped = "SKIPPED"
+)
+
+// WithMaxBatchPRs ограничивает число PR в запросе пакетного создания;
+// 0 - без ограничения
+func WithMaxBatchPRs(n int) PRServiceOption {
+	return func(s *PRService) {
+		s.maxBatch = n
+	}
+}
+
+// NewPR - PR для пакетного создания
+type NewPR struct {
+	PRID         string
+	PRName       string
+	AuthorUserID string
+}
+
+// BatchItemResult - итог создания одного PR пакета. PR заполнен для
+// созданных PR, Err - для PR с ошибкой.
+type BatchItemResult struct {
+	PRID   string
+	Status string
+	PR     *domain.PullRequest
+	Err    error
+}
+
+// BatchResult - итоги пакетного создания в порядке PR запроса
+type BatchResult struct {
+	Items   []BatchItemResult
+	Created int
+	Failed  int
+}
+
+// batchPlanner выбирает ревьюверов для PR пакета до сохранения. Команды,
+// участники и партнеры загружаются один раз на пакет, а load считает
+// назначения, уже запланированные в пакете.
+type batchPlanner struct {
+	s *PRService
+
+	prIDs   map[string]bool
+	users   map[string]*domain.User
+	teams   map[int64]*domain.Team
+	members map[int64][]domain.User
+	buddies map[int64][]domain.Team
+	load    map[int64]int
+}
+
+// batchPlan - PR пакета с выбранными ревьюверами
+type batchPlan struct {
+	planned   domain.PlannedPR
+	author    *domain.User
+	reviewers []domain.User
+	missing   int
+}
+
+// CreatePRBatch создает PR пакетом. Ревьюверы выбираются с учетом нагрузки
+// внутри пакета: из доступных кандидатов предпочтение отдается тем, кому в
+// этом пакете назначено меньше PR. В режиме atomic PR сохраняются в одной
+// транзакции и только если ни у одного PR нет ошибки; иначе каждый PR
+// сохраняется отдельно, и ошибка одного не мешает остальным.
+func (s *PRService) CreatePRBatch(ctx context.Context, prs []NewPR, atomic bool) (_ *BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.CreatePRBatch")
+	defer func() { endSpan(span, err) }()
+
+	if s.maxBatch > 0 && len(prs) > s.maxBatch {
+		return nil, fmt.Errorf("%w: %d > %d", storage.ErrTooManyPRs, len(prs), s.maxBatch)
+	}
+
+	p := &batchPlanner{
+		s:       s,
+		prIDs:   make(map[string]bool),
+		users:   make(map[string]*domain.User),
+		teams:   make(map[int64]*domain.Team),
+		members: make(map[int64][]domain.User),
+		buddies: make(map[int64][]domain.Team),
+		load:    make(map[int64]int),
+	}
+
+	result := &BatchResult{Items: make([]BatchItemResult, len(prs))}
+	plans := make([]*batchPlan, len(prs))
+	for i, pr := range prs {
+		result.Items[i].PRID = pr.PRID
+
+		plan, err := p.plan(ctx, pr)
+		if err != nil {
+			result.Items[i].Status = BatchItemFailed
+			result.Items[i].Err = err
+			result.Failed++
+			continue
+		}
+		plans[i] = plan
+	}
+
+	if atomic {
+		if err := s.saveAtomic(ctx, result, plans); err != nil {
+			return nil, err
+		}
+	} else {
+		s.saveEach(ctx, result, plans)
+	}
+
+	logger.FromContext(ctx).Info("pull requests created in batch",
+		"created", result.Created, "failed", result.Failed, "atomic", atomic, "strategy", s.strategy)
+
+	return result, nil
+}
+
+// saveAtomic сохраняет все PR одной транзакцией, если ни у одного PR нет
+// ошибки. Иначе PR без ошибок помечаются как пропущенные.
+func (s *PRService) saveAtomic(ctx context.Context, result *BatchResult, plans []*batchPlan) error {
+	if result.Failed > 0 {
+		for i := range result.Items {
+			if plans[i] != nil {
+				result.Items[i].Status = BatchItemSkipped
+			}
+		}
+		return nil
+	}
+
+	planned := make([]domain.PlannedPR, 0, len(plans))
+	for _, plan := range plans {
+		planned = append(planned, plan.planned)
+	}
+
+	err := s.prRepo.CreateBatch(ctx, planned)
+	if err != nil {
+		return fmt.Errorf("failed to create PRs: %w", err)
+	}
+
+	for i, plan := range plans {
+		s.created(&result.Items[i], plan)
+		result.Created++
+	}
+	return nil
+}
+
+// saveEach сохраняет каждый PR без ошибки отдельно
+func (s *PRService) saveEach(ctx context.Context, result *BatchResult, plans []*batchPlan) {
+	for i, plan := range plans {
+		if plan == nil {
+			continue
+		}
+
+		err := s.prRepo.CreateBatch(ctx, []domain.PlannedPR{plan.planned})
+		if err != nil {
+			result.Items[i].Status = BatchItemFailed
+			result.Items[i].Err = fmt.Errorf("failed to create PR: %w", err)
+			result.Failed++
+			continue
+		}
+
+		s.created(&result.Items[i], plan)
+		result.Created++
+	}
+}
+
+// created заполняет итог сохраненного PR и учитывает исходы выбора
+// ревьюверов. PR заполняется из плана теми же полями, что возвращает
+// репозиторий: назначения сохранены в одной транзакции с PR, поэтому их время
+// совпадает со временем создания PR.
+func (s *PRService) created(item *BatchItemResult, plan *batchPlan) {
+	pr := plan.planned.PullRequest
+	pr.Author = plan.author
+	pr.Reviewers = plan.reviewers
+	pr.Assignments = make([]domain.ReviewerAssignment, 0, len(plan.reviewers))
+	for i, reviewer := range plan.reviewers {
+		reason := plan.planned.Reviewers[i].Reason
+		pr.Assignments = append(pr.Assignments, domain.ReviewerAssignment{
+			UserID:     reviewer.UserID,
+			Username:   reviewer.Username,
+			TeamID:     reviewer.TeamID,
+			Reason:     reason,
+			Borrowed:   reason == domain.AssignReasonBorrowed,
+			AssignedAt: pr.CreatedAt,
+		})
+	}
+	item.Status = BatchItemCreated
+	item.PR = pr
+
+	for _, reviewer := range plan.planned.Reviewers {
+		s.observe(reviewer.Reason, 1)
+	}
+	s.observe(OutcomeNoCandidate, plan.missing)
+}
+
+// plan проверяет PR и выбирает ревьюверов так же, как CreatePR: сначала
+// из команды автора, недостающих - из команд-партнеров
+func (p *batchPlanner) plan(ctx context.Context, pr NewPR) (*batchPlan, error) {
+	if p.prIDs[pr.PRID] {
+		return nil, storage.ErrPRExists
+	}
+	existing, err := p.s.prRepo.GetByPRID(ctx, pr.PRID)
+	if err == nil && existing != nil {
+		return nil, storage.ErrPRExists
+	}
+
+	author, err := p.user(ctx, pr.AuthorUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: author not found", storage.ErrNotFound)
+	}
+
+	team, err := p.team(ctx, author.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: author team not found", storage.ErrNotFound)
+	}
+
+	plan := &batchPlan{
+		planned: domain.PlannedPR{
+			PullRequest: &domain.PullRequest{
+				PullRequestID:   pr.PRID,
+				PullRequestName: pr.PRName,
+				AuthorID:        author.ID,
+				StatusID:        StatusOpenID,
+			},
+		},
+		author: author,
+	}
+
+	_, count := reviewerBounds(team)
+	excludeIDs := map[int64]bool{author.ID: true}
+
+	missing, err := p.assign(ctx, plan, team.ID, excludeIDs, count, domain.AssignReasonAuto)
+	if err != nil {
+		return nil, err
+	}
+
+	if missing > 0 {
+		buddies, err := p.buddyTeams(ctx, team.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get buddy teams: %w", err)
+		}
+
+		for _, buddy := range buddies {
+			if missing == 0 {
+				break
+			}
+
+			missing, err = p.assign(ctx, plan, buddy.ID, excludeIDs, missing, domain.AssignReasonBorrowed)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	plan.missing = missing
+	if missing > 0 {
+		logger.FromContext(ctx).Warn("not enough reviewer candidates",
+			"pull_request_id", pr.PRID, "team", team.Name, "missing", missing)
+	}
+
+	p.prIDs[pr.PRID] = true
+	for _, reviewer := range plan.reviewers {
+		p.load[reviewer.ID]++
+	}
+
+	return plan, nil
+}
+
+// assign выбирает до count ревьюверов из активных участников команды и
+// возвращает, сколько мест осталось незаполненными
+func (p *batchPlanner) assign(ctx context.Context, plan *batchPlan, teamID int64, excludeIDs map[int64]bool, count int, reason string) (int, error) {
+	members, err := p.teamMembers(ctx, teamID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	var candidates []domain.User
+	for _, user := range members {
+		if user.IsActive && !excludeIDs[user.ID] {
+			candidates = append(candidates, user)
+		}
+	}
+
+	selected, err := p.selectLeastLoaded(ctx, plan.planned.PullRequest, candidates, count)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reviewer := range selected {
+		plan.planned.Reviewers = append(plan.planned.Reviewers, domain.PlannedReviewer{ReviewerID: reviewer.ID, Reason: reason})
+		plan.reviewers = append(plan.reviewers, reviewer)
+		excludeIDs[reviewer.ID] = true
+	}
+
+	return count - len(selected), nil
+}
+
+// selectLeastLoaded выбирает до count ревьюверов, начиная с кандидатов с
+// наименьшим числом назначений в пакете. Среди кандидатов с одинаковой
+// нагрузкой выбирает настроенная стратегия.
+func (p *batchPlanner) selectLeastLoaded(ctx context.Context, pr *domain.PullRequest, candidates []domain.User, count int) ([]domain.User, error) {
+	byLoad := make(map[int][]domain.User)
+	var levels []int
+	for _, candidate := range candidates {
+		load := p.load[candidate.ID]
+		if _, ok := byLoad[load]; !ok {
+			levels = append(levels, load)
+		}
+		byLoad[load] = append(byLoad[load], candidate)
+	}
+	sort.Ints(levels)
+
+	selected := []domain.User{}
+	for _, level := range levels {
+		if len(selected) == count {
+			break
+		}
+
+		chosen, err := p.s.selectReviewers(ctx, pr, byLoad[level], count-len(selected))
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, chosen...)
+	}
+
+	return selected, nil
+}
+
+func (p *batchPlanner) user(ctx context.Context, userID string) (*domain.User, error) {
+	if user, ok := p.users[userID]; ok {
+		return user, nil
+	}
+	user, err := p.s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	p.users[userID] = user
+	return user, nil
+}
+
+func (p *batchPlanner) team(ctx context.Context, teamID int64) (*domain.Team, error) {
+	if team, ok := p.teams[teamID]; ok {
+		return team, nil
+	}
+	team, err := p.s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	p.teams[teamID] = team
+	return team, nil
+}
+
+func (p *batchPlanner) teamMembers(ctx context.Context, teamID int64) ([]domain.User, error) {
+	if members, ok := p.members[teamID]; ok {
+		return members, nil
+	}
+	members, err := p.s.userRepo.GetByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	p.members[teamID] = members
+	return members, nil
+}
+
+func (p *batchPlanner) buddyTeams(ctx context.Context, teamID int64) ([]domain.Team, error) {
+	if buddies, ok := p.buddies[teamID]; ok {
+		return buddies, nil
+	}
+	buddies, err := p.s.teamRepo.GetBuddyTeams(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	p.buddies[teamID] = buddies
+	return buddies, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reviewer-appointment-service/internal/storage"
+)
+
+type versionCtxKey struct{}
+
+// ContextWithExpectedVersion возвращает контекст с версией ресурса, которую
+// ожидает клиент (заголовок If-Match). Изменение ресурса с другой версией
+// отклоняется с ErrVersionConflict.
+func ContextWithExpectedVersion(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, versionCtxKey{}, version)
+}
+
+// WithoutExpectedVersion возвращает контекст без версии, ожидаемой клиентом.
+// Нужен операциям, которые меняют много ресурсов сразу: If-Match к ним не
+// относится.
+func WithoutExpectedVersion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, versionCtxKey{}, nil)
+}
+
+// ExpectedVersionFromContext возвращает версию, ожидаемую клиентом
+func ExpectedVersionFromContext(ctx context.Context) (int, bool) {
+	version, ok := ctx.Value(versionCtxKey{}).(int)
+	return version, ok
+}
+
+// checkVersion сравнивает текущую версию ресурса с ожидаемой клиентом.
+// Без ожидаемой версии изменение разрешено.
+func checkVersion(ctx context.Context, current int) error {
+	expected, ok := ExpectedVersionFromContext(ctx)
+	if !ok || expected == current {
+		return nil
+	}
+	return fmt.Errorf("%w: expected version %d, current %d", storage.ErrVersionConflict, expected, current)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"math"
+	"reviewer-appointment-service/internal/models/domain"
+	"sort"
+	"time"
+)
+
+// DefaultFairnessThreshold - допустимое относительное отклонение фактической
+// доли назначений от ожидаемой, после которого участник считается выбросом
+const DefaultFairnessThreshold = 0.5
+
+// BuildFairnessReport группирует нагрузку участников по командам и считает
+// метрики равномерности. Ожидаемая доля участника пропорциональна времени его
+// активности в периоде, поэтому неактивные периоды (отпуск и т.п.) не
+// увеличивают ожидания. Gini и отношение max/min считаются по числу
+// назначений на единицу активного времени среди участников, которые были
+// активны в периоде.
+func BuildFairnessReport(from, to time.Time, threshold float64, workloads []domain.MemberWorkload) *domain.FairnessReport {
+	report := &domain.FairnessReport{
+		From:      from,
+		To:        to,
+		Threshold: threshold,
+		Teams:     []domain.TeamFairness{},
+	}
+
+	byTeam := make(map[string][]domain.MemberWorkload)
+	var teamNames []string
+	for _, w := range workloads {
+		if _, ok := byTeam[w.TeamName]; !ok {
+			teamNames = append(teamNames, w.TeamName)
+		}
+		byTeam[w.TeamName] = append(byTeam[w.TeamName], w)
+	}
+	sort.Strings(teamNames)
+
+	for _, name := range teamNames {
+		report.Teams = append(report.Teams, teamFairness(name, threshold, byTeam[name]))
+	}
+
+	return report
+}
+
+func teamFairness(teamName string, threshold float64, members []domain.MemberWorkload) domain.TeamFairness {
+	team := domain.TeamFairness{
+		TeamName: teamName,
+		Members:  members,
+		Outliers: []string{},
+	}
+
+	var totalActive float64
+	for _, m := range members {
+		team.TotalAssignments += m.Assignments
+		totalActive += m.ActiveSeconds
+	}
+
+	var rates []float64
+	for i := range team.Members {
+		m := &team.Members[i]
+		if team.TotalAssignments > 0 {
+			m.ActualShare = float64(m.Assignments) / float64(team.TotalAssignments)
+		}
+		if totalActive > 0 {
+			m.ExpectedShare = m.ActiveSeconds / totalActive
+		}
+
+		if m.ActiveSeconds > 0 {
+			rates = append(rates, float64(m.Assignments)/m.ActiveSeconds)
+		}
+
+		switch {
+		case m.ExpectedShare > 0 && team.TotalAssignments > 0:
+			ratio := m.ActualShare / m.ExpectedShare
+			m.Ratio = &ratio
+			if math.Abs(ratio-1) > threshold {
+				team.Outliers = append(team.Outliers, m.UserID)
+			}
+		case m.ExpectedShare == 0 && m.Assignments > 0:
+			// назначения человеку, который весь период был неактивен
+			team.Outliers = append(team.Outliers, m.UserID)
+		}
+	}
+
+	team.Gini = gini(rates)
+	team.MaxMinRatio = maxMinRatio(rates)
+
+	return team
+}
+
+// gini возвращает коэффициент Джини для values или nil, если значений меньше
+// двух или все они нулевые
+func gini(values []float64) *float64 {
+	if len(values) < 2 {
+		return nil
+	}
+
+	var sum, diffs float64
+	for _, a := range values {
+		sum += a
+		for _, b := range values {
+			diffs += math.Abs(a - b)
+		}
+	}
+	if sum == 0 {
+		return nil
+	}
+
+	n := float64(len(values))
+	g := diffs / (2 * n * sum)
+	return &g
+}
+
+// maxMinRatio возвращает отношение максимального значения к минимальному или
+// nil, если значений меньше двух или минимум равен нулю
+func maxMinRatio(values []float64) *float64 {
+	if len(values) < 2 {
+		return nil
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	if lo == 0 {
+		return nil
+	}
+
+	ratio := hi / lo
+	return &ratio
+}
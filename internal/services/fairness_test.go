@@ -0,0 +1,79 @@
+package services
+
+import (
+	"reviewer-appointment-service/internal/models/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFairnessReport(t *testing.T) {
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-30 * 24 * time.Hour)
+	day := float64(24 * 60 * 60)
+
+	t.Run("equal load", func(t *testing.T) {
+		report := BuildFairnessReport(from, to, DefaultFairnessThreshold, []domain.MemberWorkload{
+			{TeamName: "backend", UserID: "u1", Assignments: 5, ActiveSeconds: 30 * day},
+			{TeamName: "backend", UserID: "u2", Assignments: 5, ActiveSeconds: 30 * day},
+		})
+
+		require.Len(t, report.Teams, 1)
+		team := report.Teams[0]
+		assert.Equal(t, 10, team.TotalAssignments)
+		require.NotNil(t, team.Gini)
+		assert.InDelta(t, 0, *team.Gini, 1e-9)
+		require.NotNil(t, team.MaxMinRatio)
+		assert.InDelta(t, 1, *team.MaxMinRatio, 1e-9)
+		assert.Empty(t, team.Outliers)
+		for _, m := range team.Members {
+			assert.InDelta(t, 0.5, m.ExpectedShare, 1e-9)
+			require.NotNil(t, m.Ratio)
+			assert.InDelta(t, 1, *m.Ratio, 1e-9)
+		}
+	})
+
+	t.Run("inactive time reduces expected share", func(t *testing.T) {
+		report := BuildFairnessReport(from, to, DefaultFairnessThreshold, []domain.MemberWorkload{
+			{TeamName: "backend", UserID: "u1", Assignments: 6, ActiveSeconds: 30 * day},
+			{TeamName: "backend", UserID: "u2", Assignments: 3, ActiveSeconds: 15 * day},
+		})
+
+		team := report.Teams[0]
+		assert.InDelta(t, 2.0/3, team.Members[0].ExpectedShare, 1e-9)
+		assert.InDelta(t, 1.0/3, team.Members[1].ExpectedShare, 1e-9)
+		assert.InDelta(t, 0, *team.Gini, 1e-9)
+		assert.Empty(t, team.Outliers)
+	})
+
+	t.Run("skewed load", func(t *testing.T) {
+		report := BuildFairnessReport(from, to, DefaultFairnessThreshold, []domain.MemberWorkload{
+			{TeamName: "backend", UserID: "u1", Assignments: 8, ActiveSeconds: 30 * day},
+			{TeamName: "backend", UserID: "u2", Assignments: 2, ActiveSeconds: 30 * day},
+			{TeamName: "backend", UserID: "u3", Assignments: 1, ActiveSeconds: 0},
+		})
+
+		team := report.Teams[0]
+		assert.Equal(t, 11, team.TotalAssignments)
+		assert.InDelta(t, 0.3, *team.Gini, 1e-9)
+		assert.InDelta(t, 4, *team.MaxMinRatio, 1e-9)
+		assert.Equal(t, []string{"u2", "u3"}, team.Outliers)
+		assert.Nil(t, team.Members[2].Ratio)
+	})
+
+	t.Run("no assignments", func(t *testing.T) {
+		report := BuildFairnessReport(from, to, DefaultFairnessThreshold, []domain.MemberWorkload{
+			{TeamName: "frontend", UserID: "u4", ActiveSeconds: 30 * day},
+			{TeamName: "backend", UserID: "u1", ActiveSeconds: 30 * day},
+		})
+
+		require.Len(t, report.Teams, 2)
+		assert.Equal(t, "backend", report.Teams[0].TeamName)
+		assert.Nil(t, report.Teams[0].Gini)
+		assert.Nil(t, report.Teams[0].MaxMinRatio)
+		assert.Nil(t, report.Teams[0].Members[0].Ratio)
+		assert.Empty(t, report.Teams[0].Outliers)
+	})
+}
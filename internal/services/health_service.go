@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReadinessTimeout - время на все проверки готовности
+const DefaultReadinessTimeout = 2 * time.Second
+
+// HealthService проверяет готовность сервиса обслуживать запросы
+type HealthService struct {
+	repo          storage.HealthRepository
+	schemaVersion int64
+	version       string
+	timeout       time.Duration
+
+	shuttingDown atomic.Bool
+}
+
+// NewHealthService создает сервис проверок. schemaVersion - версия миграций,
+// которую ожидает эта сборка; 0, если ее не удалось определить: тогда сервис
+// не считается готовым.
+func NewHealthService(repo storage.HealthRepository, schemaVersion int64, version string, timeout time.Duration) *HealthService {
+	if timeout <= 0 {
+		timeout = DefaultReadinessTimeout
+	}
+	return &HealthService{
+		repo:          repo,
+		schemaVersion: schemaVersion,
+		version:       version,
+		timeout:       timeout,
+	}
+}
+
+// Version возвращает версию сборки
+func (s *HealthService) Version() string {
+	return s.version
+}
+
+// SetShuttingDown переводит проверку готовности в состояние "недоступен",
+// чтобы балансировщик перестал направлять запросы на останавливаемый экземпляр
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Ready проверяет доступность БД, версию схемы и состояние пула соединений.
+// Сервис готов, если БД отвечает и схема не старше ожидаемой; заполненность
+// пула только сообщается.
+func (s *HealthService) Ready(ctx context.Context) *domain.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	report := &domain.HealthReport{
+		Status:  domain.HealthStatusOK,
+		Version: s.version,
+		Checks:  make(map[string]domain.HealthCheck),
+	}
+
+	if s.shuttingDown.Load() {
+		report.Status = domain.HealthStatusUnavailable
+		report.Checks["shutdown"] = checkResult(fmt.Errorf("service is shutting down"), nil)
+		return report
+	}
+
+	start := time.Now()
+	dbErr := s.repo.Ping(ctx)
+	report.Checks["database"] = checkResult(dbErr, map[string]interface{}{
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+	})
+
+	if dbErr != nil {
+		report.Checks["schema"] = checkResult(fmt.Errorf("database unavailable"), nil)
+	} else {
+		report.Checks["schema"] = s.checkSchema(ctx)
+	}
+
+	report.Checks["pool"] = poolCheck(s.repo.PoolStats())
+
+	for _, check := range report.Checks {
+		if check.Status != domain.HealthStatusOK {
+			report.Status = domain.HealthStatusUnavailable
+		}
+	}
+
+	return report
+}
+
+func (s *HealthService) checkSchema(ctx context.Context) domain.HealthCheck {
+	current, err := s.repo.SchemaVersion(ctx)
+	details := map[string]interface{}{
+		"current_version":  current,
+		"expected_version": s.schemaVersion,
+	}
+	if err == nil && s.schemaVersion <= 0 {
+		err = fmt.Errorf("expected schema version is unknown")
+	}
+	if err == nil && current < s.schemaVersion {
+		err = fmt.Errorf("schema version %d is older than expected %d", current, s.schemaVersion)
+	}
+	return checkResult(err, details)
+}
+
+func poolCheck(stats domain.PoolStats) domain.HealthCheck {
+	var saturation float64
+	if stats.MaxConns > 0 {
+		saturation = float64(stats.AcquiredConns) / float64(stats.MaxConns)
+	}
+	return domain.HealthCheck{
+		Status: domain.HealthStatusOK,
+		Details: map[string]interface{}{
+			"acquired_conns": stats.AcquiredConns,
+			"idle_conns":     stats.IdleConns,
+			"total_conns":    stats.TotalConns,
+			"max_conns":      stats.MaxConns,
+			"saturation":     saturation,
+		},
+	}
+}
+
+func checkResult(err error, details map[string]interface{}) domain.HealthCheck {
+	check := domain.HealthCheck{Status: domain.HealthStatusOK, Details: details}
+	if err != nil {
+		check.Status = domain.HealthStatusUnavailable
+		check.Error = err.Error()
+	}
+	return check
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reviewer-appointment-service/internal/models/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHealthRepository - мок для HealthRepository
+type MockHealthRepository struct {
+	mock.Mock
+}
+
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockHealthRepository) SchemaVersion(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHealthRepository) PoolStats() domain.PoolStats {
+	args := m.Called()
+	return args.Get(0).(domain.PoolStats)
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	ctx := context.Background()
+	stats := domain.PoolStats{AcquiredConns: 3, IdleConns: 1, TotalConns: 4, MaxConns: 4}
+
+	t.Run("ready", func(t *testing.T) {
+		repo := new(MockHealthRepository)
+		service := NewHealthService(repo, 8, "v1.2.3", 0)
+
+		repo.On("Ping", mock.Anything).Return(nil).Once()
+		repo.On("SchemaVersion", mock.Anything).Return(int64(8), nil).Once()
+		repo.On("PoolStats").Return(stats).Once()
+
+		report := service.Ready(ctx)
+		assert.Equal(t, domain.HealthStatusOK, report.Status)
+		assert.Equal(t, "v1.2.3", report.Version)
+		assert.Equal(t, 0.75, report.Checks["pool"].Details["saturation"])
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("database down", func(t *testing.T) {
+		repo := new(MockHealthRepository)
+		service := NewHealthService(repo, 8, "v1.2.3", 0)
+
+		repo.On("Ping", mock.Anything).Return(errors.New("connection refused")).Once()
+		repo.On("PoolStats").Return(domain.PoolStats{}).Once()
+
+		report := service.Ready(ctx)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Status)
+		assert.Equal(t, "connection refused", report.Checks["database"].Error)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Checks["schema"].Status)
+		repo.AssertNotCalled(t, "SchemaVersion", mock.Anything)
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		repo := new(MockHealthRepository)
+		service := NewHealthService(repo, 8, "v1.2.3", 0)
+		service.SetShuttingDown()
+
+		report := service.Ready(ctx)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Status)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Checks["shutdown"].Status)
+		repo.AssertNotCalled(t, "Ping", mock.Anything)
+	})
+
+	t.Run("unknown expected schema", func(t *testing.T) {
+		repo := new(MockHealthRepository)
+		service := NewHealthService(repo, 0, "v1.2.3", 0)
+
+		repo.On("Ping", mock.Anything).Return(nil).Once()
+		repo.On("SchemaVersion", mock.Anything).Return(int64(8), nil).Once()
+		repo.On("PoolStats").Return(stats).Once()
+
+		report := service.Ready(ctx)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Status)
+		assert.Equal(t, "expected schema version is unknown", report.Checks["schema"].Error)
+	})
+
+	t.Run("outdated schema", func(t *testing.T) {
+		repo := new(MockHealthRepository)
+		service := NewHealthService(repo, 8, "v1.2.3", 0)
+
+		repo.On("Ping", mock.Anything).Return(nil).Once()
+		repo.On("SchemaVersion", mock.Anything).Return(int64(7), nil).Once()
+		repo.On("PoolStats").Return(stats).Once()
+
+		report := service.Ready(ctx)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Status)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Checks["schema"].Status)
+		assert.Equal(t, domain.HealthStatusOK, report.Checks["database"].Status)
+	})
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL - сколько хранится ответ на запрос с ключом
+// идемпотентности
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// MaxIdempotencyKeyLength - максимальная длина ключа идемпотентности
+const MaxIdempotencyKeyLength = 255
+
+// idempotencyLock - сколько ключ остается занятым первым запросом, если тот
+// не успел сохранить ответ (например, процесс упал). После этого ключ можно
+// использовать заново.
+const idempotencyLock = time.Minute
+
+// idempotencyLockRefresh - как часто продлевается ключ запроса, который еще
+// выполняется
+const idempotencyLockRefresh = idempotencyLock / 3
+
+// idempotencyPurgeInterval - как часто удаляются истекшие ключи
+const idempotencyPurgeInterval = 10 * time.Minute
+
+// IdempotencyService хранит ответы на запросы с заголовком Idempotency-Key,
+// чтобы повтор запроса с тем же ключом и телом вернул первый ответ, а не
+// выполнил действие второй раз
+type IdempotencyService struct {
+	repo storage.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	lockRefresh time.Duration
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+func NewIdempotencyService(repo storage.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &IdempotencyService{
+		repo:        repo,
+		ttl:         ttl,
+		now:         time.Now,
+		lockRefresh: idempotencyLockRefresh,
+	}
+}
+
+// Begin занимает ключ key клиента client для запроса с хешем requestHash.
+// Возвращает nil, если запрос нужно выполнить (ключ новый или истек), или
+// сохраненный ответ, если запрос с этим ключом уже выполнен. Тот же ключ с
+// другим телом - ErrIdempotencyKeyMismatch; первый запрос еще выполняется -
+// ErrIdempotencyKeyInUse.
+func (s *IdempotencyService) Begin(ctx context.Context, client, key, requestHash string) (_ *domain.IdempotencyRecord, err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Begin")
+	defer func() { endSpan(span, err) }()
+
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, storage.ErrInvalidIdempotencyKey
+	}
+
+	s.purgeExpired(ctx)
+
+	now := s.now()
+	reserved, err := s.repo.Reserve(ctx, &domain.IdempotencyRecord{
+		Client:      client,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyLock),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	record, err := s.repo.Get(ctx, client, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		// запись удалили между Reserve и Get: первый запрос завершился ошибкой
+		return nil, storage.ErrIdempotencyKeyInUse
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.RequestHash != requestHash {
+		return nil, fmt.Errorf("%w: key %q", storage.ErrIdempotencyKeyMismatch, key)
+	}
+	if !record.Completed() {
+		return nil, storage.ErrIdempotencyKeyInUse
+	}
+
+	logger.FromContext(ctx).Info("idempotent request replayed",
+		"client", client,
+		"status", record.StatusCode,
+	)
+	return record, nil
+}
+
+// Hold продлевает ключ, занятый Begin, пока выполняется запрос: иначе
+// повтор запроса, который выполняется дольше idempotencyLock, выполнился бы
+// второй раз. Возвращенная функция останавливает продление. Продление не
+// зависит от отмены ctx.
+func (s *IdempotencyService) Hold(ctx context.Context, client, key, requestHash string) (stop func()) {
+	ctx = context.WithoutCancel(ctx)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(s.lockRefresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := s.repo.Extend(ctx, client, key, requestHash, s.now().Add(idempotencyLock))
+				if err != nil {
+					logger.FromContext(ctx).Warn("failed to extend idempotency key", "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// Complete сохраняет ответ на запрос, начатый Begin, на время TTL
+func (s *IdempotencyService) Complete(ctx context.Context, client, key, requestHash string, status int, contentType string, body []byte) (err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Complete")
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.Complete(ctx, &domain.IdempotencyRecord{
+		Client:       client,
+		Key:          key,
+		RequestHash:  requestHash,
+		StatusCode:   status,
+		ContentType:  contentType,
+		ResponseBody: body,
+		ExpiresAt:    s.now().Add(s.ttl),
+	})
+}
+
+// Release освобождает ключ без сохранения ответа, чтобы запрос можно было
+// повторить (например, после внутренней ошибки)
+func (s *IdempotencyService) Release(ctx context.Context, client, key string) (err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Release")
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.Delete(ctx, client, key)
+}
+
+// purgeExpired удаляет истекшие ключи не чаще idempotencyPurgeInterval.
+// Ошибка очистки не мешает запросу - истекшие ключи все равно
+// переиспользуются в Reserve.
+func (s *IdempotencyService) purgeExpired(ctx context.Context) {
+	now := s.now()
+
+	s.mu.Lock()
+	if now.Sub(s.lastPurged) < idempotencyPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurged = now
+	s.mu.Unlock()
+
+	deleted, err := s.repo.DeleteExpired(ctx, now)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to purge expired idempotency keys", "error", err)
+		return
+	}
+	if deleted > 0 {
+		logger.FromContext(ctx).Debug("expired idempotency keys purged", "count", deleted)
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIdempotencyRepository - мок для IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	args := m.Called(ctx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Get(ctx context.Context, client, key string) (*domain.IdempotencyRecord, error) {
+	args := m.Called(ctx, client, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Extend(ctx context.Context, client, key, requestHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, client, key, requestHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, client, key string) error {
+	args := m.Called(ctx, client, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newService := func() (*IdempotencyService, *MockIdempotencyRepository) {
+		repo := new(MockIdempotencyRepository)
+		service := NewIdempotencyService(repo, time.Hour)
+		service.now = func() time.Time { return now }
+		repo.On("DeleteExpired", mock.Anything, now).Return(int64(0), nil).Maybe()
+		return service, repo
+	}
+
+	t.Run("new key is reserved", func(t *testing.T) {
+		service, repo := newService()
+		repo.On("Reserve", mock.Anything, mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+			return r.Key == "k" && r.RequestHash == "h" && r.ExpiresAt.Equal(now.Add(idempotencyLock))
+		})).Return(true, nil).Once()
+
+		saved, err := service.Begin(ctx, "token:1", "k", "h")
+		require.NoError(t, err)
+		assert.Nil(t, saved)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("completed key is replayed", func(t *testing.T) {
+		service, repo := newService()
+		record := &domain.IdempotencyRecord{Key: "k", RequestHash: "h", StatusCode: 201, ResponseBody: []byte("{}")}
+		repo.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+		repo.On("Get", mock.Anything, "token:1", "k").Return(record, nil).Once()
+
+		saved, err := service.Begin(ctx, "token:1", "k", "h")
+		require.NoError(t, err)
+		assert.Equal(t, record, saved)
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		service, repo := newService()
+		record := &domain.IdempotencyRecord{Key: "k", RequestHash: "other", StatusCode: 201}
+		repo.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+		repo.On("Get", mock.Anything, "token:1", "k").Return(record, nil).Once()
+
+		_, err := service.Begin(ctx, "token:1", "k", "h")
+		assert.ErrorIs(t, err, storage.ErrIdempotencyKeyMismatch)
+	})
+
+	t.Run("first request in progress", func(t *testing.T) {
+		service, repo := newService()
+		record := &domain.IdempotencyRecord{Key: "k", RequestHash: "h"}
+		repo.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+		repo.On("Get", mock.Anything, "token:1", "k").Return(record, nil).Once()
+
+		_, err := service.Begin(ctx, "token:1", "k", "h")
+		assert.ErrorIs(t, err, storage.ErrIdempotencyKeyInUse)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.Begin(ctx, "token:1", string(make([]byte, MaxIdempotencyKeyLength+1)), "h")
+		assert.ErrorIs(t, err, storage.ErrInvalidIdempotencyKey)
+		repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything)
+	})
+}
+
+func TestIdempotencyService_Complete(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(repo, time.Hour)
+	service.now = func() time.Time { return now }
+
+	repo.On("Complete", mock.Anything, mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+		return r.StatusCode == 201 && r.ExpiresAt.Equal(now.Add(time.Hour)) && string(r.ResponseBody) == "{}"
+	})).Return(nil).Once()
+
+	require.NoError(t, service.Complete(ctx, "token:1", "k", "h", 201, "application/json", []byte("{}")))
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyService_Hold(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(repo, time.Hour)
+	service.now = func() time.Time { return now }
+	service.lockRefresh = time.Millisecond
+
+	extended := make(chan struct{}, 1)
+	repo.On("Extend", mock.Anything, "token:1", "k", "h", now.Add(idempotencyLock)).Return(nil).Run(func(mock.Arguments) {
+		select {
+		case extended <- struct{}{}:
+		default:
+		}
+	})
+
+	// Отмена запроса не останавливает продление: обработчик еще работает
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stop := service.Hold(ctx, "token:1", "k", "h")
+	select {
+	case <-extended:
+	case <-time.After(time.Second):
+		t.Fatal("key was not extended")
+	}
+	stop()
+
+	calls := len(repo.Calls)
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, repo.Calls, calls, "key is extended after stop")
+}
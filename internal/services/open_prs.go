@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+)
+
+// OpenPRCounter считает открытые PR и PR, у которых ревьюверов меньше
+// минимума команды автора. Минимум берется так же, как при назначении,
+// с учетом значений по умолчанию.
+type OpenPRCounter struct {
+	statsRepo storage.StatsRepository
+}
+
+func NewOpenPRCounter(statsRepo storage.StatsRepository) *OpenPRCounter {
+	return &OpenPRCounter{statsRepo: statsRepo}
+}
+
+// GetOpenPRCounts реализует metrics.PRCountsSource
+func (c *OpenPRCounter) GetOpenPRCounts(ctx context.Context) (*domain.OpenPRCounts, error) {
+	groups, err := c.statsRepo.GetOpenPRGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open PRs: %w", err)
+	}
+	return countOpenPRs(groups), nil
+}
+
+func countOpenPRs(groups []domain.OpenPRGroup) *domain.OpenPRCounts {
+	counts := &domain.OpenPRCounts{}
+	for _, g := range groups {
+		counts.Open += g.Count
+		if !g.HasTeam {
+			continue
+		}
+		minReviewers, _ := reviewerBounds(&domain.Team{MinReviewers: g.MinReviewers, MaxReviewers: g.MaxReviewers})
+		if g.Reviewers < minReviewers {
+			counts.Understaffed += g.Count
+		}
+	}
+	return counts
+}
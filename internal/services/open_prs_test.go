@@ -0,0 +1,24 @@
+package services
+
+import (
+	"reviewer-appointment-service/internal/models/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountOpenPRs(t *testing.T) {
+	counts := countOpenPRs([]domain.OpenPRGroup{
+		// Границы не заданы: минимум по умолчанию - один ревьювер
+		{HasTeam: true, Reviewers: 0, Count: 3},
+		{HasTeam: true, Reviewers: 1, Count: 2},
+		// Минимум больше максимума ограничивается максимумом
+		{HasTeam: true, MinReviewers: 3, MaxReviewers: 1, Reviewers: 1, Count: 4},
+		{HasTeam: true, MinReviewers: 2, MaxReviewers: 2, Reviewers: 1, Count: 5},
+		// Автор без команды не делает PR недоукомплектованным
+		{Reviewers: 0, Count: 1},
+	})
+
+	assert.Equal(t, 15, counts.Open)
+	assert.Equal(t, 8, counts.Understaffed)
+}
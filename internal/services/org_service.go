@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+)
+
+// orgSlugRe - допустимый slug организации: он передается в заголовке X-Org
+// и в аргументах CLI
+var orgSlugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// OrgService создает организации и находит их по slug
+type OrgService struct {
+	repo storage.OrgRepository
+}
+
+func NewOrgService(repo storage.OrgRepository) *OrgService {
+	return &OrgService{repo: repo}
+}
+
+// Create создает организацию. Если name пустое, используется slug.
+func (s *OrgService) Create(ctx context.Context, slug, name string) (_ *domain.Organization, err error) {
+	ctx, span := tracer.Start(ctx, "OrgService.Create")
+	defer func() { endSpan(span, err) }()
+
+	if !orgSlugRe.MatchString(slug) {
+		return nil, fmt.Errorf("%w: %q", storage.ErrInvalidOrgSlug, slug)
+	}
+
+	_, err = s.repo.GetBySlug(ctx, slug)
+	switch {
+	case err == nil:
+		return nil, storage.ErrOrgExists
+	case !errors.Is(err, storage.ErrNotFound):
+		return nil, fmt.Errorf("failed to check organization existence: %w", err)
+	}
+
+	if name == "" {
+		name = slug
+	}
+	org := &domain.Organization{Slug: slug, Name: name}
+	if err := s.repo.Create(ctx, org); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("organization created", "org_id", org.ID, "slug", org.Slug)
+
+	return org, nil
+}
+
+// Resolve находит организацию по slug
+func (s *OrgService) Resolve(ctx context.Context, slug string) (_ *domain.Organization, err error) {
+	ctx, span := tracer.Start(ctx, "OrgService.Resolve")
+	defer func() { endSpan(span, err) }()
+
+	org, err := s.repo.GetBySlug(ctx, slug)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: organization %s not found", storage.ErrNotFound, slug)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, nil
+}
+
+func (s *OrgService) List(ctx context.Context) (_ []domain.Organization, err error) {
+	ctx, span := tracer.Start(ctx, "OrgService.List")
+	defer func() { endSpan(span, err) }()
+
+	orgs, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOrgRepository - мок для OrgRepository
+type MockOrgRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrgRepository) Create(ctx context.Context, org *domain.Organization) error {
+	args := m.Called(ctx, org)
+	return args.Error(0)
+}
+
+func (m *MockOrgRepository) GetBySlug(ctx context.Context, slug string) (*domain.Organization, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Organization), args.Error(1)
+}
+
+func (m *MockOrgRepository) List(ctx context.Context) ([]domain.Organization, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Organization), args.Error(1)
+}
+
+func TestOrgService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("name defaults to slug", func(t *testing.T) {
+		repo := new(MockOrgRepository)
+		service := NewOrgService(repo)
+
+		repo.On("GetBySlug", mock.Anything, "acme").Return(nil, storage.ErrNotFound).Once()
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(o *domain.Organization) bool {
+			return o.Slug == "acme" && o.Name == "acme"
+		})).Return(nil).Once()
+
+		org, err := service.Create(ctx, "acme", "")
+		require.NoError(t, err)
+		assert.Equal(t, "acme", org.Name)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("existing slug", func(t *testing.T) {
+		repo := new(MockOrgRepository)
+		service := NewOrgService(repo)
+
+		repo.On("GetBySlug", mock.Anything, "acme").Return(&domain.Organization{ID: 2, Slug: "acme"}, nil).Once()
+
+		_, err := service.Create(ctx, "acme", "Acme")
+		assert.ErrorIs(t, err, storage.ErrOrgExists)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		repo := new(MockOrgRepository)
+		service := NewOrgService(repo)
+
+		for _, slug := range []string{"", "Acme", "-acme", "acme corp"} {
+			_, err := service.Create(ctx, slug, "")
+			assert.ErrorIs(t, err, storage.ErrInvalidOrgSlug, slug)
+		}
+		repo.AssertNotCalled(t, "GetBySlug", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOrgService_Resolve(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockOrgRepository)
+	service := NewOrgService(repo)
+
+	repo.On("GetBySlug", mock.Anything, "globex").Return(nil, storage.ErrNotFound).Once()
+
+	_, err := service.Resolve(ctx, "globex")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
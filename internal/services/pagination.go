@@ -0,0 +1,34 @@
+package services
+
+import "reviewer-appointment-service/internal/models/domain"
+
+// Размер страницы списков PR
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// pageLimit возвращает размер страницы фильтра: по умолчанию
+// DefaultPageLimit, не больше MaxPageLimit
+func pageLimit(filter domain.PRFilter) int {
+	if filter.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	return min(filter.Limit, MaxPageLimit)
+}
+
+// prPage обрезает выборку репозитория (до limit+1 строк) до страницы и
+// строит курсор следующей, если строк оказалось больше limit. limit 0 -
+// выборка целиком, без курсора.
+func prPage(prs []domain.PullRequest, limit int) *domain.PRPage {
+	page := &domain.PRPage{PullRequests: prs}
+	if page.PullRequests == nil {
+		page.PullRequests = []domain.PullRequest{}
+	}
+	if limit > 0 && len(prs) > limit {
+		page.PullRequests = prs[:limit]
+		last := prs[limit-1]
+		page.NextCursor = domain.PRCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return page
+}
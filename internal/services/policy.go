@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+)
+
+type tokenCtxKey struct{}
+
+// ContextWithToken возвращает контекст с токеном, которым аутентифицирован
+// запрос
+func ContextWithToken(ctx context.Context, token *domain.APIToken) context.Context {
+	return context.WithValue(ctx, tokenCtxKey{}, token)
+}
+
+// TokenFromContext возвращает токен запроса, если он был проверен
+func TokenFromContext(ctx context.Context) (*domain.APIToken, bool) {
+	token, ok := ctx.Value(tokenCtxKey{}).(*domain.APIToken)
+	return token, ok
+}
+
+// Policy решает, может ли вызывающий выполнить действие над конкретными
+// пользователями, командами и PR. Области доступа токена проверяются
+// раньше, в middleware; здесь - правила по ролям:
+//
+//   - токен с областью admin может все;
+//   - лид команды меняет активность и состав только своей команды;
+//   - автор PR и лид команды автора назначают ревьюверов на PR;
+//   - лид команды ревьювера переназначает и снимает этого ревьювера;
+//   - ревью отмечает сам ревьювер или лид его команды.
+//
+// Токены интеграций (не привязанные к пользователю) и запросы без токена
+// (аутентификация выключена) ограничиваются только областями доступа.
+type Policy struct {
+	userRepo storage.UserRepository
+	teamRepo storage.TeamRepository
+	prRepo   storage.PRRepository
+}
+
+func NewPolicy(userRepo storage.UserRepository, teamRepo storage.TeamRepository, prRepo storage.PRRepository) *Policy {
+	return &Policy{
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+		prRepo:   prRepo,
+	}
+}
+
+// Caller возвращает пользователя, от имени которого выполняется запрос.
+// nil без ошибки означает, что ролевые правила к запросу не применяются.
+func (p *Policy) Caller(ctx context.Context) (*domain.User, error) {
+	token, ok := TokenFromContext(ctx)
+	if !ok || token.UserID == "" || token.HasScope(domain.ScopeAdmin) {
+		return nil, nil
+	}
+
+	user, err := p.userRepo.GetByUserID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token owner %s not found", storage.ErrForbidden, token.UserID)
+	}
+	return user, nil
+}
+
+// CanSetIsActive: лид команды пользователя userID
+func (p *Policy) CanSetIsActive(ctx context.Context, userID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Policy.CanSetIsActive")
+	defer func() { endSpan(span, err) }()
+
+	caller, err := p.Caller(ctx)
+	if err != nil || caller == nil {
+		return err
+	}
+
+	target, err := p.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("%w: user not found", storage.ErrNotFound)
+	}
+
+	if !isLeadOf(caller, target.TeamID) {
+		return fmt.Errorf("%w: only a lead of the user's team can change activity", storage.ErrForbidden)
+	}
+	return nil
+}
+
+// CanCreateTeam: лид, если все уже существующие участники новой команды
+// состоят в его команде - лид может выделить часть своей команды, но не
+// забрать чужих участников
+func (p *Policy) CanCreateTeam(ctx context.Context, team *domain.Team) (err error) {
+	ctx, span := tracer.Start(ctx, "Policy.CanCreateTeam")
+	defer func() { endSpan(span, err) }()
+
+	caller, err := p.Caller(ctx)
+	if err != nil || caller == nil {
+		return err
+	}
+
+	if caller.Role != domain.RoleLead {
+		return fmt.Errorf("%w: only team leads can manage team members", storage.ErrForbidden)
+	}
+
+	for _, member := range team.Users {
+		existing, err := p.userRepo.GetByUserID(ctx, member.UserID)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user %s: %w", member.UserID, err)
+		}
+		if existing.TeamID != caller.TeamID {
+			return fmt.Errorf("%w: user %s belongs to another team", storage.ErrForbidden, member.UserID)
+		}
+	}
+	return nil
+}
+
+// CanManageTeam: лид команды teamName
+func (p *Policy) CanManageTeam(ctx context.Context, teamName string) (err error) {
+	ctx, span := tracer.Start(ctx, "Policy.CanManageTeam")
+	defer func() { endSpan(span, err) }()
+
+	caller, err := p.Caller(ctx)
+	if err != nil || caller == nil {
+		return err
+	}
+
+	team, err := p.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return fmt.Errorf("%w: team not found", storage.ErrNotFound)
+	}
+
+	if !isLeadOf(caller, team.ID) {
+		return fmt.Errorf("%w: only the team lead can manage the team", storage.ErrForbidden)
+	}
+	return nil
+}
+
+// CanChangeReviewer: автор PR, лид команды автора или лид команды
+// ревьювера reviewerID, если тот назначен на PR. Применяется к
+// переназначению и снятию ревьювера.
+func (p *Policy) CanChangeReviewer(ctx context.Context, prID, reviewerID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Policy.CanChangeReviewer")
+	defer func() { endSpan(span, err) }()
+
+	caller, err := p.Caller(ctx)
+	if err != nil || caller == nil {
+		return err
+	}
+
+	pr, ok, err := p.prOwnedBy(ctx, caller, prID)
+	if err != nil || ok {
+		return err
+	}
+
+	for _, reviewer := range pr.Reviewers {
+		if reviewer.UserID == reviewerID && isLeadOf(caller, reviewer.TeamID) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: only the PR author or a lead of the author's or the reviewer's team can change reviewers", storage.ErrForbidden)
+}
+
+// CanAddReviewer: автор PR или лид команды автора. Команда добавляемого
+// ревьювера не дает права: иначе лид мог бы назначить своих участников на
+// любой PR.
+func (p *Policy) CanAddReviewer(ctx context.Context, prID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Policy.CanAddReviewer")
+	defer func() { endSpan(span, err) }()
+
+	caller, err := p.Caller(ctx)
+	if err != nil || caller == nil {
+		return err
+	}
+
+	_, ok, err := p.prOwnedBy(ctx, caller, prID)
+	if err != nil || ok {
+		return err
+	}
+
+	return fmt.Errorf("%w: only the PR author or a lead of the author's team can add reviewers", storage.ErrForbidden)
+}
+
+// CanMarkReviewed: сам ревьювер reviewerID или лид его команды. Автор PR
+// не может отметить ревью за ревьювера: отметка влияет на метрики задержек.
+func (p *Policy) CanMarkReviewed(ctx context.Context, reviewerID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Policy.CanMarkReviewed")
+	defer func() { endSpan(span, err) }()
+
+	caller, err := p.Caller(ctx)
+	if err != nil || caller == nil {
+		return err
+	}
+
+	if caller.UserID == reviewerID {
+		return nil
+	}
+
+	reviewer, err := p.userRepo.GetByUserID(ctx, reviewerID)
+	if err != nil {
+		return fmt.Errorf("%w: reviewer not found", storage.ErrNotFound)
+	}
+
+	if !isLeadOf(caller, reviewer.TeamID) {
+		return fmt.Errorf("%w: only the reviewer or a lead of the reviewer's team can mark a review", storage.ErrForbidden)
+	}
+	return nil
+}
+
+// prOwnedBy возвращает PR и сообщает, является ли caller его автором или
+// лидом команды автора
+func (p *Policy) prOwnedBy(ctx context.Context, caller *domain.User, prID string) (*domain.PullRequest, bool, error) {
+	pr, err := p.prRepo.GetByPRID(ctx, prID)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: PR not found", storage.ErrNotFound)
+	}
+	if pr.AuthorID == caller.ID {
+		return pr, true, nil
+	}
+
+	author, err := p.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get PR author: %w", err)
+	}
+	return pr, isLeadOf(caller, author.TeamID), nil
+}
+
+func isLeadOf(user *domain.User, teamID int64) bool {
+	return user.Role == domain.RoleLead && user.TeamID == teamID
+}
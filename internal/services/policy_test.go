@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPolicy(t *testing.T) {
+	lead := &domain.User{ID: 1, UserID: "lead", TeamID: 10, Role: domain.RoleLead}
+	member := &domain.User{ID: 2, UserID: "member", TeamID: 10, Role: domain.RoleMember}
+	otherLead := &domain.User{ID: 3, UserID: "other-lead", TeamID: 20, Role: domain.RoleLead}
+	outsider := &domain.User{ID: 4, UserID: "outsider", TeamID: 20, Role: domain.RoleMember}
+
+	users := []*domain.User{lead, member, otherLead, outsider}
+	pr := &domain.PullRequest{ID: 100, PullRequestID: "pr-1", AuthorID: member.ID, Reviewers: []domain.User{*outsider}}
+
+	newPolicy := func() *Policy {
+		userRepo := new(MockUserRepository)
+		for _, u := range users {
+			userRepo.On("GetByUserID", mock.Anything, u.UserID).Return(u, nil)
+		}
+		userRepo.On("GetByUserID", mock.Anything, "new").Return(nil, storage.ErrNotFound)
+		userRepo.On("GetByUserID", mock.Anything, "broken").Return(nil, errors.New("connection reset"))
+		userRepo.On("GetByID", mock.Anything, member.ID).Return(member, nil)
+
+		teamRepo := new(MockTeamRepository)
+		teamRepo.On("GetByName", mock.Anything, "backend").Return(&domain.Team{ID: 10, Name: "backend"}, nil)
+		teamRepo.On("GetByName", mock.Anything, "frontend").Return(&domain.Team{ID: 20, Name: "frontend"}, nil)
+
+		prRepo := new(MockPRRepository)
+		prRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil)
+
+		return NewPolicy(userRepo, teamRepo, prRepo)
+	}
+
+	as := func(userID string, scopes ...string) context.Context {
+		return ContextWithToken(context.Background(), &domain.APIToken{UserID: userID, Scopes: scopes})
+	}
+
+	t.Run("set is active", func(t *testing.T) {
+		p := newPolicy()
+
+		assert.NoError(t, p.CanSetIsActive(as("lead", domain.ScopeTeamWrite), "member"))
+		assert.ErrorIs(t, p.CanSetIsActive(as("lead", domain.ScopeTeamWrite), "outsider"), storage.ErrForbidden)
+		assert.ErrorIs(t, p.CanSetIsActive(as("member", domain.ScopeTeamWrite), "member"), storage.ErrForbidden)
+		assert.NoError(t, p.CanSetIsActive(as("member", domain.ScopeAdmin), "outsider"))
+		assert.ErrorIs(t, p.CanSetIsActive(as("lead", domain.ScopeTeamWrite), "new"), storage.ErrNotFound)
+	})
+
+	t.Run("change reviewer", func(t *testing.T) {
+		p := newPolicy()
+
+		// Автор и лид команды автора меняют любого ревьювера PR
+		assert.NoError(t, p.CanChangeReviewer(as("member", domain.ScopePRWrite), "pr-1", "outsider"))
+		assert.NoError(t, p.CanChangeReviewer(as("lead", domain.ScopePRWrite), "pr-1", "outsider"))
+		// Лид другой команды - только назначенных ревьюверов из своей команды
+		assert.NoError(t, p.CanChangeReviewer(as("other-lead", domain.ScopePRWrite), "pr-1", "outsider"))
+		assert.ErrorIs(t, p.CanChangeReviewer(as("other-lead", domain.ScopePRWrite), "pr-1", "other-lead"), storage.ErrForbidden)
+		assert.ErrorIs(t, p.CanChangeReviewer(as("outsider", domain.ScopePRWrite), "pr-1", "outsider"), storage.ErrForbidden)
+	})
+
+	t.Run("add reviewer", func(t *testing.T) {
+		p := newPolicy()
+
+		assert.NoError(t, p.CanAddReviewer(as("member", domain.ScopePRWrite), "pr-1"))
+		assert.NoError(t, p.CanAddReviewer(as("lead", domain.ScopePRWrite), "pr-1"))
+		// Лид не может назначить участника своей команды на чужой PR
+		assert.ErrorIs(t, p.CanAddReviewer(as("other-lead", domain.ScopePRWrite), "pr-1"), storage.ErrForbidden)
+	})
+
+	t.Run("mark reviewed", func(t *testing.T) {
+		p := newPolicy()
+
+		assert.NoError(t, p.CanMarkReviewed(as("outsider", domain.ScopePRWrite), "outsider"))
+		assert.NoError(t, p.CanMarkReviewed(as("other-lead", domain.ScopePRWrite), "outsider"))
+		assert.NoError(t, p.CanMarkReviewed(as("member", domain.ScopeAdmin), "outsider"))
+		// Автор PR и лид чужой команды не отмечают ревью за ревьювера
+		assert.ErrorIs(t, p.CanMarkReviewed(as("member", domain.ScopePRWrite), "outsider"), storage.ErrForbidden)
+		assert.ErrorIs(t, p.CanMarkReviewed(as("lead", domain.ScopePRWrite), "outsider"), storage.ErrForbidden)
+		assert.ErrorIs(t, p.CanMarkReviewed(as("lead", domain.ScopePRWrite), "new"), storage.ErrNotFound)
+	})
+
+	t.Run("manage team", func(t *testing.T) {
+		p := newPolicy()
+
+		assert.NoError(t, p.CanManageTeam(as("lead", domain.ScopeTeamWrite), "backend"))
+		assert.ErrorIs(t, p.CanManageTeam(as("lead", domain.ScopeTeamWrite), "frontend"), storage.ErrForbidden)
+		assert.ErrorIs(t, p.CanManageTeam(as("member", domain.ScopeTeamWrite), "backend"), storage.ErrForbidden)
+	})
+
+	t.Run("create team", func(t *testing.T) {
+		p := newPolicy()
+		own := &domain.Team{Name: "platform", Users: []domain.User{{UserID: "member"}, {UserID: "new"}}}
+		foreign := &domain.Team{Name: "platform", Users: []domain.User{{UserID: "member"}, {UserID: "outsider"}}}
+
+		assert.NoError(t, p.CanCreateTeam(as("lead", domain.ScopeTeamWrite), own))
+		assert.ErrorIs(t, p.CanCreateTeam(as("lead", domain.ScopeTeamWrite), foreign), storage.ErrForbidden)
+		assert.ErrorIs(t, p.CanCreateTeam(as("member", domain.ScopeTeamWrite), own), storage.ErrForbidden)
+
+		// Ошибка БД не считается отсутствием пользователя
+		broken := &domain.Team{Name: "platform", Users: []domain.User{{UserID: "broken"}}}
+		err := p.CanCreateTeam(as("lead", domain.ScopeTeamWrite), broken)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, storage.ErrForbidden)
+	})
+
+	t.Run("unrestricted callers", func(t *testing.T) {
+		p := newPolicy()
+
+		// Аутентификация выключена
+		assert.NoError(t, p.CanSetIsActive(context.Background(), "outsider"))
+		// Токен интеграции без пользователя
+		assert.NoError(t, p.CanSetIsActive(as("", domain.ScopeTeamWrite), "outsider"))
+	})
+
+	t.Run("unknown token owner", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByUserID", mock.Anything, "ghost").Return(nil, storage.ErrNotFound)
+		p := NewPolicy(userRepo, nil, nil)
+
+		assert.ErrorIs(t, p.CanSetIsActive(as("ghost", domain.ScopeTeamWrite), "member"), storage.ErrForbidden)
+	})
+}
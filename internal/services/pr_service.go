@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"reviewer-appointment-service/internal/logger"
 	"reviewer-appointment-service/internal/models/domain"
 	"reviewer-appointment-service/internal/storage"
+	"sync"
 	"time"
 )
 
@@ -13,23 +15,53 @@ const (
 	StatusOpenID   = 1
 	StatusMergedID = 2
 	MaxReviewers   = 2
+
+	DefaultMinReviewers = 1
 )
 
 type PRService struct {
 	prRepo   storage.PRRepository
 	userRepo storage.UserRepository
 	teamRepo storage.TeamRepository
+	tx       storage.Transactor
+
+	strategy         string
+	rotationLookback time.Duration
+
+	now           func() time.Time
+	rnd           *rand.Rand
+	rndMu         sync.Mutex
+	deterministic bool
+
+	observer AssignmentObserver
+	maxBatch int
 }
 
-func NewPRService(prRepo storage.PRRepository, userRepo storage.UserRepository, teamRepo storage.TeamRepository) *PRService {
-	return &PRService{
+func NewPRService(prRepo storage.PRRepository, userRepo storage.UserRepository, teamRepo storage.TeamRepository, opts ...PRServiceOption) *PRService {
+	s := &PRService{
 		prRepo:   prRepo,
 		userRepo: userRepo,
 		teamRepo: teamRepo,
+		tx:       noTx{},
+		strategy: StrategyRandom,
+		now:      time.Now,
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		maxBatch: DefaultMaxBatchPRs,
+
+		rotationLookback: DefaultRotationLookback,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
-func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorUserID string) (*domain.PullRequest, error) {
+func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorUserID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.CreatePR")
+	defer func() { endSpan(span, err) }()
+
 	existingPR, err := s.prRepo.GetByPRID(ctx, prID)
 	if err == nil && existingPR != nil {
 		return nil, storage.ErrPRExists
@@ -40,7 +72,7 @@ func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorUserID str
 		return nil, fmt.Errorf("%w: author not found", storage.ErrNotFound)
 	}
 
-	_, err = s.teamRepo.GetByID(ctx, author.TeamID)
+	team, err := s.teamRepo.GetByID(ctx, author.TeamID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: author team not found", storage.ErrNotFound)
 	}
@@ -52,28 +84,19 @@ func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorUserID str
 		StatusID:        StatusOpenID,
 	}
 
-	err = s.prRepo.Create(ctx, pr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create PR: %w", err)
-	}
-
-	candidates, err := s.getActiveTeamMembers(ctx, author.TeamID, author.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get team members: %w", err)
-	}
-
-	reviewersCount := MaxReviewers
-	if len(candidates) < MaxReviewers {
-		reviewersCount = len(candidates)
-	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.prRepo.Create(ctx, pr); err != nil {
+			return fmt.Errorf("failed to create PR: %w", err)
+		}
 
-	selectedReviewers := s.selectRandomReviewers(candidates, reviewersCount)
+		_, reviewersCount := reviewerBounds(team)
+		excludeIDs := map[int64]bool{author.ID: true}
 
-	for _, reviewer := range selectedReviewers {
-		err = s.prRepo.AddReviewer(ctx, pr.ID, reviewer.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to add reviewer: %w", err)
-		}
+		_, err := s.fillReviewers(ctx, pr, team, excludeIDs, reviewersCount, domain.AssignReasonAuto)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	result, err := s.prRepo.GetByPRID(ctx, prID)
@@ -81,20 +104,35 @@ func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorUserID str
 		return nil, fmt.Errorf("failed to get created PR: %w", err)
 	}
 
+	reviewerIDs := make([]string, 0, len(result.Reviewers))
+	for _, reviewer := range result.Reviewers {
+		reviewerIDs = append(reviewerIDs, reviewer.UserID)
+	}
+	logger.FromContext(ctx).Info("pull request created",
+		"pull_request_id", prID, "author_id", authorUserID, "reviewers", reviewerIDs, "strategy", s.strategy)
+
 	return result, nil
 }
 
-func (s *PRService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+func (s *PRService) MergePR(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.MergePR")
+	defer func() { endSpan(span, err) }()
+
 	pr, err := s.prRepo.GetByPRID(ctx, prID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: PR not found", storage.ErrNotFound)
 	}
 
+	// Повторное слияние ничего не меняет, поэтому версия не проверяется
 	if pr.StatusID == StatusMergedID {
 		return pr, nil
 	}
 
-	now := time.Now()
+	if err := checkVersion(ctx, pr.Version); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
 	pr.StatusID = StatusMergedID
 	pr.MergedAt = &now
 
@@ -102,6 +140,7 @@ func (s *PRService) MergePR(ctx context.Context, prID string) (*domain.PullReque
 	if err != nil {
 		return nil, fmt.Errorf("failed to merge PR: %w", err)
 	}
+	logger.FromContext(ctx).Info("pull request merged", "pull_request_id", prID)
 
 	result, err := s.prRepo.GetByPRID(ctx, prID)
 	if err != nil {
@@ -111,24 +150,55 @@ func (s *PRService) MergePR(ctx context.Context, prID string) (*domain.PullReque
 	return result, nil
 }
 
-func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (string, error) {
+// ReassignOptions задает ограничения для переназначения ревьювера.
+// Если указан NewReviewerID, заменой становится именно этот пользователь,
+// иначе замена выбирается случайно из пула кандидатов с учетом Exclude,
+// Prefer и TeamName.
+type ReassignOptions struct {
+	NewReviewerID string
+	Exclude       []string
+	Prefer        []string
+	TeamName      string
+}
+
+// ReassignResult содержит итог переназначения
+type ReassignResult struct {
+	ReplacedBy string
+	Reviewers  []domain.ReviewerAssignment
+}
+
+func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.ReassignReviewer")
+	defer func() { endSpan(span, err) }()
+
+	result, err := s.ReassignReviewerWithOptions(ctx, prID, oldUserID, ReassignOptions{})
+	if err != nil {
+		return "", err
+	}
+	return result.ReplacedBy, nil
+}
+
+func (s *PRService) ReassignReviewerWithOptions(ctx context.Context, prID, oldUserID string, opts ReassignOptions) (_ *ReassignResult, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.ReassignReviewerWithOptions")
+	defer func() { endSpan(span, err) }()
+
 	pr, err := s.prRepo.GetByPRID(ctx, prID)
 	if err != nil {
-		return "", fmt.Errorf("%w: PR not found", storage.ErrNotFound)
+		return nil, fmt.Errorf("%w: PR not found", storage.ErrNotFound)
 	}
 
 	if pr.StatusID == StatusMergedID {
-		return "", storage.ErrPRMerged
+		return nil, storage.ErrPRMerged
 	}
 
 	oldReviewer, err := s.userRepo.GetByUserID(ctx, oldUserID)
 	if err != nil {
-		return "", fmt.Errorf("%w: old reviewer not found", storage.ErrNotFound)
+		return nil, fmt.Errorf("%w: old reviewer not found", storage.ErrNotFound)
 	}
 
 	reviewers, err := s.prRepo.GetReviewers(ctx, pr.ID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get reviewers: %w", err)
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
 	}
 
 	isAssigned := false
@@ -140,12 +210,128 @@ func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string
 	}
 
 	if !isAssigned {
-		return "", storage.ErrNotAssigned
+		return nil, storage.ErrNotAssigned
 	}
 
-	_, err = s.teamRepo.GetByID(ctx, oldReviewer.TeamID)
+	var newReviewer domain.User
+	var reason string
+	if opts.NewReviewerID != "" {
+		newReviewer, err = s.getRequestedReplacement(ctx, pr, oldReviewer, reviewers, opts)
+		if err != nil {
+			return nil, err
+		}
+		reason = domain.AssignReasonRequested
+	} else {
+		newReviewer, reason, err = s.pickReplacement(ctx, pr, oldReviewer, reviewers, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.claimVersion(ctx, pr); err != nil {
+			return err
+		}
+
+		if err := s.prRepo.RemoveReviewer(ctx, pr.ID, oldReviewer.ID); err != nil {
+			return fmt.Errorf("failed to remove old reviewer: %w", err)
+		}
+
+		if err := s.prRepo.AddReviewer(ctx, pr.ID, newReviewer.ID, reason); err != nil {
+			return fmt.Errorf("failed to add new reviewer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.observe(reason, 1)
+
+	logger.FromContext(ctx).Info("reviewer reassigned",
+		"pull_request_id", prID, "old_reviewer_id", oldUserID, "new_reviewer_id", newReviewer.UserID, "reason", reason)
+
+	assignments, err := s.prRepo.GetAssignments(ctx, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+
+	return &ReassignResult{
+		ReplacedBy: newReviewer.UserID,
+		Reviewers:  assignments,
+	}, nil
+}
+
+// getRequestedReplacement проверяет явно запрошенную замену по тем же
+// правилам, что и автоматическое назначение: замена не исключена в
+// opts.Exclude и состоит в указанной команде, а без нее - в команде старого
+// ревьювера или ее командах-партнерах
+func (s *PRService) getRequestedReplacement(ctx context.Context, pr *domain.PullRequest, oldReviewer *domain.User, reviewers []domain.User, opts ReassignOptions) (domain.User, error) {
+	candidate, err := s.userRepo.GetByUserID(ctx, opts.NewReviewerID)
 	if err != nil {
-		return "", fmt.Errorf("%w: reviewer team not found", storage.ErrNotFound)
+		return domain.User{}, fmt.Errorf("%w: new reviewer not found", storage.ErrNotFound)
+	}
+
+	if candidate.ID == pr.AuthorID {
+		return domain.User{}, storage.ErrReviewerIsAuthor
+	}
+
+	if !candidate.IsActive {
+		return domain.User{}, storage.ErrReviewerInactive
+	}
+
+	if isReviewer(reviewers, candidate.ID) {
+		return domain.User{}, storage.ErrAlreadyAssigned
+	}
+
+	if len(filterByUserIDs([]domain.User{*candidate}, opts.Exclude, false)) == 0 {
+		return domain.User{}, storage.ErrNoCandidate
+	}
+
+	if opts.TeamName != "" {
+		team, err := s.teamRepo.GetByName(ctx, opts.TeamName)
+		if err != nil {
+			return domain.User{}, fmt.Errorf("%w: team not found", storage.ErrNotFound)
+		}
+		if candidate.TeamID != team.ID {
+			return domain.User{}, storage.ErrNoCandidate
+		}
+		return *candidate, nil
+	}
+
+	if candidate.TeamID == oldReviewer.TeamID {
+		return *candidate, nil
+	}
+
+	buddies, err := s.teamRepo.GetBuddyTeams(ctx, oldReviewer.TeamID)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("failed to get buddy teams: %w", err)
+	}
+	for _, buddy := range buddies {
+		if candidate.TeamID == buddy.ID {
+			return *candidate, nil
+		}
+	}
+
+	return domain.User{}, storage.ErrNoCandidate
+}
+
+// pickReplacement выбирает случайную замену из команды старого ревьювера
+// (или из указанной команды), отдавая приоритет предпочтительным кандидатам.
+// Если в команде старого ревьювера заменить некем, замена берется из ее
+// команд-партнеров в порядке приоритета, как при назначении.
+func (s *PRService) pickReplacement(ctx context.Context, pr *domain.PullRequest, oldReviewer *domain.User, reviewers []domain.User, opts ReassignOptions) (domain.User, string, error) {
+	teamID := oldReviewer.TeamID
+	if opts.TeamName != "" {
+		team, err := s.teamRepo.GetByName(ctx, opts.TeamName)
+		if err != nil {
+			return domain.User{}, "", fmt.Errorf("%w: team not found", storage.ErrNotFound)
+		}
+		teamID = team.ID
+	} else {
+		_, err := s.teamRepo.GetByID(ctx, oldReviewer.TeamID)
+		if err != nil {
+			return domain.User{}, "", fmt.Errorf("%w: reviewer team not found", storage.ErrNotFound)
+		}
 	}
 
 	excludeIDs := make(map[int64]bool)
@@ -155,44 +341,461 @@ func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string
 		excludeIDs[reviewer.ID] = true
 	}
 
-	candidates, err := s.getActiveTeamMembersExcluding(ctx, oldReviewer.TeamID, excludeIDs)
+	candidates, err := s.getActiveTeamMembersExcluding(ctx, teamID, excludeIDs)
 	if err != nil {
-		return "", fmt.Errorf("failed to get replacement candidates: %w", err)
+		return domain.User{}, "", fmt.Errorf("failed to get replacement candidates: %w", err)
+	}
+
+	candidates = filterByUserIDs(candidates, opts.Exclude, false)
+	reason := domain.AssignReasonReassign
+
+	if len(candidates) == 0 && opts.TeamName == "" {
+		buddies, err := s.teamRepo.GetBuddyTeams(ctx, oldReviewer.TeamID)
+		if err != nil {
+			return domain.User{}, "", fmt.Errorf("failed to get buddy teams: %w", err)
+		}
+		for _, buddy := range buddies {
+			candidates, err = s.getActiveTeamMembersExcluding(ctx, buddy.ID, excludeIDs)
+			if err != nil {
+				return domain.User{}, "", fmt.Errorf("failed to get buddy team members: %w", err)
+			}
+			candidates = filterByUserIDs(candidates, opts.Exclude, false)
+			if len(candidates) > 0 {
+				reason = domain.AssignReasonBorrowed
+				break
+			}
+		}
 	}
 
 	if len(candidates) == 0 {
-		return "", storage.ErrNoCandidate
+		s.observe(OutcomeNoCandidate, 1)
+		return domain.User{}, "", storage.ErrNoCandidate
+	}
+
+	if preferred := filterByUserIDs(candidates, opts.Prefer, true); len(preferred) > 0 {
+		candidates = preferred
+		reason = domain.AssignReasonPreferred
+	}
+
+	selected, err := s.selectReviewers(ctx, pr, candidates, 1)
+	if err != nil {
+		return domain.User{}, "", err
+	}
+
+	return selected[0], reason, nil
+}
+
+// filterByUserIDs оставляет (keep = true) или убирает (keep = false)
+// пользователей с указанными user_id
+func filterByUserIDs(users []domain.User, userIDs []string, keep bool) []domain.User {
+	if len(userIDs) == 0 {
+		if keep {
+			return nil
+		}
+		return users
+	}
+
+	set := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		set[id] = true
+	}
+
+	var result []domain.User
+	for _, user := range users {
+		if set[user.UserID] == keep {
+			result = append(result, user)
+		}
+	}
+	return result
+}
+
+// AddReviewer вручную назначает указанного пользователя ревьювером PR
+func (s *PRService) AddReviewer(ctx context.Context, prID, reviewerUserID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.AddReviewer")
+	defer func() { endSpan(span, err) }()
+
+	pr, err := s.getOpenPR(ctx, prID)
+	if err != nil {
+		return nil, err
 	}
 
-	newReviewer := s.selectRandomReviewers(candidates, 1)[0]
+	reviewer, err := s.userRepo.GetByUserID(ctx, reviewerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reviewer not found", storage.ErrNotFound)
+	}
+
+	if reviewer.ID == pr.AuthorID {
+		return nil, storage.ErrReviewerIsAuthor
+	}
+
+	if !reviewer.IsActive {
+		return nil, storage.ErrReviewerInactive
+	}
+
+	if isReviewer(pr.Reviewers, reviewer.ID) {
+		return nil, storage.ErrAlreadyAssigned
+	}
 
-	err = s.prRepo.RemoveReviewer(ctx, pr.ID, oldReviewer.ID)
+	team, err := s.getAuthorTeam(ctx, pr)
 	if err != nil {
-		return "", fmt.Errorf("failed to remove old reviewer: %w", err)
+		return nil, err
 	}
 
-	err = s.prRepo.AddReviewer(ctx, pr.ID, newReviewer.ID)
+	_, maxReviewers := reviewerBounds(team)
+	if len(pr.Reviewers) >= maxReviewers {
+		return nil, storage.ErrReviewerLimit
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.claimVersion(ctx, pr); err != nil {
+			return err
+		}
+
+		if err := s.prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonManual); err != nil {
+			return fmt.Errorf("failed to add reviewer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.prRepo.GetByPRID(ctx, prID)
 	if err != nil {
-		return "", fmt.Errorf("failed to add new reviewer: %w", err)
+		return nil, fmt.Errorf("failed to get updated PR: %w", err)
 	}
 
-	return newReviewer.UserID, nil
+	return result, nil
 }
 
-func (s *PRService) getActiveTeamMembers(ctx context.Context, teamID, excludeUserID int64) ([]domain.User, error) {
-	allUsers, err := s.userRepo.GetByTeamID(ctx, teamID)
+// RemoveOptions настраивает снятие ревьювера
+type RemoveOptions struct {
+	// AllowShortfall разрешает оставить PR с числом ревьюверов меньше
+	// минимума команды, если добрать недостающих некем. Нужен, когда
+	// ревьювера нельзя оставить, например при деактивации.
+	AllowShortfall bool
+}
+
+// RemoveReviewer снимает ревьювера с PR. Если после снятия ревьюверов
+// становится меньше минимума команды, недостающие добираются автоматически;
+// если добрать их некем, ревьювер не снимается и возвращается ErrNoCandidate.
+func (s *PRService) RemoveReviewer(ctx context.Context, prID, reviewerUserID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.RemoveReviewer")
+	defer func() { endSpan(span, err) }()
+
+	return s.RemoveReviewerWithOptions(ctx, prID, reviewerUserID, RemoveOptions{})
+}
+
+// RemoveReviewerWithOptions снимает ревьювера с PR так же, как RemoveReviewer,
+// с учетом opts
+func (s *PRService) RemoveReviewerWithOptions(ctx context.Context, prID, reviewerUserID string, opts RemoveOptions) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.RemoveReviewerWithOptions")
+	defer func() { endSpan(span, err) }()
+
+	pr, err := s.getOpenPR(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
 
-	var activeUsers []domain.User
-	for _, user := range allUsers {
-		if user.IsActive && user.ID != excludeUserID {
-			activeUsers = append(activeUsers, user)
+	reviewer, err := s.userRepo.GetByUserID(ctx, reviewerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reviewer not found", storage.ErrNotFound)
+	}
+
+	if !isReviewer(pr.Reviewers, reviewer.ID) {
+		return nil, storage.ErrNotAssigned
+	}
+
+	team, err := s.getAuthorTeam(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.claimVersion(ctx, pr); err != nil {
+			return err
+		}
+
+		if err := s.prRepo.RemoveReviewer(ctx, pr.ID, reviewer.ID); err != nil {
+			return fmt.Errorf("failed to remove reviewer: %w", err)
+		}
+
+		minReviewers, _ := reviewerBounds(team)
+		missing := minReviewers - (len(pr.Reviewers) - 1)
+		if missing <= 0 {
+			return nil
+		}
+
+		excludeIDs := make(map[int64]bool)
+		excludeIDs[pr.AuthorID] = true
+		for _, r := range pr.Reviewers {
+			excludeIDs[r.ID] = true
+		}
+
+		missing, err := s.fillReviewers(ctx, pr, team, excludeIDs, missing, domain.AssignReasonBackfill)
+		if err != nil {
+			return err
 		}
+		if missing > 0 && !opts.AllowShortfall {
+			return fmt.Errorf("%w: %d reviewer(s) below the team minimum", storage.ErrNoCandidate, missing)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return activeUsers, nil
+	result, err := s.prRepo.GetByPRID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated PR: %w", err)
+	}
+
+	return result, nil
+}
+
+// MarkReviewed фиксирует действие ревьювера по PR (первое ревью). Повторная
+// отметка не меняет время первого действия.
+func (s *PRService) MarkReviewed(ctx context.Context, prID, reviewerUserID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.MarkReviewed")
+	defer func() { endSpan(span, err) }()
+
+	pr, err := s.getOpenPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewer, err := s.userRepo.GetByUserID(ctx, reviewerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reviewer not found", storage.ErrNotFound)
+	}
+
+	if !isReviewer(pr.Reviewers, reviewer.ID) {
+		return nil, storage.ErrNotAssigned
+	}
+
+	if err := s.claimVersion(ctx, pr); err != nil {
+		return nil, err
+	}
+
+	err = s.prRepo.MarkReviewed(ctx, pr.ID, reviewer.ID, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark review: %w", err)
+	}
+
+	result, err := s.prRepo.GetByPRID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated PR: %w", err)
+	}
+
+	return result, nil
+}
+
+// EscalationResult описывает действие, выполненное при эскалации ревью
+type EscalationResult struct {
+	Action       string
+	TargetUserID string
+}
+
+// EscalateReview эскалирует просроченное ревью: добавляет лида команды автора
+// дополнительным ревьювером (сверх лимита команды), а если лида нет или он уже
+// назначен, переназначает просроченное ревью на другого участника.
+func (s *PRService) EscalateReview(ctx context.Context, prID, reviewerUserID string) (_ *EscalationResult, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.EscalateReview")
+	defer func() { endSpan(span, err) }()
+
+	pr, err := s.getOpenPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewer, err := s.userRepo.GetByUserID(ctx, reviewerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reviewer not found", storage.ErrNotFound)
+	}
+
+	if !isReviewer(pr.Reviewers, reviewer.ID) {
+		return nil, storage.ErrNotAssigned
+	}
+
+	team, err := s.getAuthorTeam(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.userRepo.GetByTeamID(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	for _, member := range members {
+		if member.Role != domain.RoleLead || !member.IsActive ||
+			member.ID == pr.AuthorID || isReviewer(pr.Reviewers, member.ID) {
+			continue
+		}
+
+		err = s.tx.InTx(ctx, func(ctx context.Context) error {
+			if err := s.claimVersion(ctx, pr); err != nil {
+				return err
+			}
+
+			if err := s.prRepo.AddReviewer(ctx, pr.ID, member.ID, domain.AssignReasonEscalated); err != nil {
+				return fmt.Errorf("failed to add team lead: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		logger.FromContext(ctx).Info("review escalated to team lead",
+			"pull_request_id", prID, "reviewer_id", reviewerUserID, "lead_id", member.UserID)
+
+		return &EscalationResult{
+			Action:       domain.EscalationLeadAdded,
+			TargetUserID: member.UserID,
+		}, nil
+	}
+
+	result, err := s.ReassignReviewerWithOptions(ctx, prID, reviewerUserID, ReassignOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EscalationResult{
+		Action:       domain.EscalationReassigned,
+		TargetUserID: result.ReplacedBy,
+	}, nil
+}
+
+// fillReviewers назначает до count ревьюверов: сначала из активных участников
+// команды, а недостающих - из команд-партнеров в порядке приоритета.
+// Назначенные пользователи добавляются в excludeIDs. Возвращает, сколько
+// мест осталось незаполненными.
+func (s *PRService) fillReviewers(ctx context.Context, pr *domain.PullRequest, team *domain.Team, excludeIDs map[int64]bool, count int, reason string) (int, error) {
+	candidates, err := s.getActiveTeamMembersExcluding(ctx, team.ID, excludeIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	missing, err := s.assignReviewers(ctx, pr, candidates, excludeIDs, count, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	if missing == 0 {
+		return 0, nil
+	}
+
+	buddies, err := s.teamRepo.GetBuddyTeams(ctx, team.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get buddy teams: %w", err)
+	}
+
+	for _, buddy := range buddies {
+		if missing == 0 {
+			break
+		}
+
+		candidates, err := s.getActiveTeamMembersExcluding(ctx, buddy.ID, excludeIDs)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get buddy team members: %w", err)
+		}
+
+		missing, err = s.assignReviewers(ctx, pr, candidates, excludeIDs, missing, domain.AssignReasonBorrowed)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	s.observe(OutcomeNoCandidate, missing)
+	if missing > 0 {
+		logger.FromContext(ctx).Warn("not enough reviewer candidates",
+			"pull_request_id", pr.PullRequestID, "team", team.Name, "missing", missing)
+	}
+
+	return missing, nil
+}
+
+// assignReviewers назначает до count случайных кандидатов и возвращает,
+// сколько мест осталось незаполненными
+func (s *PRService) assignReviewers(ctx context.Context, pr *domain.PullRequest, candidates []domain.User, excludeIDs map[int64]bool, count int, reason string) (int, error) {
+	selected, err := s.selectReviewers(ctx, pr, candidates, count)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reviewer := range selected {
+		err := s.prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, reason)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add reviewer: %w", err)
+		}
+		excludeIDs[reviewer.ID] = true
+	}
+	s.observe(reason, len(selected))
+
+	return count - len(selected), nil
+}
+
+func (s *PRService) getOpenPR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.prRepo.GetByPRID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: PR not found", storage.ErrNotFound)
+	}
+
+	if pr.StatusID == StatusMergedID {
+		return nil, storage.ErrPRMerged
+	}
+
+	return pr, nil
+}
+
+// claimVersion проверяет версию PR, ожидаемую клиентом, и увеличивает ее
+// перед изменением ревьюверов. Из двух одновременных изменений одного PR
+// второе получает ErrVersionConflict.
+func (s *PRService) claimVersion(ctx context.Context, pr *domain.PullRequest) error {
+	if err := checkVersion(ctx, pr.Version); err != nil {
+		return err
+	}
+	return s.prRepo.BumpVersion(ctx, pr.ID, pr.Version)
+}
+
+func (s *PRService) getAuthorTeam(ctx context.Context, pr *domain.PullRequest) (*domain.Team, error) {
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: author not found", storage.ErrNotFound)
+	}
+
+	team, err := s.teamRepo.GetByID(ctx, author.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: author team not found", storage.ErrNotFound)
+	}
+
+	return team, nil
+}
+
+// reviewerBounds возвращает допустимое число ревьюверов для PR команды.
+// Незаданные границы заменяются значениями по умолчанию.
+func reviewerBounds(team *domain.Team) (int, int) {
+	minReviewers, maxReviewers := team.MinReviewers, team.MaxReviewers
+	if maxReviewers <= 0 {
+		maxReviewers = MaxReviewers
+	}
+	if minReviewers <= 0 && team.MaxReviewers <= 0 {
+		minReviewers = DefaultMinReviewers
+	}
+	if minReviewers > maxReviewers {
+		minReviewers = maxReviewers
+	}
+	return minReviewers, maxReviewers
+}
+
+func isReviewer(reviewers []domain.User, userID int64) bool {
+	for _, reviewer := range reviewers {
+		if reviewer.ID == userID {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *PRService) getActiveTeamMembersExcluding(ctx context.Context, teamID int64, excludeIDs map[int64]bool) ([]domain.User, error) {
@@ -211,27 +814,28 @@ func (s *PRService) getActiveTeamMembersExcluding(ctx context.Context, teamID in
 	return activeUsers, nil
 }
 
-func (s *PRService) GetPR(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	return s.prRepo.GetByPRID(ctx, prID)
-}
+func (s *PRService) GetPR(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.GetPR")
+	defer func() { endSpan(span, err) }()
 
-func (s *PRService) selectRandomReviewers(candidates []domain.User, count int) []domain.User {
-	if count <= 0 || len(candidates) == 0 {
-		return []domain.User{}
+	pr, err := s.prRepo.GetByPRID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: PR not found", storage.ErrNotFound)
 	}
 
-	if count >= len(candidates) {
-		return candidates
-	}
+	return pr, nil
+}
 
-	shuffled := make([]domain.User, len(candidates))
-	copy(shuffled, candidates)
+// ListPRs возвращает страницу PR организации по фильтру
+func (s *PRService) ListPRs(ctx context.Context, filter domain.PRFilter) (_ *domain.PRPage, err error) {
+	ctx, span := tracer.Start(ctx, "PRService.ListPRs")
+	defer func() { endSpan(span, err) }()
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := len(shuffled) - 1; i > 0; i-- {
-		j := r.Intn(i + 1)
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	filter.Limit = pageLimit(filter)
+	prs, err := s.prRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
 	}
 
-	return shuffled[:count]
+	return prPage(prs, filter.Limit), nil
 }
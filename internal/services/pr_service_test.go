@@ -22,6 +22,11 @@ func (m *MockPRRepository) Create(ctx context.Context, pr *domain.PullRequest) e
 	return args.Error(0)
 }
 
+func (m *MockPRRepository) CreateBatch(ctx context.Context, prs []domain.PlannedPR) error {
+	args := m.Called(ctx, prs)
+	return args.Error(0)
+}
+
 func (m *MockPRRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
 	args := m.Called(ctx, pr)
 	return args.Error(0)
@@ -43,6 +48,14 @@ func (m *MockPRRepository) GetByReviewerID(ctx context.Context, reviewerID strin
 	return args.Get(0).([]domain.PullRequest), args.Error(1)
 }
 
+func (m *MockPRRepository) List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PullRequest), args.Error(1)
+}
+
 func (m *MockPRRepository) GetOpenPRsByUserIDs(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
 	args := m.Called(ctx, userIDs)
 	if args.Get(0) == nil {
@@ -51,8 +64,8 @@ func (m *MockPRRepository) GetOpenPRsByUserIDs(ctx context.Context, userIDs []st
 	return args.Get(0).([]domain.PullRequest), args.Error(1)
 }
 
-func (m *MockPRRepository) AddReviewer(ctx context.Context, prID int64, reviewerID int64) error {
-	args := m.Called(ctx, prID, reviewerID)
+func (m *MockPRRepository) AddReviewer(ctx context.Context, prID int64, reviewerID int64, reason string) error {
+	args := m.Called(ctx, prID, reviewerID, reason)
 	return args.Error(0)
 }
 
@@ -69,6 +82,32 @@ func (m *MockPRRepository) GetReviewers(ctx context.Context, prID int64) ([]doma
 	return args.Get(0).([]domain.User), args.Error(1)
 }
 
+func (m *MockPRRepository) GetAssignments(ctx context.Context, prID int64) ([]domain.ReviewerAssignment, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ReviewerAssignment), args.Error(1)
+}
+
+func (m *MockPRRepository) GetPairCounts(ctx context.Context, authorID int64, since time.Time) (map[int64]int, error) {
+	args := m.Called(ctx, authorID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]int), args.Error(1)
+}
+
+func (m *MockPRRepository) MarkReviewed(ctx context.Context, prID int64, reviewerID int64, at time.Time) error {
+	args := m.Called(ctx, prID, reviewerID, at)
+	return args.Error(0)
+}
+
+func (m *MockPRRepository) BumpVersion(ctx context.Context, prID int64, version int) error {
+	args := m.Called(ctx, prID, version)
+	return args.Error(0)
+}
+
 func TestPRService_CreatePR(t *testing.T) {
 	ctx := context.Background()
 
@@ -109,18 +148,18 @@ func TestPRService_CreatePR(t *testing.T) {
 			},
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(nil, errors.New("not found")).Once()
-		mockUserRepo.On("GetByUserID", ctx, "u1").Return(author, nil).Once()
-		mockTeamRepo.On("GetByID", ctx, int64(1)).Return(team, nil).Once()
-		mockPRRepo.On("Create", ctx, mock.AnythingOfType("*domain.PullRequest")).Run(func(args mock.Arguments) {
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(nil, errors.New("not found")).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockPRRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.PullRequest")).Run(func(args mock.Arguments) {
 			pr := args.Get(1).(*domain.PullRequest)
 			pr.ID = 1
 			pr.CreatedAt = time.Now()
 		}).Return(nil).Once()
-		mockUserRepo.On("GetByTeamID", ctx, int64(1)).Return(candidates, nil).Once()
-		mockPRRepo.On("AddReviewer", ctx, int64(1), int64(2)).Return(nil).Once()
-		mockPRRepo.On("AddReviewer", ctx, int64(1), int64(3)).Return(nil).Once()
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(createdPR, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(candidates, nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(2), domain.AssignReasonAuto).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(3), domain.AssignReasonAuto).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(createdPR, nil).Once()
 
 		result, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
 		assert.NoError(t, err)
@@ -145,7 +184,7 @@ func TestPRService_CreatePR(t *testing.T) {
 			StatusID:        StatusOpenID,
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(existingPR, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(existingPR, nil).Once()
 
 		_, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
 		assert.Error(t, err)
@@ -159,8 +198,8 @@ func TestPRService_CreatePR(t *testing.T) {
 		mockTeamRepo := new(MockTeamRepository)
 		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(nil, errors.New("not found")).Once()
-		mockUserRepo.On("GetByUserID", ctx, "non-existent").Return(nil, errors.New("not found")).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(nil, errors.New("not found")).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "non-existent").Return(nil, errors.New("not found")).Once()
 
 		_, err := service.CreatePR(ctx, "pr-1", "Test PR", "non-existent")
 		assert.Error(t, err)
@@ -204,17 +243,18 @@ func TestPRService_CreatePR(t *testing.T) {
 			},
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(nil, errors.New("not found")).Once()
-		mockUserRepo.On("GetByUserID", ctx, "u1").Return(author, nil).Once()
-		mockTeamRepo.On("GetByID", ctx, int64(1)).Return(team, nil).Once()
-		mockPRRepo.On("Create", ctx, mock.AnythingOfType("*domain.PullRequest")).Run(func(args mock.Arguments) {
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(nil, errors.New("not found")).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockPRRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.PullRequest")).Run(func(args mock.Arguments) {
 			pr := args.Get(1).(*domain.PullRequest)
 			pr.ID = 1
 			pr.CreatedAt = time.Now()
 		}).Return(nil).Once()
-		mockUserRepo.On("GetByTeamID", ctx, int64(1)).Return(candidates, nil).Once()
-		mockPRRepo.On("AddReviewer", ctx, int64(1), int64(2)).Return(nil).Once()
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(createdPR, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(candidates, nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(2), domain.AssignReasonAuto).Return(nil).Once()
+		mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{}, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(createdPR, nil).Once()
 
 		result, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
 		assert.NoError(t, err)
@@ -223,6 +263,61 @@ func TestPRService_CreatePR(t *testing.T) {
 		mockUserRepo.AssertExpectations(t)
 		mockTeamRepo.AssertExpectations(t)
 	})
+
+	t.Run("missing slots are borrowed from buddy teams", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		author := &domain.User{ID: 1, UserID: "u1", Username: "Author", IsActive: true, TeamID: 1}
+		team := &domain.Team{ID: 1, Name: "tiny"}
+		ownMembers := []domain.User{
+			*author,
+			{ID: 2, UserID: "u2", Username: "Teammate", IsActive: true, TeamID: 1},
+		}
+		buddies := []domain.Team{
+			{ID: 2, Name: "empty"},
+			{ID: 3, Name: "backend"},
+		}
+		emptyMembers := []domain.User{
+			{ID: 4, UserID: "u4", Username: "Away", IsActive: false, TeamID: 2},
+		}
+		backendMembers := []domain.User{
+			{ID: 5, UserID: "u5", Username: "Helper", IsActive: true, TeamID: 3},
+		}
+		createdPR := &domain.PullRequest{
+			ID:            1,
+			PullRequestID: "pr-1",
+			AuthorID:      1,
+			StatusID:      StatusOpenID,
+			Assignments: []domain.ReviewerAssignment{
+				{UserID: "u2", TeamID: 1, Reason: domain.AssignReasonAuto},
+				{UserID: "u5", TeamID: 3, Reason: domain.AssignReasonBorrowed, Borrowed: true},
+			},
+		}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(nil, errors.New("not found")).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockPRRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.PullRequest")).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.PullRequest).ID = 1
+		}).Return(nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(ownMembers, nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(2), domain.AssignReasonAuto).Return(nil).Once()
+		mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return(buddies, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(2)).Return(emptyMembers, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(3)).Return(backendMembers, nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(5), domain.AssignReasonBorrowed).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(createdPR, nil).Once()
+
+		result, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
+		assert.NoError(t, err)
+		assert.True(t, result.Assignments[1].Borrowed)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+		mockTeamRepo.AssertExpectations(t)
+	})
 }
 
 func TestPRService_MergePR(t *testing.T) {
@@ -254,9 +349,9 @@ func TestPRService_MergePR(t *testing.T) {
 		now := time.Now()
 		mergedPR.MergedAt = &now
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(pr, nil).Once()
-		mockPRRepo.On("Update", ctx, mock.AnythingOfType("*domain.PullRequest")).Return(nil).Once()
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(mergedPR, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockPRRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.PullRequest")).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(mergedPR, nil).Once()
 
 		result, err := service.MergePR(ctx, "pr-1")
 		assert.NoError(t, err)
@@ -282,7 +377,7 @@ func TestPRService_MergePR(t *testing.T) {
 			CreatedAt:       time.Now(),
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(mergedPR, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(mergedPR, nil).Once()
 
 		result, err := service.MergePR(ctx, "pr-1")
 		assert.NoError(t, err)
@@ -298,7 +393,7 @@ func TestPRService_MergePR(t *testing.T) {
 		mockTeamRepo := new(MockTeamRepository)
 		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
 
-		mockPRRepo.On("GetByPRID", ctx, "non-existent").Return(nil, errors.New("not found")).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "non-existent").Return(nil, errors.New("not found")).Once()
 
 		_, err := service.MergePR(ctx, "non-existent")
 		assert.Error(t, err)
@@ -346,13 +441,17 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 			{ID: 3, UserID: "u3", Username: "New Reviewer", IsActive: true, TeamID: 1},
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(pr, nil).Once()
-		mockUserRepo.On("GetByUserID", ctx, "u2").Return(oldReviewer, nil).Once()
-		mockPRRepo.On("GetReviewers", ctx, int64(1)).Return(reviewers, nil).Once()
-		mockTeamRepo.On("GetByID", ctx, int64(1)).Return(team, nil).Once()
-		mockUserRepo.On("GetByTeamID", ctx, int64(1)).Return(candidates, nil).Once()
-		mockPRRepo.On("RemoveReviewer", ctx, int64(1), int64(2)).Return(nil).Once()
-		mockPRRepo.On("AddReviewer", ctx, int64(1), int64(3)).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(candidates, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(3), domain.AssignReasonReassign).Return(nil).Once()
+		mockPRRepo.On("GetAssignments", mock.Anything, int64(1)).Return([]domain.ReviewerAssignment{
+			{UserID: "u3", Username: "New Reviewer", TeamID: 1, Reason: domain.AssignReasonReassign},
+		}, nil).Once()
 
 		newUserID, err := service.ReassignReviewer(ctx, "pr-1", "u2")
 		assert.NoError(t, err)
@@ -368,7 +467,7 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 		mockTeamRepo := new(MockTeamRepository)
 		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
 
-		mockPRRepo.On("GetByPRID", ctx, "non-existent").Return(nil, errors.New("not found")).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "non-existent").Return(nil, errors.New("not found")).Once()
 
 		_, err := service.ReassignReviewer(ctx, "non-existent", "u2")
 		assert.Error(t, err)
@@ -393,7 +492,7 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 			CreatedAt:       time.Now(),
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(mergedPR, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(mergedPR, nil).Once()
 
 		_, err := service.ReassignReviewer(ctx, "pr-1", "u2")
 		assert.Error(t, err)
@@ -428,9 +527,9 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 			{ID: 3, UserID: "u3", Username: "Other Reviewer", IsActive: true, TeamID: 1},
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(pr, nil).Once()
-		mockUserRepo.On("GetByUserID", ctx, "u2").Return(oldReviewer, nil).Once()
-		mockPRRepo.On("GetReviewers", ctx, int64(1)).Return(reviewers, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
 
 		_, err := service.ReassignReviewer(ctx, "pr-1", "u2")
 		assert.Error(t, err)
@@ -471,11 +570,12 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 			{ID: 2, UserID: "u2", Username: "Old Reviewer", IsActive: true, TeamID: 1},
 		}
 
-		mockPRRepo.On("GetByPRID", ctx, "pr-1").Return(pr, nil).Once()
-		mockUserRepo.On("GetByUserID", ctx, "u2").Return(oldReviewer, nil).Once()
-		mockPRRepo.On("GetReviewers", ctx, int64(1)).Return(reviewers, nil).Once()
-		mockTeamRepo.On("GetByID", ctx, int64(1)).Return(team, nil).Once()
-		mockUserRepo.On("GetByTeamID", ctx, int64(1)).Return([]domain.User{}, nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return([]domain.User{}, nil).Once()
+		mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{}, nil).Once()
 
 		_, err := service.ReassignReviewer(ctx, "pr-1", "u2")
 		assert.Error(t, err)
@@ -484,5 +584,791 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 		mockUserRepo.AssertExpectations(t)
 		mockTeamRepo.AssertExpectations(t)
 	})
+
+	t.Run("replacement from buddy team", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, CreatedAt: time.Now()}
+		oldReviewer := &domain.User{ID: 2, UserID: "u2", Username: "Old Reviewer", IsActive: true, TeamID: 1}
+		team := &domain.Team{ID: 1, Name: "backend"}
+		alice := domain.User{ID: 5, UserID: "alice", Username: "Alice", IsActive: true, TeamID: 8}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return([]domain.User{*oldReviewer}, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		// В своей команде и в первой команде-партнере заменить некем
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return([]domain.User{*oldReviewer}, nil).Once()
+		mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{{ID: 7, Name: "frontend"}, {ID: 8, Name: "mobile"}}, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(7)).Return([]domain.User{}, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(8)).Return([]domain.User{alice}, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(5), domain.AssignReasonBorrowed).Return(nil).Once()
+		mockPRRepo.On("GetAssignments", mock.Anything, int64(1)).Return([]domain.ReviewerAssignment{
+			{UserID: "alice", Username: "Alice", TeamID: 8, Reason: domain.AssignReasonBorrowed},
+		}, nil).Once()
+
+		result, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", result.ReplacedBy)
+		assert.Equal(t, domain.AssignReasonBorrowed, result.Reviewers[0].Reason)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+		mockTeamRepo.AssertExpectations(t)
+	})
+}
+
+func TestPRService_AddReviewer(t *testing.T) {
+	ctx := context.Background()
+
+	openPR := func(reviewers ...domain.User) *domain.PullRequest {
+		return &domain.PullRequest{
+			ID:              1,
+			PullRequestID:   "pr-1",
+			PullRequestName: "Test PR",
+			AuthorID:        1,
+			StatusID:        StatusOpenID,
+			CreatedAt:       time.Now(),
+			Reviewers:       reviewers,
+		}
+	}
+	author := &domain.User{ID: 1, UserID: "u1", Username: "Author", IsActive: true, TeamID: 1}
+	team := &domain.Team{ID: 1, Name: "backend", MinReviewers: 1, MaxReviewers: 2}
+
+	t.Run("successful add", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		reviewer := &domain.User{ID: 3, UserID: "u3", Username: "Alice", IsActive: true, TeamID: 1}
+		updatedPR := openPR(domain.User{ID: 2, UserID: "u2"}, *reviewer)
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(openPR(domain.User{ID: 2, UserID: "u2"}), nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u3").Return(reviewer, nil).Once()
+		mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(3), domain.AssignReasonManual).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(updatedPR, nil).Once()
+
+		result, err := service.AddReviewer(ctx, "pr-1", "u3")
+		assert.NoError(t, err)
+		assert.Len(t, result.Reviewers, 2)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+		mockTeamRepo.AssertExpectations(t)
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		reviewer := &domain.User{ID: 3, UserID: "u3", Username: "Alice", IsActive: true, TeamID: 1}
+
+		tests := []struct {
+			name     string
+			ctx      context.Context
+			bumpErr  error
+			bumpCall bool
+		}{
+			// If-Match с устаревшей версией
+			{name: "stale if-match", ctx: ContextWithExpectedVersion(ctx, 5)},
+			// PR изменили между чтением и записью
+			{name: "concurrent change", ctx: ctx, bumpErr: storage.ErrVersionConflict, bumpCall: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockPRRepo := new(MockPRRepository)
+				mockUserRepo := new(MockUserRepository)
+				mockTeamRepo := new(MockTeamRepository)
+				service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+				pr := openPR(domain.User{ID: 2, UserID: "u2"})
+				pr.Version = 4
+				mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+				mockUserRepo.On("GetByUserID", mock.Anything, "u3").Return(reviewer, nil).Once()
+				mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+				mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+				if tt.bumpCall {
+					mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 4).Return(tt.bumpErr).Once()
+				}
+
+				_, err := service.AddReviewer(tt.ctx, "pr-1", "u3")
+				assert.ErrorIs(t, err, storage.ErrVersionConflict)
+				mockPRRepo.AssertNotCalled(t, "AddReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockPRRepo.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("author cannot be reviewer", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(openPR(), nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(author, nil).Once()
+
+		_, err := service.AddReviewer(ctx, "pr-1", "u1")
+		assert.Equal(t, storage.ErrReviewerIsAuthor, err)
+		mockPRRepo.AssertNotCalled(t, "AddReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("inactive reviewer", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		inactive := &domain.User{ID: 3, UserID: "u3", Username: "Alice", IsActive: false, TeamID: 1}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(openPR(), nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u3").Return(inactive, nil).Once()
+
+		_, err := service.AddReviewer(ctx, "pr-1", "u3")
+		assert.Equal(t, storage.ErrReviewerInactive, err)
+	})
+
+	t.Run("team limit reached", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		reviewer := &domain.User{ID: 4, UserID: "u4", Username: "Dave", IsActive: true, TeamID: 1}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(openPR(domain.User{ID: 2}, domain.User{ID: 3}), nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u4").Return(reviewer, nil).Once()
+		mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+
+		_, err := service.AddReviewer(ctx, "pr-1", "u4")
+		assert.Equal(t, storage.ErrReviewerLimit, err)
+		mockPRRepo.AssertNotCalled(t, "AddReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("merged PR", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		mergedPR := openPR()
+		mergedPR.StatusID = StatusMergedID
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(mergedPR, nil).Once()
+
+		_, err := service.AddReviewer(ctx, "pr-1", "u3")
+		assert.Equal(t, storage.ErrPRMerged, err)
+	})
+}
+
+func TestPRService_RemoveReviewer(t *testing.T) {
+	ctx := context.Background()
+
+	author := &domain.User{ID: 1, UserID: "u1", Username: "Author", IsActive: true, TeamID: 1}
+	team := &domain.Team{ID: 1, Name: "backend", MinReviewers: 1, MaxReviewers: 2}
+
+	t.Run("remove with backfill below minimum", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		reviewer := domain.User{ID: 2, UserID: "u2", Username: "Bob", IsActive: true, TeamID: 1}
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: []domain.User{reviewer}}
+		teamMembers := []domain.User{
+			*author,
+			reviewer,
+			{ID: 3, UserID: "u3", Username: "Carol", IsActive: true, TeamID: 1},
+			{ID: 4, UserID: "u4", Username: "Dave", IsActive: false, TeamID: 1},
+		}
+		updatedPR := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: []domain.User{teamMembers[2]}}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Once()
+		mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(teamMembers, nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(3), domain.AssignReasonBackfill).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(updatedPR, nil).Once()
+
+		result, err := service.RemoveReviewer(ctx, "pr-1", "u2")
+		assert.NoError(t, err)
+		assert.Equal(t, "u3", result.Reviewers[0].UserID)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+		mockTeamRepo.AssertExpectations(t)
+	})
+
+	t.Run("remove without backfill", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		reviewers := []domain.User{
+			{ID: 2, UserID: "u2", Username: "Bob", IsActive: true, TeamID: 1},
+			{ID: 3, UserID: "u3", Username: "Carol", IsActive: true, TeamID: 1},
+		}
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: reviewers}
+		updatedPR := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: reviewers[1:]}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewers[0], nil).Once()
+		mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(updatedPR, nil).Once()
+
+		result, err := service.RemoveReviewer(ctx, "pr-1", "u2")
+		assert.NoError(t, err)
+		assert.Len(t, result.Reviewers, 1)
+		mockUserRepo.AssertNotCalled(t, "GetByTeamID", mock.Anything, mock.Anything)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("no backfill candidate", func(t *testing.T) {
+		for _, allowShortfall := range []bool{false, true} {
+			mockPRRepo := new(MockPRRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockTeamRepo := new(MockTeamRepository)
+			tx := &recordingTx{}
+			service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo, WithTransactor(tx))
+
+			reviewer := domain.User{ID: 2, UserID: "u2", Username: "Bob", IsActive: true, TeamID: 1}
+			pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: []domain.User{reviewer}}
+			updatedPR := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID}
+
+			mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+			mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Once()
+			mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+			mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+			mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+			mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+			mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return([]domain.User{*author, reviewer}, nil).Once()
+			mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{}, nil).Once()
+			mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(updatedPR, nil).Maybe()
+
+			result, err := service.RemoveReviewerWithOptions(ctx, "pr-1", "u2", RemoveOptions{AllowShortfall: allowShortfall})
+			if allowShortfall {
+				assert.NoError(t, err)
+				assert.Empty(t, result.Reviewers)
+			} else {
+				// Снятие откатывается вместе с транзакцией
+				assert.ErrorIs(t, err, storage.ErrNoCandidate)
+				assert.Equal(t, []error{err}, tx.results)
+			}
+			assert.Len(t, tx.results, 1)
+			mockPRRepo.AssertExpectations(t)
+		}
+	})
+
+	t.Run("reviewer not assigned", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID}
+		other := &domain.User{ID: 5, UserID: "u5", IsActive: true, TeamID: 1}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u5").Return(other, nil).Once()
+
+		_, err := service.RemoveReviewer(ctx, "pr-1", "u5")
+		assert.Equal(t, storage.ErrNotAssigned, err)
+	})
+}
+
+// recordingTx выполняет fn без транзакции и запоминает результат каждого вызова
+type recordingTx struct {
+	results []error
+}
+
+func (tx *recordingTx) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	tx.results = append(tx.results, err)
+	return err
+}
+
+func TestPRService_ReassignReviewerWithOptions(t *testing.T) {
+	ctx := context.Background()
+
+	pr := &domain.PullRequest{
+		ID:              1,
+		PullRequestID:   "pr-1",
+		PullRequestName: "Test PR",
+		AuthorID:        1,
+		StatusID:        StatusOpenID,
+		CreatedAt:       time.Now(),
+	}
+	oldReviewer := &domain.User{ID: 2, UserID: "u2", Username: "Old Reviewer", IsActive: true, TeamID: 1}
+	team := &domain.Team{ID: 1, Name: "backend"}
+	reviewers := []domain.User{*oldReviewer}
+
+	t.Run("requested replacement", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		tx := &recordingTx{}
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo, WithTransactor(tx))
+
+		alice := &domain.User{ID: 5, UserID: "alice", Username: "Alice", IsActive: true, TeamID: 7}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "alice").Return(alice, nil).Once()
+		mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{{ID: 7, Name: "frontend"}}, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(5), domain.AssignReasonRequested).Return(nil).Once()
+		mockPRRepo.On("GetAssignments", mock.Anything, int64(1)).Return([]domain.ReviewerAssignment{
+			{UserID: "alice", Username: "Alice", TeamID: 7, Reason: domain.AssignReasonRequested},
+		}, nil).Once()
+
+		result, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{NewReviewerID: "alice"})
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", result.ReplacedBy)
+		assert.Equal(t, domain.AssignReasonRequested, result.Reviewers[0].Reason)
+		assert.Equal(t, []error{nil}, tx.results)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("requested replacement outside the team and its buddies", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		alice := &domain.User{ID: 5, UserID: "alice", Username: "Alice", IsActive: true, TeamID: 9}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "alice").Return(alice, nil).Once()
+		mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{{ID: 7, Name: "frontend"}}, nil).Once()
+
+		_, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{NewReviewerID: "alice"})
+		assert.ErrorIs(t, err, storage.ErrNoCandidate)
+		mockPRRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requested replacement is excluded", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		alice := &domain.User{ID: 5, UserID: "alice", Username: "Alice", IsActive: true, TeamID: 1}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "alice").Return(alice, nil).Once()
+
+		_, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{NewReviewerID: "alice", Exclude: []string{"alice"}})
+		assert.ErrorIs(t, err, storage.ErrNoCandidate)
+		mockPRRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requested replacement is inactive", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		alice := &domain.User{ID: 5, UserID: "alice", Username: "Alice", IsActive: false, TeamID: 1}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "alice").Return(alice, nil).Once()
+
+		_, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{NewReviewerID: "alice"})
+		assert.Equal(t, storage.ErrReviewerInactive, err)
+		mockPRRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("preferred candidate wins", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		members := []domain.User{
+			*oldReviewer,
+			{ID: 3, UserID: "u3", Username: "Carol", IsActive: true, TeamID: 1},
+			{ID: 4, UserID: "u4", Username: "Dave", IsActive: true, TeamID: 1},
+		}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(members, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(4), domain.AssignReasonPreferred).Return(nil).Once()
+		mockPRRepo.On("GetAssignments", mock.Anything, int64(1)).Return([]domain.ReviewerAssignment{}, nil).Once()
+
+		result, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{Prefer: []string{"u4"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "u4", result.ReplacedBy)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("excluded candidates leave no replacement", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		other := &domain.Team{ID: 2, Name: "frontend"}
+		members := []domain.User{
+			{ID: 3, UserID: "u3", Username: "Carol", IsActive: true, TeamID: 2},
+		}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(oldReviewer, nil).Once()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(reviewers, nil).Once()
+		mockTeamRepo.On("GetByName", mock.Anything, "frontend").Return(other, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(2)).Return(members, nil).Once()
+
+		_, err := service.ReassignReviewerWithOptions(ctx, "pr-1", "u2", ReassignOptions{
+			TeamName: "frontend",
+			Exclude:  []string{"u3"},
+		})
+		assert.Equal(t, storage.ErrNoCandidate, err)
+		mockTeamRepo.AssertExpectations(t)
+	})
+}
+
+func TestPRService_MergePRUsesClock(t *testing.T) {
+	ctx := context.Background()
+
+	mergedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockPRRepo := new(MockPRRepository)
+	service := NewPRService(mockPRRepo, new(MockUserRepository), new(MockTeamRepository),
+		WithClock(func() time.Time { return mergedAt }),
+	)
+
+	pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID}
+
+	mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+	mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(pr *domain.PullRequest) bool {
+		return pr.MergedAt != nil && pr.MergedAt.Equal(mergedAt)
+	})).Return(nil).Once()
+	mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+
+	_, err := service.MergePR(ctx, "pr-1")
+	assert.NoError(t, err)
+	mockPRRepo.AssertExpectations(t)
+}
+
+func TestPRService_MarkReviewed(t *testing.T) {
+	ctx := context.Background()
+
+	reviewedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	reviewer := domain.User{ID: 2, UserID: "u2", Username: "Bob", IsActive: true, TeamID: 1}
+
+	t.Run("review is marked with the service clock", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, new(MockTeamRepository),
+			WithClock(func() time.Time { return reviewedAt }),
+		)
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: []domain.User{reviewer}}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Twice()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("MarkReviewed", mock.Anything, int64(1), int64(2), reviewedAt).Return(nil).Once()
+
+		_, err := service.MarkReviewed(ctx, "pr-1", "u2")
+		assert.NoError(t, err)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("reviewer not assigned", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, new(MockTeamRepository))
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Once()
+
+		_, err := service.MarkReviewed(ctx, "pr-1", "u2")
+		assert.Equal(t, storage.ErrNotAssigned, err)
+		mockPRRepo.AssertNotCalled(t, "MarkReviewed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPRService_EscalateReview(t *testing.T) {
+	ctx := context.Background()
+
+	author := &domain.User{ID: 1, UserID: "u1", Username: "Author", IsActive: true, TeamID: 1}
+	reviewer := domain.User{ID: 2, UserID: "u2", Username: "Bob", IsActive: true, TeamID: 1}
+	team := &domain.Team{ID: 1, Name: "backend", MinReviewers: 1, MaxReviewers: 1}
+
+	t.Run("team lead is added beyond the limit", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: []domain.User{reviewer}}
+		members := []domain.User{
+			*author,
+			reviewer,
+			{ID: 3, UserID: "u3", Username: "Inactive Lead", IsActive: false, TeamID: 1, Role: domain.RoleLead},
+			{ID: 4, UserID: "u4", Username: "Lead", IsActive: true, TeamID: 1, Role: domain.RoleLead},
+		}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Once()
+		mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(members, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(4), domain.AssignReasonEscalated).Return(nil).Once()
+
+		result, err := service.EscalateReview(ctx, "pr-1", "u2")
+		assert.NoError(t, err)
+		assert.Equal(t, &EscalationResult{Action: domain.EscalationLeadAdded, TargetUserID: "u4"}, result)
+		mockPRRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("reassigns when the team has no lead", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID, Reviewers: []domain.User{reviewer}}
+		members := []domain.User{
+			*author,
+			reviewer,
+			{ID: 3, UserID: "u3", Username: "Carol", IsActive: true, TeamID: 1},
+		}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Twice()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Twice()
+		mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(author, nil).Once()
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Twice()
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(members, nil).Twice()
+		mockPRRepo.On("GetReviewers", mock.Anything, int64(1)).Return(pr.Reviewers, nil).Once()
+		mockPRRepo.On("BumpVersion", mock.Anything, int64(1), 0).Return(nil).Once()
+		mockPRRepo.On("RemoveReviewer", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+		mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(3), domain.AssignReasonReassign).Return(nil).Once()
+		mockPRRepo.On("GetAssignments", mock.Anything, int64(1)).Return([]domain.ReviewerAssignment{
+			{UserID: "u3", Username: "Carol", TeamID: 1, Reason: domain.AssignReasonReassign},
+		}, nil).Once()
+
+		result, err := service.EscalateReview(ctx, "pr-1", "u2")
+		assert.NoError(t, err)
+		assert.Equal(t, &EscalationResult{Action: domain.EscalationReassigned, TargetUserID: "u3"}, result)
+		mockPRRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("reviewer not assigned", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+		service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo)
+
+		pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1, StatusID: StatusOpenID}
+
+		mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(pr, nil).Once()
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(&reviewer, nil).Once()
+
+		_, err := service.EscalateReview(ctx, "pr-1", "u2")
+		assert.Equal(t, storage.ErrNotAssigned, err)
+	})
 }
 
+func TestPRService_ListPRs(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	prs := []domain.PullRequest{
+		{ID: 3, PullRequestID: "pr-3", CreatedAt: created.Add(2 * time.Minute)},
+		{ID: 2, PullRequestID: "pr-2", CreatedAt: created.Add(time.Minute)},
+		{ID: 1, PullRequestID: "pr-1", CreatedAt: created},
+	}
+
+	t.Run("next cursor points after the last PR of the page", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		service := NewPRService(mockPRRepo, nil, nil)
+
+		mockPRRepo.On("List", mock.Anything, domain.PRFilter{AuthorID: "u1", Limit: 2}).Return(prs, nil).Once()
+
+		page, err := service.ListPRs(ctx, domain.PRFilter{AuthorID: "u1", Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, page.PullRequests, 2)
+
+		cursor, err := domain.ParsePRCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.PRCursor{CreatedAt: prs[1].CreatedAt, ID: 2}, cursor)
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		service := NewPRService(mockPRRepo, nil, nil)
+
+		mockPRRepo.On("List", mock.Anything, domain.PRFilter{Limit: DefaultPageLimit}).Return(prs, nil).Once()
+
+		page, err := service.ListPRs(ctx, domain.PRFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, page.PullRequests, 3)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		service := NewPRService(mockPRRepo, nil, nil)
+
+		mockPRRepo.On("List", mock.Anything, domain.PRFilter{Limit: MaxPageLimit}).Return([]domain.PullRequest{}, nil).Once()
+
+		_, err := service.ListPRs(ctx, domain.PRFilter{Limit: 1000})
+		assert.NoError(t, err)
+		mockPRRepo.AssertExpectations(t)
+	})
+}
+
+func TestPRService_CreatePRBatch(t *testing.T) {
+	ctx := context.Background()
+
+	author := &domain.User{ID: 1, UserID: "u1", Username: "Author", IsActive: true, TeamID: 1}
+	team := &domain.Team{ID: 1, Name: "backend"}
+	members := []domain.User{
+		*author,
+		{ID: 2, UserID: "u2", Username: "Reviewer1", IsActive: true, TeamID: 1},
+		{ID: 3, UserID: "u3", Username: "Reviewer2", IsActive: true, TeamID: 1},
+		{ID: 4, UserID: "u4", Username: "Reviewer3", IsActive: true, TeamID: 1},
+		{ID: 5, UserID: "u5", Username: "Reviewer4", IsActive: true, TeamID: 1},
+	}
+
+	newService := func(opts ...PRServiceOption) (*PRService, *MockPRRepository) {
+		mockPRRepo := new(MockPRRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockTeamRepo := new(MockTeamRepository)
+
+		mockPRRepo.On("GetByPRID", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(author, nil)
+		mockUserRepo.On("GetByUserID", mock.Anything, "ghost").Return(nil, storage.ErrNotFound)
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil)
+		mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(members, nil)
+
+		return NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo, opts...), mockPRRepo
+	}
+
+	batch := func(ids ...string) []NewPR {
+		prs := make([]NewPR, 0, len(ids))
+		for _, id := range ids {
+			prs = append(prs, NewPR{PRID: id, PRName: "PR " + id, AuthorUserID: "u1"})
+		}
+		return prs
+	}
+
+	t.Run("reviewers are spread across the batch", func(t *testing.T) {
+		service, mockPRRepo := newService()
+		mockPRRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(nil).Times(4)
+
+		result, err := service.CreatePRBatch(ctx, batch("pr-1", "pr-2", "pr-3", "pr-4"), false)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, result.Created)
+		assert.Equal(t, 0, result.Failed)
+
+		load := make(map[string]int)
+		for _, item := range result.Items {
+			assert.Equal(t, BatchItemCreated, item.Status)
+			assert.Len(t, item.PR.Reviewers, MaxReviewers)
+			assert.Equal(t, author, item.PR.Author)
+			assert.Len(t, item.PR.Assignments, MaxReviewers)
+			for i, reviewer := range item.PR.Reviewers {
+				load[reviewer.UserID]++
+				assert.Equal(t, reviewer.UserID, item.PR.Assignments[i].UserID)
+				assert.Equal(t, domain.AssignReasonAuto, item.PR.Assignments[i].Reason)
+			}
+		}
+		assert.Equal(t, map[string]int{"u2": 2, "u3": 2, "u4": 2, "u5": 2}, load)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed item does not stop the others", func(t *testing.T) {
+		service, mockPRRepo := newService()
+		mockPRRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		prs := batch("pr-1", "pr-1", "pr-2")
+		prs = append(prs, NewPR{PRID: "pr-3", PRName: "PR pr-3", AuthorUserID: "ghost"})
+
+		result, err := service.CreatePRBatch(ctx, prs, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Created)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, BatchItemCreated, result.Items[0].Status)
+		assert.Equal(t, BatchItemFailed, result.Items[1].Status)
+		assert.ErrorIs(t, result.Items[1].Err, storage.ErrPRExists)
+		assert.Equal(t, BatchItemCreated, result.Items[2].Status)
+		assert.ErrorIs(t, result.Items[3].Err, storage.ErrNotFound)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("atomic batch is saved in one transaction", func(t *testing.T) {
+		service, mockPRRepo := newService()
+		mockPRRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(prs []domain.PlannedPR) bool {
+			return len(prs) == 2
+		})).Return(nil).Once()
+
+		result, err := service.CreatePRBatch(ctx, batch("pr-1", "pr-2"), true)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Created)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("atomic batch with a failed item is not saved", func(t *testing.T) {
+		service, mockPRRepo := newService()
+
+		prs := batch("pr-1")
+		prs = append(prs, NewPR{PRID: "pr-2", PRName: "PR pr-2", AuthorUserID: "ghost"})
+
+		result, err := service.CreatePRBatch(ctx, prs, true)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, BatchItemSkipped, result.Items[0].Status)
+		assert.Equal(t, BatchItemFailed, result.Items[1].Status)
+		mockPRRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic batch fails as a whole on storage error", func(t *testing.T) {
+		service, mockPRRepo := newService()
+		mockPRRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(storage.ErrPRExists).Once()
+
+		_, err := service.CreatePRBatch(ctx, batch("pr-1", "pr-2"), true)
+		assert.ErrorIs(t, err, storage.ErrPRExists)
+	})
+
+	t.Run("too many PRs", func(t *testing.T) {
+		service, mockPRRepo := newService(WithMaxBatchPRs(1))
+
+		_, err := service.CreatePRBatch(ctx, batch("pr-1", "pr-2"), false)
+		assert.ErrorIs(t, err, storage.ErrTooManyPRs)
+		mockPRRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"sort"
+	"time"
+)
+
+// Стратегии выбора ревьюверов
+const (
+	StrategyRandom   = "random"
+	StrategyRotation = "rotation"
+
+	DefaultRotationLookback = 30 * 24 * time.Hour
+)
+
+// OutcomeNoCandidate - исход выбора, при котором для свободного места
+// ревьювера не нашлось ни одного кандидата
+const OutcomeNoCandidate = "NO_CANDIDATE"
+
+// AssignmentObserver получает исходы автоматического выбора ревьюверов:
+// причину назначения (AUTO, BORROWED, REASSIGN, PREFERRED) или
+// OutcomeNoCandidate и число мест с таким исходом
+type AssignmentObserver interface {
+	ObserveAssignment(strategy, outcome string, count int)
+}
+
+// PRServiceOption настраивает PRService
+type PRServiceOption func(*PRService)
+
+// WithRotation включает стратегию ротации: ревьюверы, которые недавно
+// (в пределах lookback) смотрели PR того же автора, выбираются реже
+func WithRotation(lookback time.Duration) PRServiceOption {
+	return func(s *PRService) {
+		s.strategy = StrategyRotation
+		WithRotationLookback(lookback)(s)
+	}
+}
+
+// WithRotationLookback задает окно истории назначений "автор -> ревьювер"
+// без смены стратегии: его использует ротация и /stats/pairs по умолчанию
+func WithRotationLookback(lookback time.Duration) PRServiceOption {
+	return func(s *PRService) {
+		if lookback <= 0 {
+			lookback = DefaultRotationLookback
+		}
+		s.rotationLookback = lookback
+	}
+}
+
+// RotationLookback возвращает окно истории назначений "автор -> ревьювер"
+func (s *PRService) RotationLookback() time.Duration {
+	return s.rotationLookback
+}
+
+// WithTransactor выполняет изменения ревьюверов PR в транзакциях tx: снятие,
+// замена и добор ревьюверов применяются вместе или не применяются вовсе
+func WithTransactor(tx storage.Transactor) PRServiceOption {
+	return func(s *PRService) {
+		s.tx = tx
+	}
+}
+
+// noTx выполняет fn без транзакции; используется, пока транзакции не заданы
+type noTx struct{}
+
+func (noTx) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// WithRand задает источник случайности для выбора ревьюверов
+func WithRand(r *rand.Rand) PRServiceOption {
+	return func(s *PRService) {
+		s.rnd = r
+	}
+}
+
+// WithClock задает источник текущего времени
+func WithClock(now func() time.Time) PRServiceOption {
+	return func(s *PRService) {
+		s.now = now
+	}
+}
+
+// WithAssignmentObserver подключает наблюдателя за исходами выбора ревьюверов
+func WithAssignmentObserver(o AssignmentObserver) PRServiceOption {
+	return func(s *PRService) {
+		s.observer = o
+	}
+}
+
+func (s *PRService) observe(outcome string, count int) {
+	if s.observer == nil || count <= 0 {
+		return
+	}
+	s.observer.ObserveAssignment(s.strategy, outcome, count)
+}
+
+// WithDeterministic включает детерминированный режим: случайность для
+// каждого выбора выводится из хеша ID PR и множества кандидатов, поэтому
+// повтор с теми же входными данными дает тех же ревьюверов
+func WithDeterministic() PRServiceOption {
+	return func(s *PRService) {
+		s.deterministic = true
+	}
+}
+
+// selectReviewers выбирает до count ревьюверов для PR согласно настроенной
+// стратегии
+func (s *PRService) selectReviewers(ctx context.Context, pr *domain.PullRequest, candidates []domain.User, count int) ([]domain.User, error) {
+	if count <= 0 || len(candidates) == 0 {
+		return []domain.User{}, nil
+	}
+
+	if count >= len(candidates) {
+		return candidates, nil
+	}
+
+	if s.strategy != StrategyRotation {
+		return s.selectRandomReviewers(pr.PullRequestID, candidates, count), nil
+	}
+
+	since := s.now().Add(-s.rotationLookback)
+	pairCounts, err := s.prRepo.GetPairCounts(ctx, pr.AuthorID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review history: %w", err)
+	}
+
+	var selected []domain.User
+	s.withRand(pr.PullRequestID, candidates, func(r *rand.Rand, ordered []domain.User) {
+		weights := make([]float64, len(ordered))
+		for i, candidate := range ordered {
+			weights[i] = 1 / float64(1+pairCounts[candidate.ID])
+		}
+		selected = weightedSample(r, ordered, weights, count)
+	})
+
+	return selected, nil
+}
+
+func (s *PRService) selectRandomReviewers(prID string, candidates []domain.User, count int) []domain.User {
+	if count <= 0 || len(candidates) == 0 {
+		return []domain.User{}
+	}
+
+	if count >= len(candidates) {
+		return candidates
+	}
+
+	var shuffled []domain.User
+	s.withRand(prID, candidates, func(r *rand.Rand, ordered []domain.User) {
+		shuffled = ordered
+		for i := len(shuffled) - 1; i > 0; i-- {
+			j := r.Intn(i + 1)
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		}
+	})
+
+	return shuffled[:count]
+}
+
+// withRand вызывает fn с источником случайности и копией кандидатов.
+// В детерминированном режиме кандидаты упорядочиваются по user_id, а
+// источник инициализируется хешем ID PR и кандидатов; иначе используется
+// общий источник сервиса.
+func (s *PRService) withRand(prID string, candidates []domain.User, fn func(r *rand.Rand, ordered []domain.User)) {
+	ordered := make([]domain.User, len(candidates))
+	copy(ordered, candidates)
+
+	if s.deterministic {
+		sort.Slice(ordered, func(i, j int) bool {
+			return ordered[i].UserID < ordered[j].UserID
+		})
+		fn(rand.New(rand.NewSource(selectionSeed(prID, ordered))), ordered)
+		return
+	}
+
+	s.rndMu.Lock()
+	defer s.rndMu.Unlock()
+	fn(s.rnd, ordered)
+}
+
+// selectionSeed хеширует ID PR и упорядоченный список кандидатов
+func selectionSeed(prID string, ordered []domain.User) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(prID))
+	for _, candidate := range ordered {
+		h.Write([]byte{0})
+		h.Write([]byte(candidate.UserID))
+	}
+	return int64(h.Sum64())
+}
+
+// weightedSample выбирает count кандидатов без повторов с вероятностью,
+// пропорциональной весу
+func weightedSample(r *rand.Rand, candidates []domain.User, weights []float64, count int) []domain.User {
+	pool := make([]domain.User, len(candidates))
+	copy(pool, candidates)
+	w := make([]float64, len(weights))
+	copy(w, weights)
+
+	selected := make([]domain.User, 0, count)
+	for len(selected) < count && len(pool) > 0 {
+		var total float64
+		for _, weight := range w {
+			total += weight
+		}
+
+		target := r.Float64() * total
+		idx := len(pool) - 1
+		for i, weight := range w {
+			if target < weight {
+				idx = i
+				break
+			}
+			target -= weight
+		}
+
+		selected = append(selected, pool[idx])
+		pool = append(pool[:idx], pool[idx+1:]...)
+		w = append(w[:idx], w[idx+1:]...)
+	}
+
+	return selected
+}
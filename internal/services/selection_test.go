@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reviewer-appointment-service/internal/models/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWeightedSample(t *testing.T) {
+	candidates := []domain.User{
+		{ID: 1, UserID: "u1"},
+		{ID: 2, UserID: "u2"},
+		{ID: 3, UserID: "u3"},
+	}
+
+	t.Run("zero weight is picked only when nothing else is left", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			selected := weightedSample(r, candidates, []float64{1, 0, 1}, 2)
+			assert.Len(t, selected, 2)
+			for _, u := range selected {
+				assert.NotEqual(t, "u2", u.UserID)
+			}
+		}
+	})
+
+	t.Run("no duplicates", func(t *testing.T) {
+		r := rand.New(rand.NewSource(2))
+		selected := weightedSample(r, candidates, []float64{1, 1, 1}, 3)
+		assert.ElementsMatch(t, candidates, selected)
+	})
+}
+
+func TestPRService_SelectReviewersRotation(t *testing.T) {
+	ctx := context.Background()
+
+	candidates := []domain.User{
+		{ID: 2, UserID: "u2", IsActive: true},
+		{ID: 3, UserID: "u3", IsActive: true},
+		{ID: 4, UserID: "u4", IsActive: true},
+	}
+	pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1}
+
+	t.Run("history is read within lookback window", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		service := NewPRService(mockPRRepo, new(MockUserRepository), new(MockTeamRepository),
+			WithRotation(7*24*time.Hour),
+			WithClock(func() time.Time { return now }),
+		)
+
+		mockPRRepo.On("GetPairCounts", mock.Anything, int64(1), now.Add(-7*24*time.Hour)).Return(map[int64]int{2: 5, 3: 1}, nil).Once()
+
+		selected, err := service.selectReviewers(ctx, pr, candidates, 2)
+		assert.NoError(t, err)
+		assert.Len(t, selected, 2)
+		mockPRRepo.AssertExpectations(t)
+	})
+
+	t.Run("random strategy ignores history", func(t *testing.T) {
+		mockPRRepo := new(MockPRRepository)
+		service := NewPRService(mockPRRepo, new(MockUserRepository), new(MockTeamRepository))
+
+		selected, err := service.selectReviewers(ctx, pr, candidates, 2)
+		assert.NoError(t, err)
+		assert.Len(t, selected, 2)
+		mockPRRepo.AssertNotCalled(t, "GetPairCounts", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPRService_DeterministicSelection(t *testing.T) {
+	ctx := context.Background()
+
+	candidates := []domain.User{
+		{ID: 2, UserID: "u2", IsActive: true},
+		{ID: 3, UserID: "u3", IsActive: true},
+		{ID: 4, UserID: "u4", IsActive: true},
+		{ID: 5, UserID: "u5", IsActive: true},
+	}
+	reversed := []domain.User{candidates[3], candidates[2], candidates[1], candidates[0]}
+	pr := &domain.PullRequest{ID: 1, PullRequestID: "pr-1", AuthorID: 1}
+
+	newService := func() *PRService {
+		return NewPRService(new(MockPRRepository), new(MockUserRepository), new(MockTeamRepository), WithDeterministic())
+	}
+
+	t.Run("same inputs give same reviewers", func(t *testing.T) {
+		first, err := newService().selectReviewers(ctx, pr, candidates, 2)
+		assert.NoError(t, err)
+
+		second, err := newService().selectReviewers(ctx, pr, reversed, 2)
+		assert.NoError(t, err)
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("exact outcome is stable", func(t *testing.T) {
+		selected, err := newService().selectReviewers(ctx, pr, candidates, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u3", "u5"}, []string{selected[0].UserID, selected[1].UserID})
+	})
+
+	t.Run("injected rand source is reproducible", func(t *testing.T) {
+		a := NewPRService(new(MockPRRepository), new(MockUserRepository), new(MockTeamRepository), WithRand(rand.New(rand.NewSource(42))))
+		b := NewPRService(new(MockPRRepository), new(MockUserRepository), new(MockTeamRepository), WithRand(rand.New(rand.NewSource(42))))
+
+		for i := 0; i < 5; i++ {
+			fromA, _ := a.selectReviewers(ctx, pr, candidates, 2)
+			fromB, _ := b.selectReviewers(ctx, pr, candidates, 2)
+			assert.Equal(t, fromA, fromB)
+		}
+	})
+}
+
+type recordingObserver struct {
+	counts map[string]int
+}
+
+func (o *recordingObserver) ObserveAssignment(strategy, outcome string, count int) {
+	o.counts[strategy+"/"+outcome] += count
+}
+
+func TestPRService_AssignmentObserver(t *testing.T) {
+	ctx := context.Background()
+
+	mockPRRepo := new(MockPRRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockTeamRepo := new(MockTeamRepository)
+	observer := &recordingObserver{counts: map[string]int{}}
+	service := NewPRService(mockPRRepo, mockUserRepo, mockTeamRepo, WithAssignmentObserver(observer))
+
+	author := &domain.User{ID: 1, UserID: "u1", IsActive: true, TeamID: 1}
+	team := &domain.Team{ID: 1, Name: "backend"}
+	members := []domain.User{
+		*author,
+		{ID: 2, UserID: "u2", IsActive: true, TeamID: 1},
+	}
+
+	mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(nil, errors.New("not found")).Once()
+	mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(author, nil).Once()
+	mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil).Once()
+	mockPRRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.PullRequest")).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.PullRequest).ID = 1
+	}).Return(nil).Once()
+	mockUserRepo.On("GetByTeamID", mock.Anything, int64(1)).Return(members, nil).Once()
+	mockPRRepo.On("AddReviewer", mock.Anything, int64(1), int64(2), domain.AssignReasonAuto).Return(nil).Once()
+	mockTeamRepo.On("GetBuddyTeams", mock.Anything, int64(1)).Return([]domain.Team{}, nil).Once()
+	mockPRRepo.On("GetByPRID", mock.Anything, "pr-1").Return(&domain.PullRequest{ID: 1, PullRequestID: "pr-1"}, nil).Once()
+
+	_, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		StrategyRandom + "/" + domain.AssignReasonAuto: 1,
+		StrategyRandom + "/" + OutcomeNoCandidate:      1,
+	}, observer.counts)
+	mockPRRepo.AssertExpectations(t)
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReviewReassigner переназначает ревью участников, которые после
+// синхронизации перестают быть активными. Реализуется PRService.
+type ReviewReassigner interface {
+	ReassignReviewerWithOptions(ctx context.Context, prID, oldUserID string, opts ReassignOptions) (*ReassignResult, error)
+	RemoveReviewerWithOptions(ctx context.Context, prID, reviewerUserID string, opts RemoveOptions) (*domain.PullRequest, error)
+}
+
+// WithSync включает синхронизацию команд с документом: изменения
+// применяются в одной транзакции tx, а открытые ревью деактивированных
+// участников переназначает reviews
+func WithSync(tx storage.Transactor, reviews ReviewReassigner) TeamServiceOption {
+	return func(s *TeamService) {
+		s.tx = tx
+		s.reviews = reviews
+	}
+}
+
+// ParseTeamsDocument разбирает документ синхронизации в YAML или JSON.
+// Неизвестные поля - ошибка, чтобы опечатка в файле не терялась молча.
+func ParseTeamsDocument(data []byte) (*domain.TeamsDocument, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var doc domain.TeamsDocument
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", storage.ErrInvalidSyncDocument, err)
+	}
+	return &doc, nil
+}
+
+// syncTeam - команда документа и ее текущее состояние
+type syncTeam struct {
+	desired        domain.Team
+	current        *domain.Team // nil - команда будет создана
+	currentBuddies []string
+}
+
+// syncUser - участник документа и его текущее состояние
+type syncUser struct {
+	desired     domain.User
+	team        string
+	current     *domain.User // nil - участник будет создан
+	currentTeam string
+}
+
+// syncState - документ, сопоставленный с БД. deactivate - активные
+// участники, которых нет в документе.
+type syncState struct {
+	teams      []*syncTeam
+	users      []*syncUser
+	deactivate []domain.User
+	teamNames  map[int64]string
+}
+
+// Sync приводит команды и участников организации к документу: создает и
+// меняет команды, создает, меняет и переводит участников между командами,
+// деактивирует активных участников, которых нет в документе, и
+// переназначает их открытые ревью. Все изменения выполняются в одной
+// транзакции. С dryRun возвращает план без изменений.
+func (s *TeamService) Sync(ctx context.Context, doc *domain.TeamsDocument, dryRun bool) (_ *domain.SyncPlan, err error) {
+	ctx, span := tracer.Start(ctx, "TeamService.Sync")
+	defer func() { endSpan(span, err) }()
+
+	if s.tx == nil || s.reviews == nil {
+		return nil, fmt.Errorf("team sync is not configured")
+	}
+
+	// If-Match относится к отдельному ресурсу, а синхронизация меняет
+	// многие ресурсы сразу
+	ctx = WithoutExpectedVersion(ctx)
+
+	var plan *domain.SyncPlan
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		state, err := s.loadSyncState(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		plan, err = s.planSync(ctx, state)
+		if err != nil {
+			return err
+		}
+		plan.DryRun = dryRun
+
+		if dryRun {
+			return nil
+		}
+		return s.applySync(ctx, state, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("teams synced", "dry_run", dryRun,
+		"teams", len(plan.Teams), "users", len(plan.Users), "reassignments", len(plan.Reassignments))
+
+	return plan, nil
+}
+
+// loadSyncState проверяет документ и сопоставляет его команды и участников
+// с БД
+func (s *TeamService) loadSyncState(ctx context.Context, doc *domain.TeamsDocument) (*syncState, error) {
+	if len(doc.Teams) == 0 {
+		return nil, fmt.Errorf("%w: no teams", storage.ErrInvalidSyncDocument)
+	}
+
+	existing, err := s.teamRepo.GetAllWithUsers(ctx, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	byName := make(map[string]*domain.Team, len(existing))
+	state := &syncState{teamNames: make(map[int64]string, len(existing))}
+	for i := range existing {
+		byName[existing[i].Name] = &existing[i]
+		state.teamNames[existing[i].ID] = existing[i].Name
+	}
+
+	docTeams := make(map[string]bool, len(doc.Teams))
+	docUsers := make(map[string]bool)
+	for _, spec := range doc.Teams {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("%w: team without name", storage.ErrInvalidSyncDocument)
+		}
+		if docTeams[spec.Name] {
+			return nil, fmt.Errorf("%w: team %s is listed twice", storage.ErrInvalidSyncDocument, spec.Name)
+		}
+		docTeams[spec.Name] = true
+
+		if s.maxMembers > 0 && len(spec.Members) > s.maxMembers {
+			return nil, fmt.Errorf("%w: team %s: %d > %d", storage.ErrTooManyMembers, spec.Name, len(spec.Members), s.maxMembers)
+		}
+
+		team := &syncTeam{desired: domain.Team{
+			Name:                   spec.Name,
+			MinReviewers:           spec.MinReviewers,
+			MaxReviewers:           spec.MaxReviewers,
+			ReminderAfterMinutes:   spec.ReminderAfterMinutes,
+			EscalationAfterMinutes: spec.EscalationAfterMinutes,
+			BuddyTeams:             spec.BuddyTeams,
+		}}
+		for _, member := range spec.Members {
+			if member.UserID == "" || member.Username == "" {
+				return nil, fmt.Errorf("%w: team %s: member without user_id or username", storage.ErrInvalidSyncDocument, spec.Name)
+			}
+			if docUsers[member.UserID] {
+				return nil, fmt.Errorf("%w: user %s is listed twice", storage.ErrInvalidSyncDocument, member.UserID)
+			}
+			docUsers[member.UserID] = true
+
+			team.desired.Users = append(team.desired.Users, domain.User{
+				UserID:   member.UserID,
+				Username: member.Username,
+				IsActive: member.IsActive == nil || *member.IsActive,
+				Role:     member.Role,
+			})
+		}
+
+		if err := normalizeTeam(&team.desired); err != nil {
+			return nil, fmt.Errorf("team %s: %w", spec.Name, err)
+		}
+
+		if current, ok := byName[spec.Name]; ok {
+			team.current = current
+			buddies, err := s.teamRepo.GetBuddyTeams(ctx, current.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get buddy teams: %w", err)
+			}
+			for _, buddy := range buddies {
+				team.currentBuddies = append(team.currentBuddies, buddy.Name)
+			}
+		}
+		state.teams = append(state.teams, team)
+
+		for _, user := range team.desired.Users {
+			u := &syncUser{desired: user, team: spec.Name}
+			if current, err := s.userRepo.GetByUserID(ctx, user.UserID); err == nil {
+				u.current = current
+				u.currentTeam = state.teamNames[current.TeamID]
+			}
+			state.users = append(state.users, u)
+		}
+	}
+
+	for _, team := range state.teams {
+		seen := make(map[string]bool, len(team.desired.BuddyTeams))
+		for _, name := range team.desired.BuddyTeams {
+			if name == team.desired.Name || seen[name] {
+				return nil, fmt.Errorf("%w: %s", storage.ErrInvalidBuddyTeam, name)
+			}
+			seen[name] = true
+
+			if !docTeams[name] && byName[name] == nil {
+				return nil, fmt.Errorf("%w: buddy team %s not found", storage.ErrNotFound, name)
+			}
+		}
+	}
+
+	for _, team := range existing {
+		for _, user := range team.Users {
+			if !docUsers[user.UserID] {
+				state.deactivate = append(state.deactivate, user)
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// planSync описывает изменения, которые внесет синхронизация, и открытые
+// ревью участников, которые перестанут быть активными
+func (s *TeamService) planSync(ctx context.Context, state *syncState) (*domain.SyncPlan, error) {
+	plan := &domain.SyncPlan{
+		Teams:         []domain.TeamChange{},
+		Users:         []domain.UserChange{},
+		Reassignments: []domain.SyncReassignment{},
+	}
+
+	for _, team := range state.teams {
+		if team.current == nil {
+			plan.Teams = append(plan.Teams, domain.TeamChange{Action: domain.SyncCreate, Team: team.desired.Name})
+			continue
+		}
+		if changes := team.changes(); len(changes) > 0 {
+			plan.Teams = append(plan.Teams, domain.TeamChange{Action: domain.SyncUpdate, Team: team.desired.Name, Changes: changes})
+		}
+	}
+
+	var leaving []string
+	for _, user := range state.users {
+		change := domain.UserChange{UserID: user.desired.UserID, Team: user.team}
+		switch {
+		case user.current == nil:
+			change.Action = domain.SyncCreate
+		case user.currentTeam != user.team:
+			change.Action = domain.SyncMove
+			change.FromTeam = user.currentTeam
+			change.Changes = user.changes()
+		default:
+			change.Changes = user.changes()
+			if len(change.Changes) == 0 {
+				continue
+			}
+			change.Action = domain.SyncUpdate
+		}
+		plan.Users = append(plan.Users, change)
+
+		if user.current != nil && user.current.IsActive && !user.desired.IsActive {
+			leaving = append(leaving, user.desired.UserID)
+		}
+	}
+
+	for _, user := range state.deactivate {
+		plan.Users = append(plan.Users, domain.UserChange{
+			Action: domain.SyncDeactivate,
+			UserID: user.UserID,
+			Team:   state.teamNames[user.TeamID],
+		})
+		leaving = append(leaving, user.UserID)
+	}
+
+	for _, userID := range leaving {
+		prs, err := s.userRepo.GetByReviewerID(ctx, userID, domain.PRFilter{StatusID: StatusOpenID, Sort: domain.PRSortCreatedAsc})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get review PRs: %w", err)
+		}
+		for _, pr := range prs {
+			plan.Reassignments = append(plan.Reassignments, domain.SyncReassignment{
+				PullRequestID: pr.PullRequestID,
+				OldReviewerID: userID,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// applySync вносит изменения плана: сначала команды, затем участники,
+// команды-партнеры и переназначение ревью
+func (s *TeamService) applySync(ctx context.Context, state *syncState, plan *domain.SyncPlan) error {
+	teams := make(map[string]*domain.Team, len(state.teams))
+	for _, team := range state.teams {
+		if team.current == nil {
+			created := team.desired
+			created.Users = nil
+			if err := s.teamRepo.Create(ctx, &created); err != nil {
+				return fmt.Errorf("failed to create team %s: %w", created.Name, err)
+			}
+			teams[created.Name] = &created
+			continue
+		}
+
+		updated := *team.current
+		if team.settingsChanged() {
+			updated.MinReviewers = team.desired.MinReviewers
+			updated.MaxReviewers = team.desired.MaxReviewers
+			updated.ReminderAfterMinutes = team.desired.ReminderAfterMinutes
+			updated.EscalationAfterMinutes = team.desired.EscalationAfterMinutes
+			if err := s.teamRepo.Update(ctx, &updated); err != nil {
+				return fmt.Errorf("failed to update team %s: %w", updated.Name, err)
+			}
+		}
+		teams[updated.Name] = &updated
+	}
+
+	for _, user := range state.users {
+		teamID := teams[user.team].ID
+		if user.current == nil {
+			created := user.desired
+			created.TeamID = teamID
+			if err := s.userRepo.Create(ctx, &created); err != nil {
+				return fmt.Errorf("failed to create user %s: %w", created.UserID, err)
+			}
+			continue
+		}
+
+		if user.currentTeam == user.team && len(user.changes()) == 0 {
+			continue
+		}
+		updated := *user.current
+		updated.Username = user.desired.Username
+		updated.IsActive = user.desired.IsActive
+		updated.Role = user.desired.Role
+		updated.TeamID = teamID
+		if err := s.userRepo.Update(ctx, &updated); err != nil {
+			return fmt.Errorf("failed to update user %s: %w", updated.UserID, err)
+		}
+	}
+
+	for _, user := range state.deactivate {
+		if err := s.userRepo.SetIsActive(ctx, user.UserID, false); err != nil {
+			return fmt.Errorf("failed to deactivate user %s: %w", user.UserID, err)
+		}
+	}
+
+	for _, team := range state.teams {
+		if slices.Equal(team.currentBuddies, team.desired.BuddyTeams) {
+			continue
+		}
+		if err := s.setBuddyTeams(ctx, teams[team.desired.Name], team.desired.BuddyTeams); err != nil {
+			return err
+		}
+	}
+
+	for i := range plan.Reassignments {
+		r := &plan.Reassignments[i]
+		result, err := s.reviews.ReassignReviewerWithOptions(ctx, r.PullRequestID, r.OldReviewerID, ReassignOptions{})
+		if errors.Is(err, storage.ErrNoCandidate) {
+			// Замены нет - ревьювер снимается, а недостающих до минимума
+			// команды добирает RemoveReviewerWithOptions. Деактивированного
+			// ревьювера нельзя оставить, даже если добрать некем.
+			opts := RemoveOptions{AllowShortfall: true}
+			if _, err := s.reviews.RemoveReviewerWithOptions(ctx, r.PullRequestID, r.OldReviewerID, opts); err != nil {
+				return fmt.Errorf("failed to remove reviewer %s from %s: %w", r.OldReviewerID, r.PullRequestID, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reassign reviewer %s on %s: %w", r.OldReviewerID, r.PullRequestID, err)
+		}
+		r.NewReviewerID = result.ReplacedBy
+	}
+
+	return nil
+}
+
+func (t *syncTeam) settingsChanged() bool {
+	return t.current.MinReviewers != t.desired.MinReviewers ||
+		t.current.MaxReviewers != t.desired.MaxReviewers ||
+		t.current.ReminderAfterMinutes != t.desired.ReminderAfterMinutes ||
+		t.current.EscalationAfterMinutes != t.desired.EscalationAfterMinutes
+}
+
+// changes описывает отличия настроек существующей команды от документа
+func (t *syncTeam) changes() []string {
+	var changes []string
+	changes = appendChange(changes, "min_reviewers", t.current.MinReviewers, t.desired.MinReviewers)
+	changes = appendChange(changes, "max_reviewers", t.current.MaxReviewers, t.desired.MaxReviewers)
+	changes = appendChange(changes, "reminder_after_minutes", t.current.ReminderAfterMinutes, t.desired.ReminderAfterMinutes)
+	changes = appendChange(changes, "escalation_after_minutes", t.current.EscalationAfterMinutes, t.desired.EscalationAfterMinutes)
+	if !slices.Equal(t.currentBuddies, t.desired.BuddyTeams) {
+		changes = append(changes, fmt.Sprintf("buddy_teams: %v -> %v", t.currentBuddies, t.desired.BuddyTeams))
+	}
+	return changes
+}
+
+// changes описывает отличия существующего участника от документа без учета
+// команды
+func (u *syncUser) changes() []string {
+	var changes []string
+	changes = appendChange(changes, "username", u.current.Username, u.desired.Username)
+	changes = appendChange(changes, "is_active", u.current.IsActive, u.desired.IsActive)
+	changes = appendChange(changes, "role", u.current.Role, u.desired.Role)
+	return changes
+}
+
+func appendChange[T comparable](changes []string, field string, from, to T) []string {
+	if from == to {
+		return changes
+	}
+	return append(changes, fmt.Sprintf("%s: %v -> %v", field, from, to))
+}
@@ -3,23 +3,57 @@ package services
 import (
 	"context"
 	"fmt"
+	"reviewer-appointment-service/internal/logger"
 	"reviewer-appointment-service/internal/models/domain"
 	"reviewer-appointment-service/internal/storage"
 )
 
+// DefaultMaxTeamMembers - максимальное число участников в запросе создания
+// команды по умолчанию
+const DefaultMaxTeamMembers = 500
+
 type TeamService struct {
-	teamRepo storage.TeamRepository
-	userRepo storage.UserRepository
+	teamRepo   storage.TeamRepository
+	userRepo   storage.UserRepository
+	maxMembers int
+
+	tx      storage.Transactor
+	reviews ReviewReassigner
 }
 
-func NewTeamService(teamRepo storage.TeamRepository, userRepo storage.UserRepository) *TeamService {
-	return &TeamService{
-		teamRepo: teamRepo,
-		userRepo: userRepo,
+// TeamServiceOption настраивает TeamService
+type TeamServiceOption func(*TeamService)
+
+// WithMaxTeamMembers ограничивает число участников в запросе создания
+// команды; 0 - без ограничения
+func WithMaxTeamMembers(n int) TeamServiceOption {
+	return func(s *TeamService) {
+		s.maxMembers = n
 	}
 }
 
-func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team) (*domain.Team, error) {
+func NewTeamService(teamRepo storage.TeamRepository, userRepo storage.UserRepository, opts ...TeamServiceOption) *TeamService {
+	s := &TeamService{
+		teamRepo:   teamRepo,
+		userRepo:   userRepo,
+		maxMembers: DefaultMaxTeamMembers,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team) (_ *domain.Team, err error) {
+	ctx, span := tracer.Start(ctx, "TeamService.CreateTeam")
+	defer func() { endSpan(span, err) }()
+
+	if s.maxMembers > 0 && len(team.Users) > s.maxMembers {
+		return nil, fmt.Errorf("%w: %d > %d", storage.ErrTooManyMembers, len(team.Users), s.maxMembers)
+	}
+
 	exists, err := s.teamRepo.ExistsByName(ctx, team.Name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check team existence: %w", err)
@@ -29,6 +63,17 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team) (*domai
 		return nil, storage.ErrTeamExists
 	}
 
+	if err := normalizeTeam(team); err != nil {
+		return nil, err
+	}
+
+	// Команды-партнеры проверяются до записи, чтобы ошибка в них не
+	// оставляла созданную наполовину команду
+	buddyIDs, err := s.resolveBuddyTeams(ctx, team.Name, team.BuddyTeams)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.teamRepo.Create(ctx, team)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create team: %w", err)
@@ -48,6 +93,7 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team) (*domai
 			existingUser.Username = user.Username
 			existingUser.IsActive = user.IsActive
 			existingUser.TeamID = team.ID
+			existingUser.Role = user.Role
 			err = s.userRepo.Update(ctx, existingUser)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update user %s: %w", user.UserID, err)
@@ -57,15 +103,56 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team) (*domai
 		}
 	}
 
+	if len(buddyIDs) > 0 {
+		if err := s.teamRepo.SetBuddyTeams(ctx, team.ID, team.Version, buddyIDs); err != nil {
+			return nil, fmt.Errorf("failed to set buddy teams: %w", err)
+		}
+	}
+
 	result, err := s.teamRepo.GetWithUsers(ctx, team.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team with users: %w", err)
 	}
 
+	logger.FromContext(ctx).Info("team created", "team", team.Name, "members", len(result.Users))
+
 	return result, nil
 }
 
-func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
+// normalizeTeam подставляет значения по умолчанию для границ числа
+// ревьюверов и ролей участников и проверяет настройки команды
+func normalizeTeam(team *domain.Team) error {
+	if team.MaxReviewers == 0 && team.MinReviewers == 0 {
+		team.MinReviewers = DefaultMinReviewers
+		team.MaxReviewers = MaxReviewers
+	}
+
+	if team.MinReviewers < 0 || team.MaxReviewers < team.MinReviewers {
+		return storage.ErrInvalidReviewerBounds
+	}
+
+	if team.ReminderAfterMinutes < 0 || team.EscalationAfterMinutes < 0 ||
+		(team.ReminderAfterMinutes > 0 && team.EscalationAfterMinutes > 0 && team.EscalationAfterMinutes < team.ReminderAfterMinutes) {
+		return storage.ErrInvalidReviewSLA
+	}
+
+	for i := range team.Users {
+		switch team.Users[i].Role {
+		case "":
+			team.Users[i].Role = domain.RoleMember
+		case domain.RoleMember, domain.RoleLead:
+		default:
+			return fmt.Errorf("%w: %s", storage.ErrInvalidRole, team.Users[i].Role)
+		}
+	}
+
+	return nil
+}
+
+func (s *TeamService) GetTeam(ctx context.Context, teamName string) (_ *domain.Team, err error) {
+	ctx, span := tracer.Start(ctx, "TeamService.GetTeam")
+	defer func() { endSpan(span, err) }()
+
 	team, err := s.teamRepo.GetByName(ctx, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("%w: team not found", storage.ErrNotFound)
@@ -79,8 +166,72 @@ func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*domain.Tea
 	return teamWithUsers, nil
 }
 
-func (s *TeamService) DeactivateTeamUsers(ctx context.Context, teamID int64) error {
-	_, err := s.teamRepo.GetByID(ctx, teamID)
+// SetBuddyTeams задает команды-партнеры, из которых заимствуются ревьюверы,
+// когда в команде не хватает активных участников. Порядок buddyNames
+// определяет приоритет.
+func (s *TeamService) SetBuddyTeams(ctx context.Context, teamName string, buddyNames []string) (_ *domain.Team, err error) {
+	ctx, span := tracer.Start(ctx, "TeamService.SetBuddyTeams")
+	defer func() { endSpan(span, err) }()
+
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: team not found", storage.ErrNotFound)
+	}
+
+	if err := checkVersion(ctx, team.Version); err != nil {
+		return nil, err
+	}
+
+	if err := s.setBuddyTeams(ctx, team, buddyNames); err != nil {
+		return nil, err
+	}
+
+	result, err := s.teamRepo.GetWithUsers(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team with users: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *TeamService) setBuddyTeams(ctx context.Context, team *domain.Team, buddyNames []string) error {
+	buddyIDs, err := s.resolveBuddyTeams(ctx, team.Name, buddyNames)
+	if err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.SetBuddyTeams(ctx, team.ID, team.Version, buddyIDs); err != nil {
+		return fmt.Errorf("failed to set buddy teams: %w", err)
+	}
+
+	return nil
+}
+
+// resolveBuddyTeams проверяет команды-партнеры команды teamName и
+// возвращает их ID в порядке приоритета
+func (s *TeamService) resolveBuddyTeams(ctx context.Context, teamName string, buddyNames []string) ([]int64, error) {
+	seen := make(map[string]bool, len(buddyNames))
+	buddyIDs := make([]int64, 0, len(buddyNames))
+	for _, name := range buddyNames {
+		if name == teamName || seen[name] {
+			return nil, fmt.Errorf("%w: %s", storage.ErrInvalidBuddyTeam, name)
+		}
+		seen[name] = true
+
+		buddy, err := s.teamRepo.GetByName(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: buddy team %s not found", storage.ErrNotFound, name)
+		}
+		buddyIDs = append(buddyIDs, buddy.ID)
+	}
+	return buddyIDs, nil
+}
+
+func (s *TeamService) DeactivateTeamUsers(ctx context.Context, teamID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "TeamService.DeactivateTeamUsers")
+	defer func() { endSpan(span, err) }()
+
+	_, err = s.teamRepo.GetByID(ctx, teamID)
 	if err != nil {
 		return fmt.Errorf("%w: team not found", storage.ErrNotFound)
 	}
@@ -22,6 +22,11 @@ func (m *MockTeamRepository) Create(ctx context.Context, team *domain.Team) erro
 	return args.Error(0)
 }
 
+func (m *MockTeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	args := m.Called(ctx, team)
+	return args.Error(0)
+}
+
 func (m *MockTeamRepository) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
 	args := m.Called(ctx, teamName)
 	if args.Get(0) == nil {
@@ -46,8 +51,8 @@ func (m *MockTeamRepository) GetWithUsers(ctx context.Context, teamID int64) (*d
 	return args.Get(0).(*domain.Team), args.Error(1)
 }
 
-func (m *MockTeamRepository) GetAllWithUsers(ctx context.Context) ([]domain.Team, error) {
-	args := m.Called(ctx)
+func (m *MockTeamRepository) GetAllWithUsers(ctx context.Context, after string, limit int) ([]domain.Team, error) {
+	args := m.Called(ctx, after, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -59,6 +64,19 @@ func (m *MockTeamRepository) ExistsByName(ctx context.Context, teamName string)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockTeamRepository) GetBuddyTeams(ctx context.Context, teamID int64) ([]domain.Team, error) {
+	args := m.Called(ctx, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Team), args.Error(1)
+}
+
+func (m *MockTeamRepository) SetBuddyTeams(ctx context.Context, teamID int64, version int, buddyTeamIDs []int64) error {
+	args := m.Called(ctx, teamID, version, buddyTeamIDs)
+	return args.Error(0)
+}
+
 func TestTeamService_CreateTeam(t *testing.T) {
 	ctx := context.Background()
 
@@ -85,17 +103,17 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			},
 		}
 
-		mockTeamRepo.On("ExistsByName", ctx, "backend").Return(false, nil)
-		mockTeamRepo.On("Create", ctx, mock.AnythingOfType("*domain.Team")).Run(func(args mock.Arguments) {
+		mockTeamRepo.On("ExistsByName", mock.Anything, "backend").Return(false, nil)
+		mockTeamRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Team")).Run(func(args mock.Arguments) {
 			team := args.Get(1).(*domain.Team)
 			team.ID = 1
 			team.CreatedAt = time.Now()
 		}).Return(nil)
-		mockUserRepo.On("GetByUserID", ctx, "u1").Return(nil, errors.New("not found"))
-		mockUserRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
-		mockUserRepo.On("GetByUserID", ctx, "u2").Return(nil, errors.New("not found"))
-		mockUserRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
-		mockTeamRepo.On("GetWithUsers", ctx, int64(1)).Return(createdTeam, nil)
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(nil, errors.New("not found"))
+		mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+		mockUserRepo.On("GetByUserID", mock.Anything, "u2").Return(nil, errors.New("not found"))
+		mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+		mockTeamRepo.On("GetWithUsers", mock.Anything, int64(1)).Return(createdTeam, nil)
 
 		result, err := service.CreateTeam(ctx, team)
 		assert.NoError(t, err)
@@ -112,7 +130,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 
 		team := &domain.Team{Name: "backend"}
 
-		mockTeamRepo.On("ExistsByName", ctx, "backend").Return(true, nil)
+		mockTeamRepo.On("ExistsByName", mock.Anything, "backend").Return(true, nil)
 
 		_, err := service.CreateTeam(ctx, team)
 		assert.Error(t, err)
@@ -150,15 +168,15 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			},
 		}
 
-		mockTeamRepo.On("ExistsByName", ctx, "backend").Return(false, nil)
-		mockTeamRepo.On("Create", ctx, mock.AnythingOfType("*domain.Team")).Run(func(args mock.Arguments) {
+		mockTeamRepo.On("ExistsByName", mock.Anything, "backend").Return(false, nil)
+		mockTeamRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Team")).Run(func(args mock.Arguments) {
 			team := args.Get(1).(*domain.Team)
 			team.ID = 1
 			team.CreatedAt = time.Now()
 		}).Return(nil)
-		mockUserRepo.On("GetByUserID", ctx, "u1").Return(existingUser, nil)
-		mockUserRepo.On("Update", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
-		mockTeamRepo.On("GetWithUsers", ctx, int64(1)).Return(createdTeam, nil)
+		mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(existingUser, nil)
+		mockUserRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+		mockTeamRepo.On("GetWithUsers", mock.Anything, int64(1)).Return(createdTeam, nil)
 
 		result, err := service.CreateTeam(ctx, team)
 		assert.NoError(t, err)
@@ -166,6 +184,65 @@ func TestTeamService_CreateTeam(t *testing.T) {
 		mockTeamRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 	})
+
+	t.Run("unknown member role", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo)
+
+		team := &domain.Team{
+			Name:  "backend",
+			Users: []domain.User{{UserID: "u1", Username: "Alice", IsActive: true, Role: "owner"}},
+		}
+
+		mockTeamRepo.On("ExistsByName", mock.Anything, "backend").Return(false, nil)
+
+		_, err := service.CreateTeam(ctx, team)
+		assert.ErrorIs(t, err, storage.ErrInvalidRole)
+		mockTeamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("escalation before reminder", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo)
+
+		team := &domain.Team{Name: "backend", ReminderAfterMinutes: 120, EscalationAfterMinutes: 60}
+
+		mockTeamRepo.On("ExistsByName", mock.Anything, "backend").Return(false, nil)
+
+		_, err := service.CreateTeam(ctx, team)
+		assert.Equal(t, storage.ErrInvalidReviewSLA, err)
+		mockTeamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("too many members", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo, WithMaxTeamMembers(2))
+
+		team := &domain.Team{Name: "backend", Users: []domain.User{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}}
+
+		_, err := service.CreateTeam(ctx, team)
+		assert.ErrorIs(t, err, storage.ErrTooManyMembers)
+		mockTeamRepo.AssertNotCalled(t, "ExistsByName", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown buddy team creates nothing", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo)
+
+		team := &domain.Team{Name: "backend", BuddyTeams: []string{"nope"}, Users: []domain.User{{UserID: "u1"}}}
+
+		mockTeamRepo.On("ExistsByName", mock.Anything, "backend").Return(false, nil)
+		mockTeamRepo.On("GetByName", mock.Anything, "nope").Return(nil, storage.ErrNotFound)
+
+		_, err := service.CreateTeam(ctx, team)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		mockTeamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
 }
 
 func TestTeamService_GetTeam(t *testing.T) {
@@ -189,8 +266,8 @@ func TestTeamService_GetTeam(t *testing.T) {
 			},
 		}
 
-		mockTeamRepo.On("GetByName", ctx, "backend").Return(team, nil)
-		mockTeamRepo.On("GetWithUsers", ctx, int64(1)).Return(teamWithUsers, nil)
+		mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+		mockTeamRepo.On("GetWithUsers", mock.Anything, int64(1)).Return(teamWithUsers, nil)
 
 		result, err := service.GetTeam(ctx, "backend")
 		assert.NoError(t, err)
@@ -204,7 +281,7 @@ func TestTeamService_GetTeam(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
 		service := NewTeamService(mockTeamRepo, mockUserRepo)
 
-		mockTeamRepo.On("GetByName", ctx, "non-existent").Return(nil, errors.New("not found"))
+		mockTeamRepo.On("GetByName", mock.Anything, "non-existent").Return(nil, errors.New("not found"))
 
 		_, err := service.GetTeam(ctx, "non-existent")
 		assert.Error(t, err)
@@ -226,8 +303,8 @@ func TestTeamService_DeactivateTeamUsers(t *testing.T) {
 			Name: "backend",
 		}
 
-		mockTeamRepo.On("GetByID", ctx, int64(1)).Return(team, nil)
-		mockUserRepo.On("DeactivateByTeamID", ctx, int64(1)).Return(nil)
+		mockTeamRepo.On("GetByID", mock.Anything, int64(1)).Return(team, nil)
+		mockUserRepo.On("DeactivateByTeamID", mock.Anything, int64(1)).Return(nil)
 
 		err := service.DeactivateTeamUsers(ctx, 1)
 		assert.NoError(t, err)
@@ -240,7 +317,7 @@ func TestTeamService_DeactivateTeamUsers(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
 		service := NewTeamService(mockTeamRepo, mockUserRepo)
 
-		mockTeamRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+		mockTeamRepo.On("GetByID", mock.Anything, int64(999)).Return(nil, errors.New("not found"))
 
 		err := service.DeactivateTeamUsers(ctx, 999)
 		assert.Error(t, err)
@@ -249,3 +326,51 @@ func TestTeamService_DeactivateTeamUsers(t *testing.T) {
 	})
 }
 
+
+func TestTeamService_SetBuddyTeams(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("buddies are saved in priority order", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo)
+
+		team := &domain.Team{ID: 1, Name: "tiny"}
+		updated := &domain.Team{ID: 1, Name: "tiny", BuddyTeams: []string{"backend", "frontend"}}
+
+		mockTeamRepo.On("GetByName", mock.Anything, "tiny").Return(team, nil)
+		mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(&domain.Team{ID: 3, Name: "backend"}, nil)
+		mockTeamRepo.On("GetByName", mock.Anything, "frontend").Return(&domain.Team{ID: 2, Name: "frontend"}, nil)
+		mockTeamRepo.On("SetBuddyTeams", mock.Anything, int64(1), 0, []int64{3, 2}).Return(nil)
+		mockTeamRepo.On("GetWithUsers", mock.Anything, int64(1)).Return(updated, nil)
+
+		result, err := service.SetBuddyTeams(ctx, "tiny", []string{"backend", "frontend"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"backend", "frontend"}, result.BuddyTeams)
+		mockTeamRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo)
+
+		mockTeamRepo.On("GetByName", mock.Anything, "tiny").Return(&domain.Team{ID: 1, Name: "tiny", Version: 3}, nil)
+
+		_, err := service.SetBuddyTeams(ContextWithExpectedVersion(ctx, 2), "tiny", []string{"backend"})
+		assert.ErrorIs(t, err, storage.ErrVersionConflict)
+		mockTeamRepo.AssertNotCalled(t, "SetBuddyTeams", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("team cannot be its own buddy", func(t *testing.T) {
+		mockTeamRepo := new(MockTeamRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTeamService(mockTeamRepo, mockUserRepo)
+
+		mockTeamRepo.On("GetByName", mock.Anything, "tiny").Return(&domain.Team{ID: 1, Name: "tiny"}, nil)
+
+		_, err := service.SetBuddyTeams(ctx, "tiny", []string{"tiny"})
+		assert.True(t, errors.Is(err, storage.ErrInvalidBuddyTeam))
+		mockTeamRepo.AssertNotCalled(t, "SetBuddyTeams", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"slices"
+	"time"
+)
+
+// TokenPrefix отличает токены сервиса от прочих секретов (например, при
+// поиске утечек в репозиториях)
+const TokenPrefix = "ras_"
+
+// tokenBytes - число случайных байт в токене
+const tokenBytes = 32
+
+// displayPrefixLength - сколько первых символов токена хранится открыто,
+// чтобы его можно было узнать в списке
+const displayPrefixLength = len(TokenPrefix) + 8
+
+// TokenService выпускает, проверяет и отзывает токены доступа к API
+type TokenService struct {
+	repo     storage.TokenRepository
+	userRepo storage.UserRepository
+	now      func() time.Time
+}
+
+func NewTokenService(repo storage.TokenRepository, userRepo storage.UserRepository) *TokenService {
+	return &TokenService{
+		repo:     repo,
+		userRepo: userRepo,
+		now:      time.Now,
+	}
+}
+
+// HashToken возвращает хеш токена, под которым он хранится в БД
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create выпускает токен с областями доступа scopes. Если userID не пустой,
+// токен действует от имени этого пользователя. Сам токен возвращается
+// только здесь; expiresAt == nil - бессрочный токен.
+func (s *TokenService) Create(ctx context.Context, name, userID string, scopes []string, expiresAt *time.Time) (_ *domain.CreatedToken, err error) {
+	ctx, span := tracer.Start(ctx, "TokenService.Create")
+	defer func() { endSpan(span, err) }()
+
+	scopes, err = normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		return nil, storage.ErrInvalidTokenExpiry
+	}
+	if userID != "" {
+		if _, err := s.userRepo.GetByUserID(ctx, userID); err != nil {
+			return nil, fmt.Errorf("%w: user not found", storage.ErrNotFound)
+		}
+	}
+
+	raw, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := &domain.APIToken{
+		Name:      name,
+		Prefix:    raw[:displayPrefixLength],
+		Scopes:    scopes,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		Hash:      HashToken(raw),
+	}
+	if err := s.repo.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("api token created",
+		"token_id", token.ID, "name", name, "scopes", scopes, "user_id", userID)
+
+	return &domain.CreatedToken{Token: raw, APIToken: token}, nil
+}
+
+// Authenticate находит действующий токен. Неизвестный, отозванный и
+// истекший токены одинаково дают storage.ErrUnauthorized.
+func (s *TokenService) Authenticate(ctx context.Context, raw string) (_ *domain.APIToken, err error) {
+	ctx, span := tracer.Start(ctx, "TokenService.Authenticate")
+	defer func() { endSpan(span, err) }()
+
+	if raw == "" {
+		return nil, storage.ErrUnauthorized
+	}
+
+	token, err := s.repo.GetByHash(ctx, HashToken(raw))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, storage.ErrUnauthorized
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	now := s.now()
+	if !token.Usable(now) {
+		return nil, storage.ErrUnauthorized
+	}
+
+	// Время последнего использования носит справочный характер: ошибка
+	// записи не должна отклонять запрос
+	if err := s.repo.TouchLastUsed(ctx, token.ID, now); err != nil {
+		logger.FromContext(ctx).Warn("failed to update token last use", "token_id", token.ID, "error", err)
+	}
+
+	return token, nil
+}
+
+// List возвращает все токены, включая отозванные и истекшие
+func (s *TokenService) List(ctx context.Context) (_ []domain.APIToken, err error) {
+	ctx, span := tracer.Start(ctx, "TokenService.List")
+	defer func() { endSpan(span, err) }()
+
+	tokens, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен; он перестает приниматься сразу
+func (s *TokenService) Revoke(ctx context.Context, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "TokenService.Revoke")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.repo.Revoke(ctx, id, s.now()); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("%w: token not found", storage.ErrNotFound)
+		}
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("api token revoked", "token_id", id)
+	return nil
+}
+
+// normalizeScopes проверяет области доступа и убирает повторы
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, storage.ErrInvalidScope
+	}
+
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !domain.ValidScope(scope) {
+			return nil, storage.ErrInvalidScope
+		}
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
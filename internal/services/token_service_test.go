@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTokenRepository - мок для TokenRepository
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) List(ctx context.Context) ([]domain.APIToken, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.APIToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func TestTokenService_Create(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("stores only hash", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		service := NewTokenService(repo, nil)
+		service.now = func() time.Time { return now }
+
+		var stored *domain.APIToken
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIToken")).
+			Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*domain.APIToken)
+				stored.ID = 7
+			}).Return(nil).Once()
+
+		created, err := service.Create(ctx, "ci", "", []string{domain.ScopeRead, domain.ScopePRWrite, domain.ScopeRead}, nil)
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(created.Token, TokenPrefix))
+		assert.Equal(t, int64(7), created.APIToken.ID)
+		assert.Equal(t, []string{domain.ScopeRead, domain.ScopePRWrite}, stored.Scopes)
+		assert.Equal(t, HashToken(created.Token), stored.Hash)
+		assert.NotContains(t, stored.Hash, created.Token)
+		assert.True(t, strings.HasPrefix(created.Token, stored.Prefix))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		service := NewTokenService(repo, nil)
+
+		_, err := service.Create(ctx, "ci", "", []string{"root"}, nil)
+		assert.ErrorIs(t, err, storage.ErrInvalidScope)
+
+		_, err = service.Create(ctx, "ci", "", nil, nil)
+		assert.ErrorIs(t, err, storage.ErrInvalidScope)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("bound to unknown user", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		userRepo := new(MockUserRepository)
+		service := NewTokenService(repo, userRepo)
+
+		userRepo.On("GetByUserID", mock.Anything, "ghost").Return(nil, storage.ErrNotFound).Once()
+
+		_, err := service.Create(ctx, "ghost", "ghost", []string{domain.ScopeRead}, nil)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		service := NewTokenService(repo, nil)
+		service.now = func() time.Time { return now }
+
+		past := now.Add(-time.Hour)
+		_, err := service.Create(ctx, "ci", "", []string{domain.ScopeRead}, &past)
+		assert.ErrorIs(t, err, storage.ErrInvalidTokenExpiry)
+	})
+}
+
+func TestTokenService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	raw := TokenPrefix + "secret"
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		token   *domain.APIToken
+		repoErr error
+		wantErr error
+	}{
+		{name: "valid", token: &domain.APIToken{ID: 1, Scopes: []string{domain.ScopeRead}, ExpiresAt: &future}},
+		{name: "unknown", repoErr: storage.ErrNotFound, wantErr: storage.ErrUnauthorized},
+		{name: "revoked", token: &domain.APIToken{ID: 1, RevokedAt: &past}, wantErr: storage.ErrUnauthorized},
+		{name: "expired", token: &domain.APIToken{ID: 1, ExpiresAt: &past}, wantErr: storage.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockTokenRepository)
+			service := NewTokenService(repo, nil)
+			service.now = func() time.Time { return now }
+
+			repo.On("GetByHash", mock.Anything, HashToken(raw)).Return(tt.token, tt.repoErr).Once()
+			if tt.wantErr == nil {
+				repo.On("TouchLastUsed", mock.Anything, tt.token.ID, now).Return(nil).Once()
+			}
+
+			token, err := service.Authenticate(ctx, raw)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, token)
+				repo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.token, token)
+			repo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("empty token", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		service := NewTokenService(repo, nil)
+
+		_, err := service.Authenticate(ctx, "")
+		assert.ErrorIs(t, err, storage.ErrUnauthorized)
+		repo.AssertNotCalled(t, "GetByHash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("last use update failure does not reject", func(t *testing.T) {
+		repo := new(MockTokenRepository)
+		service := NewTokenService(repo, nil)
+		service.now = func() time.Time { return now }
+
+		token := &domain.APIToken{ID: 2, Scopes: []string{domain.ScopeAdmin}}
+		repo.On("GetByHash", mock.Anything, HashToken(raw)).Return(token, nil).Once()
+		repo.On("TouchLastUsed", mock.Anything, int64(2), now).Return(errors.New("timeout")).Once()
+
+		got, err := service.Authenticate(ctx, raw)
+		require.NoError(t, err)
+		assert.Equal(t, token, got)
+	})
+}
+
+func TestTokenService_Revoke(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := new(MockTokenRepository)
+	service := NewTokenService(repo, nil)
+	service.now = func() time.Time { return now }
+
+	repo.On("Revoke", mock.Anything, int64(3), now).Return(nil).Once()
+	repo.On("Revoke", mock.Anything, int64(4), now).Return(storage.ErrNotFound).Once()
+
+	assert.NoError(t, service.Revoke(ctx, 3))
+	assert.ErrorIs(t, service.Revoke(ctx, 4), storage.ErrNotFound)
+	repo.AssertExpectations(t)
+}
+
+func TestAPIToken_HasScope(t *testing.T) {
+	admin := &domain.APIToken{Scopes: []string{domain.ScopeAdmin}}
+	reader := &domain.APIToken{Scopes: []string{domain.ScopeRead}}
+
+	assert.True(t, admin.HasScope(domain.ScopeTeamWrite))
+	assert.True(t, reader.HasScope(domain.ScopeRead))
+	assert.False(t, reader.HasScope(domain.ScopePRWrite))
+	assert.False(t, reader.HasScope(domain.ScopeAdmin))
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer создает спаны методов сервисов. Контекст со спаном передается в
+// репозитории, поэтому спаны запросов к БД вкладываются в спан метода.
+var tracer = otel.Tracer("reviewer-appointment-service/internal/services")
+
+// endSpan завершает спан метода сервиса. Ошибку метода записывает в спан и
+// помечает спан ошибочным.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
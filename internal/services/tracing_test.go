@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestServiceSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(prev)
+
+	mockUserRepo := new(MockUserRepository)
+	service := NewUserService(mockUserRepo)
+
+	var repoSpan trace.SpanContext
+	mockUserRepo.On("GetByUserID", mock.Anything, "u1").Return(&domain.User{UserID: "u1"}, nil).Once()
+	mockUserRepo.On("GetByReviewerID", mock.Anything, "u1", mock.Anything).Run(func(args mock.Arguments) {
+		repoSpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+	}).Return([]domain.PullRequest{}, nil).Once()
+
+	_, err := service.GetUserReviewPRs(context.Background(), "u1", domain.PRFilter{})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "UserService.GetUserReviewPRs", spans[0].Name())
+	// Репозиторий получает контекст со спаном сервиса
+	assert.Equal(t, spans[0].SpanContext().SpanID(), repoSpan.SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	t.Run("error is recorded", func(t *testing.T) {
+		mockUserRepo.On("GetByUserID", mock.Anything, "nope").Return(nil, storage.ErrNotFound).Once()
+
+		_, err := service.GetUser(context.Background(), "nope")
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		require.Len(t, spans[1].Events(), 1)
+		assert.Equal(t, "exception", spans[1].Events()[0].Name)
+	})
+}
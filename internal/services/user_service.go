@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"reviewer-appointment-service/internal/logger"
 	"reviewer-appointment-service/internal/models/domain"
 	"reviewer-appointment-service/internal/storage"
 )
@@ -17,31 +18,77 @@ func NewUserService(userRepo storage.UserRepository) *UserService {
 	}
 }
 
-func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+func (s *UserService) GetUser(ctx context.Context, userID string) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUser")
+	defer func() { endSpan(span, err) }()
+
 	user, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: user not found", storage.ErrNotFound)
 	}
 
-	err = s.userRepo.SetIsActive(ctx, userID, isActive)
+	return user, nil
+}
+
+func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.SetIsActive")
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set is_active: %w", err)
+		return nil, fmt.Errorf("%w: user not found", storage.ErrNotFound)
 	}
 
+	if err := checkVersion(ctx, user.Version); err != nil {
+		return nil, err
+	}
+
+	// Update сохраняет флаг, только если версия не изменилась с чтения:
+	// из двух одновременных запросов с одним If-Match второй получит
+	// ErrVersionConflict
 	user.IsActive = isActive
+	err = s.userRepo.Update(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set is_active: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("user activity changed", "user_id", userID, "is_active", isActive)
+
 	return user, nil
 }
 
-func (s *UserService) GetUserReviewPRs(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+// GetUserReviewPRs возвращает страницу PR, где пользователь назначен
+// ревьювером
+func (s *UserService) GetUserReviewPRs(ctx context.Context, userID string, filter domain.PRFilter) (_ *domain.PRPage, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUserReviewPRs")
+	defer func() { endSpan(span, err) }()
+
+	filter.Limit = pageLimit(filter)
+	return s.reviewPRs(ctx, userID, filter)
+}
+
+// GetAllUserReviewPRs возвращает все PR, где пользователь назначен
+// ревьювером, одним списком: так v1 отвечает клиентам, которые не
+// запрашивают страницу
+func (s *UserService) GetAllUserReviewPRs(ctx context.Context, userID string, filter domain.PRFilter) (_ *domain.PRPage, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetAllUserReviewPRs")
+	defer func() { endSpan(span, err) }()
+
+	filter.Limit = 0
+	filter.After = nil
+	return s.reviewPRs(ctx, userID, filter)
+}
+
+func (s *UserService) reviewPRs(ctx context.Context, userID string, filter domain.PRFilter) (*domain.PRPage, error) {
 	_, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: user not found", storage.ErrNotFound)
 	}
 
-	prs, err := s.userRepo.GetByReviewerID(ctx, userID)
+	prs, err := s.userRepo.GetByReviewerID(ctx, userID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get review PRs: %w", err)
 	}
 
-	return prs, nil
+	return prPage(prs, filter.Limit), nil
 }
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reviewer-appointment-service/internal/models/domain"
 	"reviewer-appointment-service/internal/storage"
 	"testing"
@@ -27,6 +28,14 @@ func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetByUserID(ctx context.Context, userID string) (*domain.User, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -48,8 +57,8 @@ func (m *MockUserRepository) SetIsActive(ctx context.Context, userID string, isA
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetByReviewerID(ctx context.Context, userID string) ([]domain.PullRequest, error) {
-	args := m.Called(ctx, userID)
+func (m *MockUserRepository) GetByReviewerID(ctx context.Context, userID string, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	args := m.Called(ctx, userID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -76,8 +85,10 @@ func TestUserService_SetIsActive(t *testing.T) {
 			TeamID:   1,
 		}
 
-		mockRepo.On("GetByUserID", ctx, "u1").Return(user, nil)
-		mockRepo.On("SetIsActive", ctx, "u1", true).Return(nil)
+		mockRepo.On("GetByUserID", mock.Anything, "u1").Return(user, nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+			return u.UserID == "u1" && u.IsActive
+		})).Return(nil)
 
 		result, err := service.SetIsActive(ctx, "u1", true)
 		assert.NoError(t, err)
@@ -89,7 +100,7 @@ func TestUserService_SetIsActive(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		service := NewUserService(mockRepo)
 
-		mockRepo.On("GetByUserID", ctx, "non-existent").Return(nil, errors.New("not found"))
+		mockRepo.On("GetByUserID", mock.Anything, "non-existent").Return(nil, errors.New("not found"))
 
 		_, err := service.SetIsActive(ctx, "non-existent", true)
 		assert.Error(t, err)
@@ -109,14 +120,30 @@ func TestUserService_SetIsActive(t *testing.T) {
 			TeamID:   1,
 		}
 
-		mockRepo.On("GetByUserID", ctx, "u1").Return(user, nil)
-		mockRepo.On("SetIsActive", ctx, "u1", false).Return(nil)
+		mockRepo.On("GetByUserID", mock.Anything, "u1").Return(user, nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+			return u.UserID == "u1" && !u.IsActive
+		})).Return(nil)
 
 		result, err := service.SetIsActive(ctx, "u1", false)
 		assert.NoError(t, err)
 		assert.False(t, result.IsActive)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("concurrent change with the same version", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		user := &domain.User{ID: 1, UserID: "u1", IsActive: true, TeamID: 1, Version: 3}
+
+		mockRepo.On("GetByUserID", mock.Anything, "u1").Return(user, nil)
+		mockRepo.On("Update", mock.Anything, mock.Anything).Return(storage.ErrVersionConflict)
+
+		_, err := service.SetIsActive(ContextWithExpectedVersion(ctx, 3), "u1", false)
+		assert.ErrorIs(t, err, storage.ErrVersionConflict)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestUserService_GetUserReviewPRs(t *testing.T) {
@@ -145,13 +172,14 @@ func TestUserService_GetUserReviewPRs(t *testing.T) {
 			},
 		}
 
-		mockRepo.On("GetByUserID", ctx, "u1").Return(user, nil)
-		mockRepo.On("GetByReviewerID", ctx, "u1").Return(prs, nil)
+		mockRepo.On("GetByUserID", mock.Anything, "u1").Return(user, nil)
+		mockRepo.On("GetByReviewerID", mock.Anything, "u1", domain.PRFilter{StatusID: StatusOpenID, Limit: DefaultPageLimit}).Return(prs, nil)
 
-		result, err := service.GetUserReviewPRs(ctx, "u1")
+		result, err := service.GetUserReviewPRs(ctx, "u1", domain.PRFilter{StatusID: StatusOpenID})
 		assert.NoError(t, err)
-		assert.Len(t, result, 1)
-		assert.Equal(t, "pr-1", result[0].PullRequestID)
+		assert.Len(t, result.PullRequests, 1)
+		assert.Equal(t, "pr-1", result.PullRequests[0].PullRequestID)
+		assert.Empty(t, result.NextCursor)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -159,9 +187,9 @@ func TestUserService_GetUserReviewPRs(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		service := NewUserService(mockRepo)
 
-		mockRepo.On("GetByUserID", ctx, "non-existent").Return(nil, errors.New("not found"))
+		mockRepo.On("GetByUserID", mock.Anything, "non-existent").Return(nil, errors.New("not found"))
 
-		_, err := service.GetUserReviewPRs(ctx, "non-existent")
+		_, err := service.GetUserReviewPRs(ctx, "non-existent", domain.PRFilter{})
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, storage.ErrNotFound))
 		mockRepo.AssertExpectations(t)
@@ -179,13 +207,52 @@ func TestUserService_GetUserReviewPRs(t *testing.T) {
 			TeamID:   1,
 		}
 
-		mockRepo.On("GetByUserID", ctx, "u1").Return(user, nil)
-		mockRepo.On("GetByReviewerID", ctx, "u1").Return([]domain.PullRequest{}, nil)
+		mockRepo.On("GetByUserID", mock.Anything, "u1").Return(user, nil)
+		mockRepo.On("GetByReviewerID", mock.Anything, "u1", mock.Anything).Return(nil, nil)
+
+		result, err := service.GetUserReviewPRs(ctx, "u1", domain.PRFilter{})
+		assert.NoError(t, err)
+		assert.NotNil(t, result.PullRequests)
+		assert.Len(t, result.PullRequests, 0)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("all PRs without a page", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		prs := make([]domain.PullRequest, DefaultPageLimit+10)
+		for i := range prs {
+			prs[i] = domain.PullRequest{ID: int64(i + 1), PullRequestID: fmt.Sprintf("pr-%d", i+1)}
+		}
+
+		mockRepo.On("GetByUserID", mock.Anything, "u1").Return(&domain.User{ID: 1, UserID: "u1"}, nil)
+		mockRepo.On("GetByReviewerID", mock.Anything, "u1", domain.PRFilter{StatusID: StatusOpenID}).Return(prs, nil)
 
-		result, err := service.GetUserReviewPRs(ctx, "u1")
+		result, err := service.GetAllUserReviewPRs(ctx, "u1", domain.PRFilter{StatusID: StatusOpenID})
 		assert.NoError(t, err)
-		assert.Len(t, result, 0)
+		assert.Len(t, result.PullRequests, DefaultPageLimit+10)
+		assert.Empty(t, result.NextCursor)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
+
+func TestUserService_GetUser(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo)
+
+	user := &domain.User{ID: 1, UserID: "u1", Username: "Alice", IsActive: true, TeamID: 1, Version: 2}
+	mockRepo.On("GetByUserID", mock.Anything, "u1").Return(user, nil)
+	mockRepo.On("GetByUserID", mock.Anything, "ghost").Return(nil, errors.New("no rows"))
+
+	result, err := service.GetUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, result)
+
+	_, err = service.GetUser(ctx, "ghost")
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+	mockRepo.AssertExpectations(t)
+}
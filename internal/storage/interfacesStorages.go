@@ -3,42 +3,122 @@ package storage
 import (
 	"context"
 	"reviewer-appointment-service/internal/models/domain"
+	"time"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	Update(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id int64) (*domain.User, error)
 	GetByUserID(ctx context.Context, userID string) (*domain.User, error)
 	GetByTeamID(ctx context.Context, teamID int64) ([]domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) error
-	GetByReviewerID(ctx context.Context, userID string) ([]domain.PullRequest, error)
+	// GetByReviewerID возвращает страницу PR, где пользователь назначен
+	// ревьювером, с авторами: не больше filter.Limit+1 строк, чтобы было
+	// видно, есть ли следующая страница
+	GetByReviewerID(ctx context.Context, userID string, filter domain.PRFilter) ([]domain.PullRequest, error)
 	DeactivateByTeamID(ctx context.Context, teamID int64) error
 }
 
 type TeamRepository interface {
 	Create(ctx context.Context, team *domain.Team) error
+	// Update сохраняет границы числа ревьюверов и SLA команды, если ее версия
+	// равна team.Version, и увеличивает версию. Иначе - ErrVersionConflict.
+	Update(ctx context.Context, team *domain.Team) error
 	GetByName(ctx context.Context, teamName string) (*domain.Team, error)
 	GetByID(ctx context.Context, teamID int64) (*domain.Team, error)
 	GetWithUsers(ctx context.Context, teamID int64) (*domain.Team, error)
-	GetAllWithUsers(ctx context.Context) ([]domain.Team, error)
+	// GetAllWithUsers возвращает команды с активными участниками по имени,
+	// начиная после after. limit <= 0 - без ограничения.
+	GetAllWithUsers(ctx context.Context, after string, limit int) ([]domain.Team, error)
 	ExistsByName(ctx context.Context, teamName string) (bool, error)
+	GetBuddyTeams(ctx context.Context, teamID int64) ([]domain.Team, error)
+	SetBuddyTeams(ctx context.Context, teamID int64, version int, buddyTeamIDs []int64) error
 }
 
 type PRRepository interface {
 	Create(ctx context.Context, pr *domain.PullRequest) error
+	// CreateBatch сохраняет PR вместе с ревьюверами в одной транзакции: при
+	// любой ошибке не сохраняется ни один PR. Уже существующий ID PR -
+	// ErrPRExists.
+	CreateBatch(ctx context.Context, prs []domain.PlannedPR) error
 	Update(ctx context.Context, pr *domain.PullRequest) error
 	GetByPRID(ctx context.Context, prID string) (*domain.PullRequest, error)
 	GetByReviewerID(ctx context.Context, reviewerID string) ([]domain.PullRequest, error)
+	// List возвращает страницу PR с авторами по фильтру, как
+	// UserRepository.GetByReviewerID
+	List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error)
 	GetOpenPRsByUserIDs(ctx context.Context, userIDs []string) ([]domain.PullRequest, error)
-	AddReviewer(ctx context.Context, prID int64, reviewerID int64) error
+	AddReviewer(ctx context.Context, prID int64, reviewerID int64, reason string) error
 	RemoveReviewer(ctx context.Context, prID int64, reviewerID int64) error
 	GetReviewers(ctx context.Context, prID int64) ([]domain.User, error)
+	GetAssignments(ctx context.Context, prID int64) ([]domain.ReviewerAssignment, error)
+	GetPairCounts(ctx context.Context, authorID int64, since time.Time) (map[int64]int, error)
+	MarkReviewed(ctx context.Context, prID int64, reviewerID int64, at time.Time) error
+	BumpVersion(ctx context.Context, prID int64, version int) error
+}
+
+// Transactor выполняет fn в одной транзакции: запросы репозиториев с
+// контекстом fn выполняются в ней, а ошибка fn откатывает все изменения
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// HealthRepository предоставляет проверки состояния БД
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (int64, error)
+	PoolStats() domain.PoolStats
+}
+
+// OrgRepository хранит организации. Организации не принадлежат ни одной
+// организации, поэтому запросы не зависят от организации контекста.
+type OrgRepository interface {
+	Create(ctx context.Context, org *domain.Organization) error
+	GetBySlug(ctx context.Context, slug string) (*domain.Organization, error)
+	List(ctx context.Context) ([]domain.Organization, error)
+}
+
+// TokenRepository хранит токены доступа к API
+type TokenRepository interface {
+	Create(ctx context.Context, token *domain.APIToken) error
+	GetByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	List(ctx context.Context) ([]domain.APIToken, error)
+	Revoke(ctx context.Context, id int64, at time.Time) error
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
+
+// ReviewSLARepository ищет просроченные назначения и хранит события SLA
+type ReviewSLARepository interface {
+	GetStaleReviews(ctx context.Context, kind string, defaultAfter time.Duration, now time.Time, limit int) ([]domain.StaleReview, error)
+	CreateEvent(ctx context.Context, event *domain.ReviewEvent) (bool, error)
 }
 
 type StatsRepository interface {
-	GetTotalPRs(ctx context.Context) (int, error)
-	GetTotalUsers(ctx context.Context) (int, error)
-	GetActiveUsers(ctx context.Context) (int, error)
-	GetPRsByStatus(ctx context.Context) (map[string]int, error)
-	GetTopReviewers(ctx context.Context, limit int) ([]domain.ReviewerStats, error)
+	GetTotalPRs(ctx context.Context, filter domain.StatsFilter) (int, error)
+	GetTotalUsers(ctx context.Context, filter domain.StatsFilter) (int, error)
+	GetActiveUsers(ctx context.Context, filter domain.StatsFilter) (int, error)
+	GetPRsByStatus(ctx context.Context, filter domain.StatsFilter) (map[string]int, error)
+	GetTopReviewers(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStats, error)
+	GetBorrowedReviews(ctx context.Context, filter domain.StatsFilter) (map[string]int, error)
+	GetTeamStats(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamStats, error)
+	GetMemberWorkloads(ctx context.Context, filter domain.StatsFilter) ([]domain.MemberWorkload, error)
+	GetOpenPRGroups(ctx context.Context) ([]domain.OpenPRGroup, error)
+	GetReviewPairs(ctx context.Context, since time.Time) ([]domain.ReviewPairStats, error)
+	GetLatency(ctx context.Context, filter domain.StatsFilter) (*domain.LatencyReport, error)
+}
+
+// IdempotencyRepository хранит ответы на запросы с Idempotency-Key.
+// Организация берется из контекста.
+type IdempotencyRepository interface {
+	// Reserve сохраняет незавершенную запись, если ключ свободен или его
+	// прежняя запись истекла. Возвращает false, если ключ занят.
+	Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error)
+	Get(ctx context.Context, client, key string) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *domain.IdempotencyRecord) error
+	// Extend продлевает до expiresAt незавершенную запись запроса с хешем
+	// requestHash
+	Extend(ctx context.Context, client, key, requestHash string, expiresAt time.Time) error
+	Delete(ctx context.Context, client, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
 }
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+)
+
+type OrgRepo struct {
+	store *Store
+}
+
+func NewOrgRepo(store *Store) *OrgRepo {
+	return &OrgRepo{store: store}
+}
+
+func (r *OrgRepo) Create(_ context.Context, org *domain.Organization) error {
+	const op = "memory.OrgRepo.Create"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.orgs {
+		if existing.Slug == org.Slug {
+			return fmt.Errorf("%s: organization %s already exists", op, org.Slug)
+		}
+	}
+
+	org.ID = r.store.id()
+	org.CreatedAt = r.store.now()
+	r.store.orgs = append(r.store.orgs, *org)
+	return nil
+}
+
+func (r *OrgRepo) GetBySlug(_ context.Context, slug string) (*domain.Organization, error) {
+	const op = "memory.OrgRepo.GetBySlug"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, org := range r.store.orgs {
+		if org.Slug == slug {
+			return &org, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+}
+
+func (r *OrgRepo) List(context.Context) ([]domain.Organization, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return append([]domain.Organization{}, r.store.orgs...), nil
+}
+
+type TokenRepo struct {
+	store *Store
+}
+
+func NewTokenRepo(store *Store) *TokenRepo {
+	return &TokenRepo{store: store}
+}
+
+// Create сохраняет токен в организации запроса. Токен, выпущенный от имени
+// неизвестного пользователя, как и в postgresql, остается без пользователя.
+func (r *TokenRepo) Create(ctx context.Context, token *domain.APIToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token.ID = r.store.id()
+	token.OrgID = tenant.OrgID(ctx)
+	token.CreatedAt = r.store.now()
+	if token.UserID != "" && r.store.org(ctx).userByUserID(token.UserID) == nil {
+		token.UserID = ""
+	}
+
+	stored := *token
+	stored.Scopes = append([]string(nil), token.Scopes...)
+	r.store.tokens = append(r.store.tokens, &stored)
+	return nil
+}
+
+// GetByHash ищет токен среди всех организаций: именно токен определяет
+// организацию запроса
+func (r *TokenRepo) GetByHash(_ context.Context, hash string) (*domain.APIToken, error) {
+	const op = "memory.TokenRepo.GetByHash"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.tokens {
+		if token.Hash == hash {
+			result := *token
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+}
+
+func (r *TokenRepo) List(ctx context.Context) ([]domain.APIToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tokens := []domain.APIToken{}
+	for _, token := range r.store.tokens {
+		if token.OrgID == tenant.OrgID(ctx) {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен. Повторный отзыв не меняет время первого.
+func (r *TokenRepo) Revoke(ctx context.Context, id int64, at time.Time) error {
+	const op = "memory.TokenRepo.Revoke"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.tokens {
+		if token.ID == id && token.OrgID == tenant.OrgID(ctx) {
+			if token.RevokedAt == nil {
+				token.RevokedAt = &at
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+}
+
+func (r *TokenRepo) TouchLastUsed(_ context.Context, id int64, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.tokens {
+		if token.ID == id {
+			token.LastUsedAt = &at
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Package memory - реализация репозиториев в памяти процесса. Используется
+// в тестах, которым нужен весь HTTP-стек без PostgreSQL: данные
+// разделяются по организациям, версии и ошибки ведут себя так же, как в
+// postgresql, но история активности и события SLA не хранятся.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
+)
+
+// Store - общее хранилище репозиториев. Все операции выполняются под одной
+// блокировкой.
+type Store struct {
+	mu     sync.Mutex
+	nextID int64
+	now    func() time.Time
+
+	schemaVersion int64
+	orgs          []domain.Organization
+	tokens        []*domain.APIToken
+	data          map[int64]*orgData
+}
+
+// orgData - данные одной организации
+type orgData struct {
+	users   map[int64]*domain.User
+	teams   map[int64]*domain.Team
+	buddies map[int64][]int64
+	prs     map[int64]*domain.PullRequest
+	// reviewers - текущие назначения по ID PR в порядке назначения,
+	// history - снятые назначения
+	reviewers map[int64][]*assignment
+	history   []assignment
+}
+
+type assignment struct {
+	prID       int64
+	reviewerID int64
+	reason     string
+	assignedAt time.Time
+	reviewedAt *time.Time
+}
+
+// New создает пустое хранилище с организацией по умолчанию. schemaVersion
+// возвращается проверке готовности как текущая версия схемы.
+func New(schemaVersion int64) *Store {
+	return &Store{
+		now:           time.Now,
+		schemaVersion: schemaVersion,
+		orgs: []domain.Organization{
+			{ID: tenant.DefaultOrgID, Slug: tenant.DefaultSlug, Name: "Default", CreatedAt: time.Now()},
+		},
+		nextID: tenant.DefaultOrgID,
+		data:   make(map[int64]*orgData),
+	}
+}
+
+// SetClock подменяет источник времени назначений, ревью и мержа
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+func (s *Store) Ping(context.Context) error {
+	return nil
+}
+
+func (s *Store) SchemaVersion(context.Context) (int64, error) {
+	return s.schemaVersion, nil
+}
+
+func (s *Store) PoolStats() domain.PoolStats {
+	return domain.PoolStats{MaxConns: 1}
+}
+
+// org возвращает данные организации запроса. Вызывается под блокировкой.
+func (s *Store) org(ctx context.Context) *orgData {
+	orgID := tenant.OrgID(ctx)
+	d, ok := s.data[orgID]
+	if !ok {
+		d = &orgData{
+			users:     make(map[int64]*domain.User),
+			teams:     make(map[int64]*domain.Team),
+			buddies:   make(map[int64][]int64),
+			prs:       make(map[int64]*domain.PullRequest),
+			reviewers: make(map[int64][]*assignment),
+		}
+		s.data[orgID] = d
+	}
+	return d
+}
+
+// InTx выполняет fn и при ошибке восстанавливает данные организации, какими
+// они были до вызова. В отличие от PostgreSQL, запросы, выполненные во
+// время fn вне ее, не изолируются и при откате тоже теряются.
+func (s *Store) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	orgID := tenant.OrgID(ctx)
+	snapshot := s.org(ctx).clone()
+	s.mu.Unlock()
+
+	if err := fn(ctx); err != nil {
+		s.mu.Lock()
+		s.data[orgID] = snapshot
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// clone возвращает копию данных организации, не разделяющую с ней
+// изменяемые значения
+func (d *orgData) clone() *orgData {
+	c := &orgData{
+		users:     make(map[int64]*domain.User, len(d.users)),
+		teams:     make(map[int64]*domain.Team, len(d.teams)),
+		buddies:   make(map[int64][]int64, len(d.buddies)),
+		prs:       make(map[int64]*domain.PullRequest, len(d.prs)),
+		reviewers: make(map[int64][]*assignment, len(d.reviewers)),
+		history:   append([]assignment(nil), d.history...),
+	}
+	for id, user := range d.users {
+		u := *user
+		c.users[id] = &u
+	}
+	for id, team := range d.teams {
+		t := *team
+		c.teams[id] = &t
+	}
+	for id, buddies := range d.buddies {
+		c.buddies[id] = append([]int64(nil), buddies...)
+	}
+	for id, pr := range d.prs {
+		p := *pr
+		c.prs[id] = &p
+	}
+	for id, assignments := range d.reviewers {
+		copied := make([]*assignment, 0, len(assignments))
+		for _, a := range assignments {
+			a := *a
+			copied = append(copied, &a)
+		}
+		c.reviewers[id] = copied
+	}
+	return c
+}
+
+func (s *Store) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (d *orgData) userByUserID(userID string) *domain.User {
+	for _, user := range d.users {
+		if user.UserID == userID {
+			return user
+		}
+	}
+	return nil
+}
+
+func (d *orgData) teamByName(name string) *domain.Team {
+	for _, team := range d.teams {
+		if team.Name == name {
+			return team
+		}
+	}
+	return nil
+}
+
+func (d *orgData) prByPRID(prID string) *domain.PullRequest {
+	for _, pr := range d.prs {
+		if pr.PullRequestID == prID {
+			return pr
+		}
+	}
+	return nil
+}
+
+// teamName возвращает название команды пользователя или пустую строку
+func (d *orgData) teamName(userID int64) string {
+	user, ok := d.users[userID]
+	if !ok {
+		return ""
+	}
+	if team, ok := d.teams[user.TeamID]; ok {
+		return team.Name
+	}
+	return ""
+}
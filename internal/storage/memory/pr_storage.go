@@ -0,0 +1,357 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+)
+
+type PRRepo struct {
+	store *Store
+}
+
+func NewPRRepo(store *Store) *PRRepo {
+	return &PRRepo{store: store}
+}
+
+func (r *PRRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
+	const op = "memory.PRRepo.Create"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	if d.prByPRID(pr.PullRequestID) != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrPRExists)
+	}
+
+	pr.ID = r.store.id()
+	pr.Version = 1
+	pr.CreatedAt = r.store.now()
+	d.prs[pr.ID] = &domain.PullRequest{
+		ID:              pr.ID,
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorID:        pr.AuthorID,
+		StatusID:        pr.StatusID,
+		MergedAt:        pr.MergedAt,
+		Version:         pr.Version,
+		CreatedAt:       pr.CreatedAt,
+	}
+	return nil
+}
+
+// CreateBatch сохраняет PR и их ревьюверов. Если хотя бы один ID PR занят,
+// не сохраняется ни один PR.
+func (r *PRRepo) CreateBatch(ctx context.Context, prs []domain.PlannedPR) error {
+	const op = "memory.PRRepo.CreateBatch"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	seen := make(map[string]bool, len(prs))
+	for _, planned := range prs {
+		prID := planned.PullRequest.PullRequestID
+		if seen[prID] || d.prByPRID(prID) != nil {
+			return fmt.Errorf("%s: %s: %w", op, prID, storage.ErrPRExists)
+		}
+		seen[prID] = true
+	}
+
+	for _, planned := range prs {
+		pr := planned.PullRequest
+		pr.ID = r.store.id()
+		pr.Version = 1
+		pr.CreatedAt = r.store.now()
+		d.prs[pr.ID] = &domain.PullRequest{
+			ID:              pr.ID,
+			PullRequestID:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorID:        pr.AuthorID,
+			StatusID:        pr.StatusID,
+			Version:         pr.Version,
+			CreatedAt:       pr.CreatedAt,
+		}
+		for _, reviewer := range planned.Reviewers {
+			d.reviewers[pr.ID] = append(d.reviewers[pr.ID], &assignment{
+				prID:       pr.ID,
+				reviewerID: reviewer.ReviewerID,
+				reason:     reviewer.Reason,
+				assignedAt: pr.CreatedAt,
+			})
+		}
+	}
+	return nil
+}
+
+// Update сохраняет PR, если его версия не изменилась с момента чтения, и
+// увеличивает версию. Иначе - ErrVersionConflict.
+func (r *PRRepo) Update(ctx context.Context, pr *domain.PullRequest) error {
+	const op = "memory.PRRepo.Update"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored := r.store.org(ctx).prByPRID(pr.PullRequestID)
+	if stored == nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if stored.Version != pr.Version {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+
+	stored.PullRequestName = pr.PullRequestName
+	stored.StatusID = pr.StatusID
+	stored.MergedAt = pr.MergedAt
+	stored.Version++
+
+	pr.ID, pr.AuthorID, pr.CreatedAt, pr.Version = stored.ID, stored.AuthorID, stored.CreatedAt, stored.Version
+	return nil
+}
+
+func (r *PRRepo) BumpVersion(ctx context.Context, prID int64, version int) error {
+	const op = "memory.PRRepo.BumpVersion"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	pr, ok := r.store.org(ctx).prs[prID]
+	if !ok || pr.Version != version {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+	pr.Version++
+	return nil
+}
+
+func (r *PRRepo) GetByPRID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	const op = "memory.PRRepo.GetByPRID"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	stored := d.prByPRID(prID)
+	if stored == nil {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	pr := *stored
+	pr.Reviewers = d.reviewerUsers(pr.ID)
+	pr.Assignments = d.assignments(pr.ID)
+	return &pr, nil
+}
+
+func (r *PRRepo) GetByReviewerID(ctx context.Context, reviewerID string) ([]domain.PullRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.org(ctx).reviewedBy(reviewerID), nil
+}
+
+func (r *PRRepo) List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.org(ctx).listPRs(filter), nil
+}
+
+func (r *PRRepo) GetOpenPRsByUserIDs(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	prs := []domain.PullRequest{}
+	for _, pr := range d.sortedPRs() {
+		author, ok := d.users[pr.AuthorID]
+		if !ok || pr.StatusID != statusOpen || !slices.Contains(userIDs, author.UserID) {
+			continue
+		}
+		a := *author
+		a.Version = 0
+		pr.Author = &a
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func (r *PRRepo) AddReviewer(ctx context.Context, prID int64, reviewerID int64, reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	for _, a := range d.reviewers[prID] {
+		if a.reviewerID == reviewerID {
+			return nil
+		}
+	}
+	d.reviewers[prID] = append(d.reviewers[prID], &assignment{
+		prID:       prID,
+		reviewerID: reviewerID,
+		reason:     reason,
+		assignedAt: r.store.now(),
+	})
+	return nil
+}
+
+// RemoveReviewer снимает ревьювера с PR и переносит назначение в историю
+func (r *PRRepo) RemoveReviewer(ctx context.Context, prID int64, reviewerID int64) error {
+	const op = "memory.PRRepo.RemoveReviewer"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	for i, a := range d.reviewers[prID] {
+		if a.reviewerID == reviewerID {
+			d.history = append(d.history, *a)
+			d.reviewers[prID] = slices.Delete(d.reviewers[prID], i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: reviewer not found for this PR", op)
+}
+
+// MarkReviewed фиксирует первое действие ревьювера по PR. Повторные вызовы
+// не меняют время.
+func (r *PRRepo) MarkReviewed(ctx context.Context, prID int64, reviewerID int64, at time.Time) error {
+	const op = "memory.PRRepo.MarkReviewed"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, a := range r.store.org(ctx).reviewers[prID] {
+		if a.reviewerID == reviewerID {
+			if a.reviewedAt == nil {
+				a.reviewedAt = &at
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: reviewer not found for this PR", op)
+}
+
+func (r *PRRepo) GetReviewers(ctx context.Context, prID int64) ([]domain.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.org(ctx).reviewerUsers(prID), nil
+}
+
+func (r *PRRepo) GetAssignments(ctx context.Context, prID int64) ([]domain.ReviewerAssignment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.org(ctx).assignments(prID), nil
+}
+
+func (r *PRRepo) GetPairCounts(ctx context.Context, authorID int64, since time.Time) (map[int64]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	counts := make(map[int64]int)
+	for _, a := range d.allAssignments() {
+		if d.prs[a.prID].AuthorID == authorID && !a.assignedAt.Before(since) {
+			counts[a.reviewerID]++
+		}
+	}
+	return counts, nil
+}
+
+// Статусы PR в таблице pr_system.statuses
+const (
+	statusOpen   = 1
+	statusMerged = 2
+)
+
+// sortedPRs возвращает копии PR организации, упорядоченные по ID
+func (d *orgData) sortedPRs() []domain.PullRequest {
+	prs := make([]domain.PullRequest, 0, len(d.prs))
+	for _, pr := range d.prs {
+		prs = append(prs, *pr)
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].ID < prs[j].ID })
+	return prs
+}
+
+// listPRs повторяет выборку postgresql: фильтры, порядок (created_at, id),
+// продолжение после курсора и на одну строку больше filter.Limit
+func (d *orgData) listPRs(filter domain.PRFilter) []domain.PullRequest {
+	prs := d.sortedPRs()
+	asc := filter.Sort == domain.PRSortCreatedAsc
+	sort.SliceStable(prs, func(i, j int) bool {
+		if !prs[i].CreatedAt.Equal(prs[j].CreatedAt) {
+			return prs[i].CreatedAt.Before(prs[j].CreatedAt) == asc
+		}
+		return (prs[i].ID < prs[j].ID) == asc
+	})
+
+	result := []domain.PullRequest{}
+	for _, pr := range prs {
+		author := d.users[pr.AuthorID]
+		switch {
+		case filter.ReviewerID != "" && !d.isReviewer(pr.ID, filter.ReviewerID),
+			filter.AuthorID != "" && author.UserID != filter.AuthorID,
+			filter.StatusID != 0 && pr.StatusID != filter.StatusID,
+			filter.TeamName != "" && d.teamName(author.ID) != filter.TeamName,
+			!filter.CreatedAfter.IsZero() && !pr.CreatedAt.After(filter.CreatedAfter),
+			filter.After != nil && !afterCursor(pr, filter.After, asc):
+			continue
+		}
+
+		a := *author
+		a.Version = 0
+		pr.Author = &a
+		result = append(result, pr)
+		if filter.Limit > 0 && len(result) > filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+func (d *orgData) isReviewer(prID int64, userID string) bool {
+	for _, a := range d.reviewers[prID] {
+		if d.users[a.reviewerID].UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// afterCursor сообщает, идет ли PR после курсора в порядке выборки
+func afterCursor(pr domain.PullRequest, cursor *domain.PRCursor, asc bool) bool {
+	if !pr.CreatedAt.Equal(cursor.CreatedAt) {
+		return pr.CreatedAt.After(cursor.CreatedAt) == asc
+	}
+	if pr.ID == cursor.ID {
+		return false
+	}
+	return (pr.ID > cursor.ID) == asc
+}
+
+func (d *orgData) reviewerUsers(prID int64) []domain.User {
+	var users []domain.User
+	for _, a := range d.reviewers[prID] {
+		user := *d.users[a.reviewerID]
+		user.Version = 0
+		users = append(users, user)
+	}
+	return users
+}
+
+func (d *orgData) assignments(prID int64) []domain.ReviewerAssignment {
+	var result []domain.ReviewerAssignment
+	for _, a := range d.reviewers[prID] {
+		reviewer := d.users[a.reviewerID]
+		result = append(result, domain.ReviewerAssignment{
+			UserID:     reviewer.UserID,
+			Username:   reviewer.Username,
+			TeamID:     reviewer.TeamID,
+			Reason:     a.reason,
+			Borrowed:   a.reason == domain.AssignReasonBorrowed,
+			AssignedAt: a.assignedAt,
+			ReviewedAt: a.reviewedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,445 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+)
+
+// StatsRepo считает статистику по данным в памяти. Журнал активности не
+// хранится, поэтому время активности участника в GetMemberWorkloads
+// считается по текущему флагу is_active.
+type StatsRepo struct {
+	store *Store
+}
+
+func NewStatsRepo(store *Store) *StatsRepo {
+	return &StatsRepo{store: store}
+}
+
+func (r *StatsRepo) GetTotalPRs(ctx context.Context, filter domain.StatsFilter) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	total := 0
+	for _, pr := range d.prs {
+		if inPeriod(filter, pr.CreatedAt) && matchTeam(filter, d.teamName(pr.AuthorID)) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r *StatsRepo) GetTotalUsers(ctx context.Context, filter domain.StatsFilter) (int, error) {
+	return r.countUsers(ctx, filter, false)
+}
+
+func (r *StatsRepo) GetActiveUsers(ctx context.Context, filter domain.StatsFilter) (int, error) {
+	return r.countUsers(ctx, filter, true)
+}
+
+func (r *StatsRepo) countUsers(ctx context.Context, filter domain.StatsFilter, onlyActive bool) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	total := 0
+	for _, user := range d.users {
+		if (user.IsActive || !onlyActive) && matchTeam(filter, d.teamName(user.ID)) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r *StatsRepo) GetPRsByStatus(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	result := map[string]int{domain.PRStatusOpen: 0, domain.PRStatusMerged: 0}
+	for _, pr := range d.prs {
+		if !inPeriod(filter, pr.CreatedAt) || !matchTeam(filter, d.teamName(pr.AuthorID)) {
+			continue
+		}
+		if pr.StatusID == statusMerged {
+			result[domain.PRStatusMerged]++
+		} else {
+			result[domain.PRStatusOpen]++
+		}
+	}
+	return result, nil
+}
+
+func (r *StatsRepo) GetTopReviewers(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStats, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	counts := d.reviewCounts(filter)
+
+	var result []domain.ReviewerStats
+	for id, count := range counts {
+		user := d.users[id]
+		if user.IsActive && matchTeam(filter, d.teamName(id)) {
+			result = append(result, domain.ReviewerStats{UserID: user.UserID, Username: user.Username, ReviewCount: count})
+		}
+	}
+	sortReviewerStats(result)
+
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (r *StatsRepo) GetBorrowedReviews(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	result := make(map[string]int)
+	for _, a := range d.allAssignments() {
+		teamName := d.teamName(a.reviewerID)
+		if a.reason != domain.AssignReasonBorrowed || teamName == "" || !inPeriod(filter, a.assignedAt) || !matchTeam(filter, teamName) {
+			continue
+		}
+		result[teamName]++
+	}
+	return result, nil
+}
+
+func (r *StatsRepo) GetTeamStats(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamStats, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	// Как и в postgres, учитываются и снятые назначения
+	counts := make(map[int64]int)
+	for _, a := range d.allAssignments() {
+		if inPeriod(filter, a.assignedAt) {
+			counts[a.reviewerID]++
+		}
+	}
+
+	teams := []domain.TeamStats{}
+	for _, team := range d.sortedTeams() {
+		if !matchTeam(filter, team.Name) {
+			continue
+		}
+
+		ts := domain.TeamStats{TeamName: team.Name, ReviewsPerMember: []domain.ReviewerStats{}}
+		for _, pr := range d.prs {
+			if d.users[pr.AuthorID].TeamID != team.ID || !inPeriod(filter, pr.CreatedAt) {
+				continue
+			}
+			if pr.StatusID == statusMerged {
+				ts.MergedPRs++
+			} else {
+				ts.OpenPRs++
+			}
+		}
+		for _, member := range d.members(team.ID) {
+			if member.IsActive {
+				ts.ActiveMembers++
+			} else {
+				ts.InactiveMembers++
+			}
+			ts.ReviewsPerMember = append(ts.ReviewsPerMember, domain.ReviewerStats{
+				UserID:      member.UserID,
+				Username:    member.Username,
+				ReviewCount: counts[member.ID],
+			})
+		}
+		sortReviewerStats(ts.ReviewsPerMember)
+		teams = append(teams, ts)
+	}
+	return teams, nil
+}
+
+func (r *StatsRepo) GetMemberWorkloads(ctx context.Context, filter domain.StatsFilter) ([]domain.MemberWorkload, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	total := make(map[int64]int)
+	removed := make(map[int64]int)
+	for _, assignments := range d.reviewers {
+		for _, a := range assignments {
+			if inPeriod(filter, a.assignedAt) {
+				total[a.reviewerID]++
+			}
+		}
+	}
+	for _, a := range d.history {
+		if inPeriod(filter, a.assignedAt) {
+			total[a.reviewerID]++
+			removed[a.reviewerID]++
+		}
+	}
+
+	var workloads []domain.MemberWorkload
+	for _, team := range d.sortedTeams() {
+		if !matchTeam(filter, team.Name) {
+			continue
+		}
+		members := d.members(team.ID)
+		sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+
+		for _, member := range members {
+			w := domain.MemberWorkload{
+				TeamName:       team.Name,
+				UserID:         member.UserID,
+				Username:       member.Username,
+				Assignments:    total[member.ID],
+				ReassignedAway: removed[member.ID],
+			}
+			if from := later(filter.From, member.CreatedAt); member.IsActive && from.Before(filter.To) {
+				w.ActiveSeconds = filter.To.Sub(from).Seconds()
+			}
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads, nil
+}
+
+// GetOpenPRGroups группирует открытые PR всех организаций
+func (r *StatsRepo) GetOpenPRGroups(context.Context) ([]domain.OpenPRGroup, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	counts := make(map[domain.OpenPRGroup]int)
+	for _, d := range r.store.data {
+		for _, pr := range d.prs {
+			if pr.StatusID != statusOpen {
+				continue
+			}
+			key := domain.OpenPRGroup{Reviewers: len(d.reviewers[pr.ID])}
+			if team, ok := d.teams[d.users[pr.AuthorID].TeamID]; ok {
+				key.HasTeam, key.MinReviewers, key.MaxReviewers = true, team.MinReviewers, team.MaxReviewers
+			}
+			counts[key]++
+		}
+	}
+
+	groups := make([]domain.OpenPRGroup, 0, len(counts))
+	for g, count := range counts {
+		g.Count = count
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (r *StatsRepo) GetReviewPairs(ctx context.Context, since time.Time) ([]domain.ReviewPairStats, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	type pair struct{ author, reviewer int64 }
+	counts := make(map[pair]int)
+	for _, a := range d.allAssignments() {
+		if !a.assignedAt.Before(since) {
+			counts[pair{d.prs[a.prID].AuthorID, a.reviewerID}]++
+		}
+	}
+
+	pairs := []domain.ReviewPairStats{}
+	for p, count := range counts {
+		author, reviewer := d.users[p.author], d.users[p.reviewer]
+		pairs = append(pairs, domain.ReviewPairStats{
+			AuthorID:         author.UserID,
+			AuthorUsername:   author.Username,
+			ReviewerID:       reviewer.UserID,
+			ReviewerUsername: reviewer.Username,
+			ReviewCount:      count,
+		})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].ReviewCount != pairs[j].ReviewCount {
+			return pairs[i].ReviewCount > pairs[j].ReviewCount
+		}
+		if pairs[i].AuthorID != pairs[j].AuthorID {
+			return pairs[i].AuthorID < pairs[j].AuthorID
+		}
+		return pairs[i].ReviewerID < pairs[j].ReviewerID
+	})
+	return pairs, nil
+}
+
+func (r *StatsRepo) GetLatency(ctx context.Context, filter domain.StatsFilter) (*domain.LatencyReport, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	report := &domain.LatencyReport{
+		From:      filter.From,
+		To:        filter.To,
+		Teams:     []domain.TeamLatency{},
+		Reviewers: []domain.ReviewerLatency{},
+	}
+
+	type samples struct{ review, merge []float64 }
+	overall := &samples{}
+	byTeam := make(map[string]*samples)
+	byReviewer := make(map[int64]*samples)
+
+	reviews := make(map[int64][]assignment)
+	for _, a := range d.allAssignments() {
+		if a.reviewedAt != nil {
+			reviews[a.prID] = append(reviews[a.prID], a)
+		}
+	}
+
+	for _, pr := range d.sortedPRs() {
+		teamName := d.teamName(pr.AuthorID)
+		if !inPeriod(filter, pr.CreatedAt) || !matchTeam(filter, teamName) {
+			continue
+		}
+
+		groups := []*samples{overall}
+		if teamName != "" {
+			if byTeam[teamName] == nil {
+				byTeam[teamName] = &samples{}
+			}
+			groups = append(groups, byTeam[teamName])
+		}
+
+		var firstReview *time.Time
+		for _, a := range reviews[pr.ID] {
+			if firstReview == nil || a.reviewedAt.Before(*firstReview) {
+				firstReview = a.reviewedAt
+			}
+
+			s := byReviewer[a.reviewerID]
+			if s == nil {
+				s = &samples{}
+				byReviewer[a.reviewerID] = s
+			}
+			s.review = append(s.review, a.reviewedAt.Sub(pr.CreatedAt).Seconds())
+			if pr.MergedAt != nil {
+				s.merge = append(s.merge, pr.MergedAt.Sub(pr.CreatedAt).Seconds())
+			}
+		}
+
+		for _, g := range groups {
+			if firstReview != nil {
+				g.review = append(g.review, firstReview.Sub(pr.CreatedAt).Seconds())
+			}
+			if pr.MergedAt != nil {
+				g.merge = append(g.merge, pr.MergedAt.Sub(pr.CreatedAt).Seconds())
+			}
+		}
+	}
+
+	report.Overall = domain.TeamLatency{TimeToFirstReview: latency(overall.review), TimeToMerge: latency(overall.merge)}
+	for _, team := range d.sortedTeams() {
+		if s, ok := byTeam[team.Name]; ok {
+			report.Teams = append(report.Teams, domain.TeamLatency{
+				TeamName:          team.Name,
+				TimeToFirstReview: latency(s.review),
+				TimeToMerge:       latency(s.merge),
+			})
+		}
+	}
+
+	var reviewers []domain.User
+	for id := range byReviewer {
+		reviewers = append(reviewers, *d.users[id])
+	}
+	sort.Slice(reviewers, func(i, j int) bool { return reviewers[i].UserID < reviewers[j].UserID })
+	for _, user := range reviewers {
+		s := byReviewer[user.ID]
+		report.Reviewers = append(report.Reviewers, domain.ReviewerLatency{
+			UserID:       user.UserID,
+			Username:     user.Username,
+			TimeToReview: latency(s.review),
+			TimeToMerge:  latency(s.merge),
+		})
+	}
+
+	return report, nil
+}
+
+// reviewCounts возвращает число текущих назначений каждого ревьювера за
+// период
+func (d *orgData) reviewCounts(filter domain.StatsFilter) map[int64]int {
+	counts := make(map[int64]int)
+	for _, assignments := range d.reviewers {
+		for _, a := range assignments {
+			if inPeriod(filter, a.assignedAt) {
+				counts[a.reviewerID]++
+			}
+		}
+	}
+	return counts
+}
+
+// allAssignments возвращает текущие и снятые назначения
+func (d *orgData) allAssignments() []assignment {
+	result := append([]assignment(nil), d.history...)
+	for _, assignments := range d.reviewers {
+		for _, a := range assignments {
+			result = append(result, *a)
+		}
+	}
+	return result
+}
+
+func (d *orgData) sortedTeams() []domain.Team {
+	teams := make([]domain.Team, 0, len(d.teams))
+	for _, team := range d.teams {
+		teams = append(teams, *team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams
+}
+
+func sortReviewerStats(stats []domain.ReviewerStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ReviewCount != stats[j].ReviewCount {
+			return stats[i].ReviewCount > stats[j].ReviewCount
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+}
+
+func inPeriod(filter domain.StatsFilter, t time.Time) bool {
+	return (filter.From.IsZero() || !t.Before(filter.From)) && (filter.To.IsZero() || t.Before(filter.To))
+}
+
+func matchTeam(filter domain.StatsFilter, teamName string) bool {
+	return filter.TeamName == "" || filter.TeamName == teamName
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// latency считает медиану и p90 так же, как percentile_cont в PostgreSQL:
+// с линейной интерполяцией между соседними значениями
+func latency(values []float64) domain.Latency {
+	l := domain.Latency{Count: len(values)}
+	if len(values) == 0 {
+		return l
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	percentile := func(p float64) *float64 {
+		pos := p * float64(len(sorted)-1)
+		lower := int(math.Floor(pos))
+		upper := int(math.Ceil(pos))
+		v := sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+		return &v
+	}
+	l.MedianSeconds = percentile(0.5)
+	l.P90Seconds = percentile(0.9)
+	return l
+}
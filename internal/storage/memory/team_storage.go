@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+)
+
+type TeamRepo struct {
+	store *Store
+}
+
+func NewTeamRepo(store *Store) *TeamRepo {
+	return &TeamRepo{store: store}
+}
+
+func (r *TeamRepo) Create(ctx context.Context, team *domain.Team) error {
+	const op = "memory.TeamRepo.Create"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	if d.teamByName(team.Name) != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrTeamExists)
+	}
+
+	team.ID = r.store.id()
+	team.Version = 1
+	team.CreatedAt = r.store.now()
+	d.teams[team.ID] = &domain.Team{
+		ID:                     team.ID,
+		Name:                   team.Name,
+		MinReviewers:           team.MinReviewers,
+		MaxReviewers:           team.MaxReviewers,
+		ReminderAfterMinutes:   team.ReminderAfterMinutes,
+		EscalationAfterMinutes: team.EscalationAfterMinutes,
+		Version:                team.Version,
+		CreatedAt:              team.CreatedAt,
+	}
+	return nil
+}
+
+// Update сохраняет настройки команды, если ее версия не изменилась с момента
+// чтения, и увеличивает версию. Иначе - ErrVersionConflict.
+func (r *TeamRepo) Update(ctx context.Context, team *domain.Team) error {
+	const op = "memory.TeamRepo.Update"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.org(ctx).teams[team.ID]
+	if !ok || stored.Version != team.Version {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+
+	stored.MinReviewers = team.MinReviewers
+	stored.MaxReviewers = team.MaxReviewers
+	stored.ReminderAfterMinutes = team.ReminderAfterMinutes
+	stored.EscalationAfterMinutes = team.EscalationAfterMinutes
+	stored.Version++
+
+	team.Version = stored.Version
+	return nil
+}
+
+func (r *TeamRepo) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	const op = "memory.TeamRepo.GetByName"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	team := r.store.org(ctx).teamByName(teamName)
+	if team == nil {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	result := *team
+	return &result, nil
+}
+
+func (r *TeamRepo) GetByID(ctx context.Context, teamID int64) (*domain.Team, error) {
+	const op = "memory.TeamRepo.GetByID"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	team, ok := r.store.org(ctx).teams[teamID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	result := *team
+	return &result, nil
+}
+
+func (r *TeamRepo) GetWithUsers(ctx context.Context, teamID int64) (*domain.Team, error) {
+	const op = "memory.TeamRepo.GetWithUsers"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	if _, ok := d.teams[teamID]; !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	team := d.teamWithUsers(teamID)
+	return &team, nil
+}
+
+// GetAllWithUsers возвращает команды по имени после after с активными
+// участниками. limit <= 0 - без ограничения.
+func (r *TeamRepo) GetAllWithUsers(ctx context.Context, after string, limit int) ([]domain.Team, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	teams := []domain.Team{}
+	for _, team := range d.sortedTeams() {
+		if team.Name <= after {
+			continue
+		}
+		if limit > 0 && len(teams) == limit {
+			break
+		}
+		var active []domain.User
+		for _, user := range d.members(team.ID) {
+			if user.IsActive {
+				active = append(active, user)
+			}
+		}
+		team.Users = active
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func (r *TeamRepo) ExistsByName(ctx context.Context, teamName string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.org(ctx).teamByName(teamName) != nil, nil
+}
+
+func (r *TeamRepo) GetBuddyTeams(ctx context.Context, teamID int64) ([]domain.Team, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	var buddies []domain.Team
+	for _, id := range d.buddies[teamID] {
+		buddies = append(buddies, *d.teams[id])
+	}
+	return buddies, nil
+}
+
+// SetBuddyTeams заменяет список команд-партнеров, если версия команды не
+// изменилась, и увеличивает ее
+func (r *TeamRepo) SetBuddyTeams(ctx context.Context, teamID int64, version int, buddyTeamIDs []int64) error {
+	const op = "memory.TeamRepo.SetBuddyTeams"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	team, ok := d.teams[teamID]
+	if !ok || team.Version != version {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+	for _, id := range buddyTeamIDs {
+		if _, ok := d.teams[id]; !ok || id == teamID {
+			return fmt.Errorf("%s: %w", op, storage.ErrInvalidBuddyTeam)
+		}
+	}
+
+	team.Version++
+	d.buddies[teamID] = append([]int64(nil), buddyTeamIDs...)
+	return nil
+}
+
+// teamWithUsers возвращает копию команды с участниками и партнерами
+func (d *orgData) teamWithUsers(teamID int64) domain.Team {
+	team := *d.teams[teamID]
+	team.Users = d.members(teamID)
+	for _, id := range d.buddies[teamID] {
+		team.BuddyTeams = append(team.BuddyTeams, d.teams[id].Name)
+	}
+	return team
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+)
+
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+func (r *UserRepo) Create(ctx context.Context, user *domain.User) error {
+	const op = "memory.UserRepo.Create"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	d := r.store.org(ctx)
+	if d.userByUserID(user.UserID) != nil {
+		return fmt.Errorf("%s: user %s already exists", op, user.UserID)
+	}
+
+	user.ID = r.store.id()
+	user.Version = 1
+	user.CreatedAt = r.store.now()
+	if user.Role == "" {
+		user.Role = domain.RoleMember
+	}
+	stored := *user
+	d.users[user.ID] = &stored
+	return nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, user *domain.User) error {
+	const op = "memory.UserRepo.Update"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored := r.store.org(ctx).userByUserID(user.UserID)
+	if stored == nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if stored.Version != user.Version {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+
+	stored.Username = user.Username
+	stored.TeamID = user.TeamID
+	stored.IsActive = user.IsActive
+	if user.Role != "" {
+		stored.Role = user.Role
+	}
+	stored.Version++
+
+	user.ID, user.Role, user.CreatedAt, user.Version = stored.ID, stored.Role, stored.CreatedAt, stored.Version
+	return nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	const op = "memory.UserRepo.GetByID"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.org(ctx).users[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	result := *user
+	return &result, nil
+}
+
+func (r *UserRepo) GetByUserID(ctx context.Context, userID string) (*domain.User, error) {
+	const op = "memory.UserRepo.GetByUserID"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user := r.store.org(ctx).userByUserID(userID)
+	if user == nil {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	result := *user
+	return &result, nil
+}
+
+func (r *UserRepo) GetByTeamID(ctx context.Context, teamID int64) ([]domain.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.org(ctx).members(teamID), nil
+}
+
+func (r *UserRepo) SetIsActive(ctx context.Context, userID string, isActive bool) error {
+	const op = "memory.UserRepo.SetIsActive"
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user := r.store.org(ctx).userByUserID(userID)
+	if user == nil {
+		return fmt.Errorf("%s: user not found", op)
+	}
+	user.IsActive = isActive
+	user.Version++
+	return nil
+}
+
+// GetByReviewerID возвращает страницу PR, где пользователь назначен
+// ревьювером, с авторами
+func (r *UserRepo) GetByReviewerID(ctx context.Context, userID string, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	filter.ReviewerID = userID
+	return r.store.org(ctx).listPRs(filter), nil
+}
+
+func (r *UserRepo) DeactivateByTeamID(ctx context.Context, teamID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.org(ctx).users {
+		if user.TeamID == teamID && user.IsActive {
+			user.IsActive = false
+			user.Version++
+		}
+	}
+	return nil
+}
+
+// members возвращает копии участников команды, упорядоченные по ID
+func (d *orgData) members(teamID int64) []domain.User {
+	var users []domain.User
+	for _, user := range d.users {
+		if user.TeamID == teamID {
+			users = append(users, *user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+// reviewedBy возвращает копии PR, где пользователь назначен ревьювером
+func (d *orgData) reviewedBy(userID string) []domain.PullRequest {
+	reviewer := d.userByUserID(userID)
+	if reviewer == nil {
+		return nil
+	}
+
+	var prs []domain.PullRequest
+	for _, pr := range d.sortedPRs() {
+		for _, a := range d.reviewers[pr.ID] {
+			if a.reviewerID == reviewer.ID {
+				prs = append(prs, pr)
+				break
+			}
+		}
+	}
+	return prs
+}
@@ -0,0 +1,132 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type IdempotencyRepo struct {
+	storage *Storage
+}
+
+func NewIdempotencyRepo(storage *Storage) *IdempotencyRepo {
+	return &IdempotencyRepo{storage: storage}
+}
+
+// Reserve вставляет запись или занимает место истекшей. Проверка срока и
+// вставка выполняются одним запросом, поэтому ключ достается только одному
+// из одновременных запросов.
+func (r *IdempotencyRepo) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	const op = "repository.IdempotencyRepo.Reserve"
+	const query = `
+        INSERT INTO pr_system.idempotency_keys (org_id, client, key, request_hash, created_at, expires_at) 
+        VALUES ($1, $2, $3, $4, $5, $6) 
+        ON CONFLICT (org_id, client, key) DO UPDATE 
+        SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL, 
+            response_body = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at 
+        WHERE pr_system.idempotency_keys.expires_at <= EXCLUDED.created_at
+        RETURNING org_id`
+
+	record.OrgID = tenant.OrgID(ctx)
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, record.OrgID, record.Client, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt,
+	).Scan(&record.OrgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return true, nil
+}
+
+func (r *IdempotencyRepo) Get(ctx context.Context, client, key string) (*domain.IdempotencyRecord, error) {
+	const op = "repository.IdempotencyRepo.Get"
+	const query = `
+        SELECT org_id, client, key, request_hash, COALESCE(status_code, 0), COALESCE(content_type, ''), 
+            response_body, created_at, expires_at 
+        FROM pr_system.idempotency_keys 
+        WHERE org_id = $1 AND client = $2 AND key = $3`
+
+	var record domain.IdempotencyRecord
+	err := r.storage.conn(ctx).QueryRow(ctx, query, tenant.OrgID(ctx), client, key).Scan(
+		&record.OrgID, &record.Client, &record.Key, &record.RequestHash, &record.StatusCode, &record.ContentType,
+		&record.ResponseBody, &record.CreatedAt, &record.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &record, nil
+}
+
+// Complete сохраняет ответ и продлевает запись до record.ExpiresAt
+func (r *IdempotencyRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	const op = "repository.IdempotencyRepo.Complete"
+	const query = `
+        UPDATE pr_system.idempotency_keys 
+        SET status_code = $4, content_type = $5, response_body = $6, expires_at = $7 
+        WHERE org_id = $1 AND client = $2 AND key = $3 AND request_hash = $8`
+
+	tag, err := r.storage.conn(ctx).Exec(
+		ctx, query, tenant.OrgID(ctx), record.Client, record.Key,
+		record.StatusCode, record.ContentType, record.ResponseBody, record.ExpiresAt, record.RequestHash,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// Extend продлевает запись, пока запрос еще выполняется. Завершенная или
+// занятая другим запросом запись не меняется.
+func (r *IdempotencyRepo) Extend(ctx context.Context, client, key, requestHash string, expiresAt time.Time) error {
+	const op = "repository.IdempotencyRepo.Extend"
+	const query = `
+        UPDATE pr_system.idempotency_keys 
+        SET expires_at = $5 
+        WHERE org_id = $1 AND client = $2 AND key = $3 AND request_hash = $4 AND status_code IS NULL`
+
+	tag, err := r.storage.conn(ctx).Exec(ctx, query, tenant.OrgID(ctx), client, key, requestHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepo) Delete(ctx context.Context, client, key string) error {
+	const op = "repository.IdempotencyRepo.Delete"
+	const query = `DELETE FROM pr_system.idempotency_keys WHERE org_id = $1 AND client = $2 AND key = $3`
+
+	if _, err := r.storage.conn(ctx).Exec(ctx, query, tenant.OrgID(ctx), client, key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет истекшие записи всех организаций
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	const op = "repository.IdempotencyRepo.DeleteExpired"
+	const query = `DELETE FROM pr_system.idempotency_keys WHERE expires_at <= $1`
+
+	tag, err := r.storage.conn(ctx).Exec(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return tag.RowsAffected(), nil
+}
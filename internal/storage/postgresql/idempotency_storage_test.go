@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepo(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	repo := NewIdempotencyRepo(s)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	record := func(hash string, at time.Time) *domain.IdempotencyRecord {
+		return &domain.IdempotencyRecord{
+			Client: "token:1", Key: "key-1", RequestHash: hash,
+			CreatedAt: at, ExpiresAt: at.Add(time.Minute),
+		}
+	}
+
+	reserved, err := repo.Reserve(ctx, record("hash-a", now))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// Пока резерв не истек, ключ занят
+	reserved, err = repo.Reserve(ctx, record("hash-b", now.Add(time.Second)))
+	require.NoError(t, err)
+	assert.False(t, reserved)
+
+	got, err := repo.Get(ctx, "token:1", "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "hash-a", got.RequestHash)
+	assert.False(t, got.Completed())
+
+	done := record("hash-a", now)
+	done.StatusCode = 201
+	done.ContentType = "application/json"
+	done.ResponseBody = []byte(`{"data":{}}`)
+	done.ExpiresAt = now.Add(time.Hour)
+	require.NoError(t, repo.Complete(ctx, done))
+
+	got, err = repo.Get(ctx, "token:1", "key-1")
+	require.NoError(t, err)
+	assert.True(t, got.Completed())
+	assert.Equal(t, 201, got.StatusCode)
+	assert.Equal(t, done.ResponseBody, got.ResponseBody)
+
+	// Другой клиент может использовать тот же ключ
+	other := record("hash-c", now)
+	other.Client = "token:2"
+	reserved, err = repo.Reserve(ctx, other)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	require.NoError(t, repo.Delete(ctx, "token:2", "key-1"))
+	_, err = repo.Get(ctx, "token:2", "key-1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Истекший ключ занимается заново
+	reserved, err = repo.Reserve(ctx, record("hash-d", now.Add(2*time.Hour)))
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	deleted, err := repo.DeleteExpired(ctx, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
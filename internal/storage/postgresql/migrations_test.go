@@ -0,0 +1,47 @@
+package postgresql
+
+import (
+	"context"
+	"io/fs"
+	"reviewer-appointment-service/migrations"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// docker-compose монтирует ./migrations в /docker-entrypoint-initdb.d, и
+// образ postgres выполняет файлы на пустой базе в алфавитном порядке: каждая
+// down-миграция идет перед своей up-миграцией. Все они должны проходить.
+func TestMigrations_DockerInitOrder(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := context.Background()
+	dropSchema := func() {
+		_, err := s.DB.Exec(ctx, `DROP SCHEMA IF EXISTS pr_system CASCADE`)
+		require.NoError(t, err)
+	}
+	dropSchema()
+	// Следующие тесты создают схему заново
+	defer dropSchema()
+
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	for _, name := range files {
+		body, err := fs.ReadFile(migrations.FS, name)
+		require.NoError(t, err)
+
+		_, err = s.DB.Exec(ctx, string(body))
+		require.NoError(t, err, name)
+	}
+
+	latest, err := migrations.LatestVersion()
+	require.NoError(t, err)
+	version, err := s.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+}
@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type OrgRepo struct {
+	storage *Storage
+}
+
+func NewOrgRepo(storage *Storage) *OrgRepo {
+	return &OrgRepo{storage: storage}
+}
+
+func (r *OrgRepo) Create(ctx context.Context, org *domain.Organization) error {
+	const op = "repository.OrgRepo.Create"
+	const query = `
+        INSERT INTO pr_system.organizations (slug, name) 
+        VALUES ($1, $2) 
+        RETURNING id, created_at`
+
+	err := r.storage.conn(ctx).QueryRow(ctx, query, org.Slug, org.Name).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *OrgRepo) GetBySlug(ctx context.Context, slug string) (*domain.Organization, error) {
+	const op = "repository.OrgRepo.GetBySlug"
+	const query = `
+        SELECT id, slug, name, created_at 
+        FROM pr_system.organizations 
+        WHERE slug = $1`
+
+	var org domain.Organization
+	err := r.storage.conn(ctx).QueryRow(ctx, query, slug).Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &org, nil
+}
+
+func (r *OrgRepo) List(ctx context.Context) ([]domain.Organization, error) {
+	const op = "repository.OrgRepo.List"
+	const query = `SELECT id, slug, name, created_at FROM pr_system.organizations ORDER BY id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	orgs := []domain.Organization{}
+	for rows.Next() {
+		var org domain.Organization
+		if err := rows.Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		orgs = append(orgs, org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return orgs, nil
+}
@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgRepo_CreateAndGetBySlug(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	repo := NewOrgRepo(s)
+
+	def, err := repo.GetBySlug(ctx, tenant.DefaultSlug)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.DefaultOrgID, def.ID)
+
+	org := &domain.Organization{Slug: "acme", Name: "Acme"}
+	require.NoError(t, repo.Create(ctx, org))
+	assert.NotEqual(t, tenant.DefaultOrgID, org.ID)
+
+	got, err := repo.GetBySlug(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, got.ID)
+
+	_, err = repo.GetBySlug(ctx, "globex")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	orgs, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, orgs, 2)
+}
+
+// seedOrg создает в организации ctx команду backend, пользователей и PR
+// автора author с ревьювером reviewer
+func seedOrg(t *testing.T, s *Storage, ctx context.Context, author, reviewer, prID string) (*domain.Team, *domain.User, *domain.User, *domain.PullRequest) {
+	t.Helper()
+
+	team := &domain.Team{Name: "backend"}
+	require.NoError(t, NewTeamRepo(s).Create(ctx, team))
+
+	users := NewUserStorage(s)
+	a := &domain.User{UserID: author, Username: author, IsActive: true, TeamID: team.ID}
+	require.NoError(t, users.Create(ctx, a))
+	r := &domain.User{UserID: reviewer, Username: reviewer, IsActive: true, TeamID: team.ID}
+	require.NoError(t, users.Create(ctx, r))
+
+	prs := NewPRRepo(s)
+	pr := &domain.PullRequest{PullRequestID: prID, PullRequestName: prID, AuthorID: a.ID, StatusID: 1}
+	require.NoError(t, prs.Create(ctx, pr))
+	require.NoError(t, prs.AddReviewer(ctx, pr.ID, r.ID, domain.AssignReasonAuto))
+
+	return team, a, r, pr
+}
+
+func TestTenantIsolation(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	acme := &domain.Organization{Slug: "acme", Name: "Acme"}
+	require.NoError(t, NewOrgRepo(s).Create(context.Background(), acme))
+
+	ctxA := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	ctxB := tenant.WithOrg(context.Background(), acme.ID)
+
+	users := NewUserStorage(s)
+	teams := NewTeamRepo(s)
+	prs := NewPRRepo(s)
+
+	// Имена команд, user_id и pull_request_id совпадают в обеих организациях
+	teamA, authorA, reviewerA, prA := seedOrg(t, s, ctxA, "u1", "u2", "pr-1")
+	teamB, authorB, _, prB := seedOrg(t, s, ctxB, "u1", "u2", "pr-1")
+	onlyA := &domain.User{UserID: "only-a", Username: "only-a", IsActive: true, TeamID: teamA.ID}
+	require.NoError(t, users.Create(ctxA, onlyA))
+
+	t.Run("same identifiers resolve within the organization", func(t *testing.T) {
+		user, err := users.GetByUserID(ctxB, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, authorB.ID, user.ID)
+		assert.NotEqual(t, authorA.ID, user.ID)
+
+		team, err := teams.GetByName(ctxB, "backend")
+		require.NoError(t, err)
+		assert.Equal(t, teamB.ID, team.ID)
+
+		pr, err := prs.GetByPRID(ctxB, "pr-1")
+		require.NoError(t, err)
+		assert.Equal(t, prB.ID, pr.ID)
+	})
+
+	t.Run("another organization's data cannot be read", func(t *testing.T) {
+		_, err := users.GetByUserID(ctxB, "only-a")
+		assert.Error(t, err)
+		_, err = users.GetByID(ctxB, onlyA.ID)
+		assert.Error(t, err)
+		_, err = teams.GetByID(ctxB, teamA.ID)
+		assert.Error(t, err)
+		_, err = teams.GetWithUsers(ctxB, teamA.ID)
+		assert.Error(t, err)
+
+		members, err := users.GetByTeamID(ctxB, teamA.ID)
+		require.NoError(t, err)
+		assert.Empty(t, members)
+
+		reviewers, err := prs.GetReviewers(ctxB, prA.ID)
+		require.NoError(t, err)
+		assert.Empty(t, reviewers)
+
+		assigned, err := prs.GetByReviewerID(ctxB, "only-a")
+		require.NoError(t, err)
+		assert.Empty(t, assigned)
+
+		total, err := NewStatsRepo(s).GetTotalUsers(ctxB, domain.StatsFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("another organization's users cannot be assigned", func(t *testing.T) {
+		// Ревьювер чужой организации на свой PR
+		assert.Error(t, prs.AddReviewer(ctxB, prB.ID, onlyA.ID, domain.AssignReasonManual))
+		// Свой ревьювер на PR чужой организации
+		assert.Error(t, prs.AddReviewer(ctxA, prB.ID, onlyA.ID, domain.AssignReasonManual))
+
+		reviewers, err := prs.GetReviewers(ctxB, prB.ID)
+		require.NoError(t, err)
+		for _, r := range reviewers {
+			assert.NotEqual(t, onlyA.ID, r.ID)
+		}
+
+		assert.Error(t, prs.RemoveReviewer(ctxB, prA.ID, reviewerA.ID))
+		assert.Error(t, users.SetIsActive(ctxB, "only-a", false))
+
+		// Команда из чужой организации не может стать партнером
+		assert.Error(t, teams.SetBuddyTeams(ctxB, teamB.ID, teamB.Version, []int64{teamA.ID}))
+	})
+
+	t.Run("tokens are listed and revoked within the organization", func(t *testing.T) {
+		tokens := NewTokenRepo(s)
+		token := &domain.APIToken{Name: "a", Hash: "a", Prefix: "ras_a", Scopes: []string{domain.ScopeRead}}
+		require.NoError(t, tokens.Create(ctxA, token))
+		assert.Equal(t, tenant.DefaultOrgID, token.OrgID)
+
+		list, err := tokens.List(ctxB)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+
+		assert.ErrorIs(t, tokens.Revoke(ctxB, token.ID, time.Now()), storage.ErrNotFound)
+	})
+}
@@ -3,10 +3,15 @@ package postgresql
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"reviewer-appointment-service/internal/config"
+	"reviewer-appointment-service/internal/logger"
+	"reviewer-appointment-service/internal/models/domain"
 	"reviewer-appointment-service/internal/storage"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,16 +19,63 @@ type Storage struct {
 	DB *pgxpool.Pool
 }
 
+// querier - общие методы пула и транзакции, через которые работают
+// репозитории
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type txCtxKey struct{}
+
+// conn возвращает транзакцию, начатую InTx, или пул, если ctx не относится
+// к транзакции
+func (s *Storage) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return s.DB
+}
+
+// InTx выполняет fn в транзакции: репозитории, вызванные с контекстом fn,
+// работают в ней. Ошибка fn откатывает транзакцию. Вложенный вызов
+// выполняется во внешней транзакции.
+func (s *Storage) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "storage.postgresql.InTx"
+
+	if _, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txCtxKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
 func NewStorage(cfg *config.Config, ctx context.Context) (*Storage, error) {
 	const op = "storage.postgresql.NewStorage"
 
 	connectString := storage.GetDBConnectionString(cfg)
-	log.Printf("Connection string: %s", connectString)
+	slog.Debug("connecting to PostgreSQL", "dsn", logger.RedactDSN(connectString))
 
 	poolConfig, err := pgxpool.ParseConfig(connectString)
 	if err != nil {
 		return nil, fmt.Errorf("%s parse config error: %w", op, err)
 	}
+	poolConfig.ConnConfig.Tracer = newQueryTracer()
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -37,6 +89,70 @@ func NewStorage(cfg *config.Config, ctx context.Context) (*Storage, error) {
 	return &Storage{DB: pool}, nil
 }
 
+// TryAdvisoryLock пытается взять сессионную advisory-блокировку key на
+// выделенном соединении пула. Если блокировка получена, соединение держится
+// до вызова unlock, который снимает блокировку и возвращает соединение в пул.
+func (s *Storage) TryAdvisoryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error) {
+	const op = "storage.postgresql.TryAdvisoryLock"
+
+	conn, err := s.DB.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock = func() {
+		// Блокировка снимается и при закрытии сессии, поэтому при ошибке
+		// соединение уничтожается, а не возвращается в пул
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			slog.Error("failed to release advisory lock", "op", op, "key", key, "error", err)
+			conn.Conn().Close(unlockCtx)
+		}
+		conn.Release()
+	}
+
+	return unlock, true, nil
+}
+
+// Ping проверяет доступность БД
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.DB.Ping(ctx)
+}
+
+// SchemaVersion возвращает последнюю примененную версию миграций
+func (s *Storage) SchemaVersion(ctx context.Context) (int64, error) {
+	const op = "storage.postgresql.SchemaVersion"
+
+	var version int64
+	err := s.DB.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM pr_system.schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return version, nil
+}
+
+// PoolStats возвращает текущее состояние пула соединений
+func (s *Storage) PoolStats() domain.PoolStats {
+	stat := s.DB.Stat()
+	return domain.PoolStats{
+		AcquiredConns: stat.AcquiredConns(),
+		IdleConns:     stat.IdleConns(),
+		TotalConns:    stat.TotalConns(),
+		MaxConns:      stat.MaxConns(),
+	}
+}
+
 func (s *Storage) Close() {
 	if s.DB != nil {
 		s.DB.Close()
@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"reviewer-appointment-service/internal/tenant"
+	"reviewer-appointment-service/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_HealthChecks(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+
+	t.Run("ping", func(t *testing.T) {
+		assert.NoError(t, storage.Ping(ctx))
+	})
+
+	t.Run("schema version", func(t *testing.T) {
+		version, err := storage.SchemaVersion(ctx)
+		require.NoError(t, err)
+		latest, err := migrations.LatestVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latest, version)
+	})
+
+	t.Run("pool stats", func(t *testing.T) {
+		stats := storage.PoolStats()
+		assert.Greater(t, stats.MaxConns, int32(0))
+	})
+}
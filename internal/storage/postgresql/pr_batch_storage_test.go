@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPRRepo_CreateBatch(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	teams := NewTeamRepo(s)
+	users := NewUserStorage(s)
+	prs := NewPRRepo(s)
+
+	team := &domain.Team{Name: "backend"}
+	require.NoError(t, teams.Create(ctx, team))
+
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: team.ID}
+	reviewer := &domain.User{UserID: "u2", Username: "Reviewer", IsActive: true, TeamID: team.ID}
+	require.NoError(t, users.Create(ctx, author))
+	require.NoError(t, users.Create(ctx, reviewer))
+
+	planned := func(prID string) domain.PlannedPR {
+		return domain.PlannedPR{
+			PullRequest: &domain.PullRequest{
+				PullRequestID:   prID,
+				PullRequestName: "PR " + prID,
+				AuthorID:        author.ID,
+				StatusID:        1,
+			},
+			Reviewers: []domain.PlannedReviewer{{ReviewerID: reviewer.ID, Reason: domain.AssignReasonAuto}},
+		}
+	}
+
+	t.Run("creates PRs with reviewers", func(t *testing.T) {
+		batch := []domain.PlannedPR{planned("pr-1"), planned("pr-2")}
+		require.NoError(t, prs.CreateBatch(ctx, batch))
+
+		for _, p := range batch {
+			assert.NotZero(t, p.PullRequest.ID)
+			assert.False(t, p.PullRequest.CreatedAt.IsZero())
+
+			pr, err := prs.GetByPRID(ctx, p.PullRequest.PullRequestID)
+			require.NoError(t, err)
+			require.Len(t, pr.Reviewers, 1)
+			assert.Equal(t, "u2", pr.Reviewers[0].UserID)
+		}
+	})
+
+	t.Run("existing PR rolls back the whole batch", func(t *testing.T) {
+		err := prs.CreateBatch(ctx, []domain.PlannedPR{planned("pr-3"), planned("pr-1")})
+		assert.ErrorIs(t, err, storage.ErrPRExists)
+
+		_, err = prs.GetByPRID(ctx, "pr-3")
+		assert.Error(t, err)
+	})
+
+	t.Run("joins the transaction of the context", func(t *testing.T) {
+		errStop := errors.New("stop")
+		err := s.InTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, prs.CreateBatch(ctx, []domain.PlannedPR{planned("pr-4")}))
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+
+		_, err = prs.GetByPRID(ctx, "pr-4")
+		assert.Error(t, err)
+	})
+}
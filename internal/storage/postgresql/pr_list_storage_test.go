@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPRRepo_List(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	teams := NewTeamRepo(s)
+	users := NewUserStorage(s)
+	prs := NewPRRepo(s)
+
+	backend := &domain.Team{Name: "backend"}
+	frontend := &domain.Team{Name: "frontend"}
+	require.NoError(t, teams.Create(ctx, backend))
+	require.NoError(t, teams.Create(ctx, frontend))
+
+	alice := &domain.User{UserID: "u1", Username: "Alice", IsActive: true, TeamID: backend.ID}
+	bob := &domain.User{UserID: "u2", Username: "Bob", IsActive: true, TeamID: frontend.ID}
+	require.NoError(t, users.Create(ctx, alice))
+	require.NoError(t, users.Create(ctx, bob))
+
+	var created []*domain.PullRequest
+	for _, pr := range []*domain.PullRequest{
+		{PullRequestID: "pr-1", PullRequestName: "One", AuthorID: alice.ID, StatusID: 1},
+		{PullRequestID: "pr-2", PullRequestName: "Two", AuthorID: bob.ID, StatusID: 1},
+		{PullRequestID: "pr-3", PullRequestName: "Three", AuthorID: alice.ID, StatusID: 2},
+	} {
+		require.NoError(t, prs.Create(ctx, pr))
+		created = append(created, pr)
+	}
+	require.NoError(t, prs.AddReviewer(ctx, created[0].ID, bob.ID, domain.AssignReasonAuto))
+
+	ids := func(list []domain.PullRequest) []string {
+		var result []string
+		for _, pr := range list {
+			result = append(result, pr.PullRequestID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.PRFilter
+		want   []string
+	}{
+		{"newest first by default", domain.PRFilter{}, []string{"pr-3", "pr-2", "pr-1"}},
+		{"oldest first", domain.PRFilter{Sort: domain.PRSortCreatedAsc}, []string{"pr-1", "pr-2", "pr-3"}},
+		{"by author", domain.PRFilter{AuthorID: "u1"}, []string{"pr-3", "pr-1"}},
+		{"by status", domain.PRFilter{StatusID: 1}, []string{"pr-2", "pr-1"}},
+		{"by author team", domain.PRFilter{TeamName: "frontend"}, []string{"pr-2"}},
+		{"by reviewer", domain.PRFilter{ReviewerID: "u2"}, []string{"pr-1"}},
+		{"created after", domain.PRFilter{CreatedAfter: created[0].CreatedAt}, []string{"pr-3", "pr-2"}},
+		{"limit returns one extra row", domain.PRFilter{Limit: 1}, []string{"pr-3", "pr-2"}},
+		{"after cursor", domain.PRFilter{After: &domain.PRCursor{CreatedAt: created[2].CreatedAt, ID: created[2].ID}}, []string{"pr-2", "pr-1"}},
+		{"after cursor ascending", domain.PRFilter{Sort: domain.PRSortCreatedAsc, After: &domain.PRCursor{CreatedAt: created[0].CreatedAt, ID: created[0].ID}}, []string{"pr-2", "pr-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := prs.List(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(list))
+			for _, pr := range list {
+				require.NotNil(t, pr.Author)
+			}
+		})
+	}
+}
@@ -2,9 +2,15 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type PRRepo struct {
@@ -18,13 +24,13 @@ func NewPRRepo(storage *Storage) *PRRepo {
 func (r *PRRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
 	const op = "repository.PRRepo.Create"
 	const query = `
-        INSERT INTO pr_system.pull_requests (pull_request_id, pull_request_name, author_id, status_id) 
-        VALUES ($1, $2, $3, $4) 
-        RETURNING id, created_at`
+        INSERT INTO pr_system.pull_requests (pull_request_id, pull_request_name, author_id, status_id, org_id) 
+        VALUES ($1, $2, $3, $4, $5) 
+        RETURNING id, version, created_at`
 
-	err := r.storage.DB.QueryRow(
-		ctx, query, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.StatusID,
-	).Scan(&pr.ID, &pr.CreatedAt)
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.StatusID, tenant.OrgID(ctx),
+	).Scan(&pr.ID, &pr.Version, &pr.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -32,35 +38,119 @@ func (r *PRRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
 	return nil
 }
 
+// CreateBatch сохраняет PR и их ревьюверов в одной транзакции (или в
+// транзакции контекста, если она есть). PR с уже занятым ID откатывает всю
+// транзакцию с ErrPRExists.
+func (r *PRRepo) CreateBatch(ctx context.Context, prs []domain.PlannedPR) error {
+	const op = "repository.PRRepo.CreateBatch"
+	const insertPR = `
+        INSERT INTO pr_system.pull_requests (pull_request_id, pull_request_name, author_id, status_id, org_id) 
+        VALUES ($1, $2, $3, $4, $5) 
+        ON CONFLICT (org_id, pull_request_id) DO NOTHING 
+        RETURNING id, version, created_at`
+	const insertReviewer = `
+        INSERT INTO pr_system.pr_reviewers (pr_id, reviewer_id, reason, org_id) 
+        VALUES ($1, $2, $3, $4)`
+
+	orgID := tenant.OrgID(ctx)
+	return r.storage.InTx(ctx, func(ctx context.Context) error {
+		conn := r.storage.conn(ctx)
+		for _, planned := range prs {
+			pr := planned.PullRequest
+			err := conn.QueryRow(
+				ctx, insertPR, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.StatusID, orgID,
+			).Scan(&pr.ID, &pr.Version, &pr.CreatedAt)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%s: %s: %w", op, pr.PullRequestID, storage.ErrPRExists)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			for _, reviewer := range planned.Reviewers {
+				_, err := conn.Exec(ctx, insertReviewer, pr.ID, reviewer.ReviewerID, reviewer.Reason, orgID)
+				if err != nil {
+					return fmt.Errorf("%s: %w", op, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Update сохраняет PR, если его версия не изменилась с момента чтения
+// (pr.Version), и увеличивает версию. Иначе - ErrVersionConflict.
 func (r *PRRepo) Update(ctx context.Context, pr *domain.PullRequest) error {
 	const op = "repository.PRRepo.Update"
 	const query = `
-        UPDATE pr_system.pull_requests 
-        SET pull_request_name = $1, status_id = $2, merged_at = $3 
-        WHERE pull_request_id = $4 
-        RETURNING id, author_id, created_at`
+        WITH old AS (
+            SELECT id, version FROM pr_system.pull_requests 
+            WHERE pull_request_id = $4 AND org_id = $5 
+            FOR UPDATE
+        ), updated AS (
+            UPDATE pr_system.pull_requests p 
+            SET pull_request_name = $1, status_id = $2, merged_at = $3, version = p.version + 1 
+            FROM old 
+            WHERE p.id = old.id AND old.version = $6 
+            RETURNING p.id, p.author_id, p.created_at, p.version
+        )
+        SELECT old.id, updated.author_id, updated.created_at, updated.version 
+        FROM old LEFT JOIN updated ON updated.id = old.id`
+
+	var (
+		authorID  *int64
+		createdAt *time.Time
+		version   *int
+	)
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, pr.PullRequestName, pr.StatusID, pr.MergedAt, pr.PullRequestID, tenant.OrgID(ctx), pr.Version,
+	).Scan(&pr.ID, &authorID, &createdAt, &version)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if version == nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
 
-	err := r.storage.DB.QueryRow(
-		ctx, query, pr.PullRequestName, pr.StatusID, pr.MergedAt, pr.PullRequestID,
-	).Scan(&pr.ID, &pr.AuthorID, &pr.CreatedAt)
+	pr.AuthorID, pr.CreatedAt, pr.Version = *authorID, *createdAt, *version
+	return nil
+}
 
+// BumpVersion увеличивает версию PR, если она равна version. Вызывается
+// перед изменением ревьюверов, чтобы из двух одновременных изменений одного
+// PR прошло только одно, а второе получило ErrVersionConflict.
+func (r *PRRepo) BumpVersion(ctx context.Context, prID int64, version int) error {
+	const op = "repository.PRRepo.BumpVersion"
+	const query = `
+        UPDATE pr_system.pull_requests 
+        SET version = version + 1 
+        WHERE id = $1 AND org_id = $2 AND version = $3`
+
+	tag, err := r.storage.conn(ctx).Exec(ctx, query, prID, tenant.OrgID(ctx), version)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
 	return nil
 }
 
 func (r *PRRepo) GetByPRID(ctx context.Context, prID string) (*domain.PullRequest, error) {
 	const op = "repository.PRRepo.GetByPRID"
 	const query = `
-        SELECT id, pull_request_id, pull_request_name, author_id, status_id, merged_at, created_at 
+        SELECT id, pull_request_id, pull_request_name, author_id, status_id, merged_at, version, created_at 
         FROM pr_system.pull_requests 
-        WHERE pull_request_id = $1`
+        WHERE pull_request_id = $1 AND org_id = $2`
 
 	var pr domain.PullRequest
-	err := r.storage.DB.QueryRow(ctx, query, prID).Scan(
+	err := r.storage.conn(ctx).QueryRow(ctx, query, prID, tenant.OrgID(ctx)).Scan(
 		&pr.ID, &pr.PullRequestID, &pr.PullRequestName,
-		&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.CreatedAt,
+		&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.Version, &pr.CreatedAt,
 	)
 
 	if err != nil {
@@ -73,6 +163,12 @@ func (r *PRRepo) GetByPRID(ctx context.Context, prID string) (*domain.PullReques
 	}
 	pr.Reviewers = reviewers
 
+	assignments, err := r.GetAssignments(ctx, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get assignments: %w", op, err)
+	}
+	pr.Assignments = assignments
+
 	return &pr, nil
 }
 
@@ -81,13 +177,13 @@ func (r *PRRepo) GetByReviewerID(ctx context.Context, reviewerID string) ([]doma
 	const query = `
         SELECT 
             pr.id, pr.pull_request_id, pr.pull_request_name, 
-            pr.author_id, pr.status_id, pr.merged_at, pr.created_at
+            pr.author_id, pr.status_id, pr.merged_at, pr.version, pr.created_at
         FROM pr_system.pull_requests pr
         JOIN pr_system.pr_reviewers prr ON pr.id = prr.pr_id
         JOIN pr_system.users u ON prr.reviewer_id = u.id
-        WHERE u.user_id = $1`
+        WHERE u.user_id = $1 AND u.org_id = $2`
 
-	rows, err := r.storage.DB.Query(ctx, query, reviewerID)
+	rows, err := r.storage.conn(ctx).Query(ctx, query, reviewerID, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -98,7 +194,7 @@ func (r *PRRepo) GetByReviewerID(ctx context.Context, reviewerID string) ([]doma
 		var pr domain.PullRequest
 		err := rows.Scan(
 			&pr.ID, &pr.PullRequestID, &pr.PullRequestName,
-			&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.CreatedAt,
+			&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.Version, &pr.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -118,23 +214,24 @@ func (r *PRRepo) GetOpenPRsByUserIDs(ctx context.Context, userIDs []string) ([]d
 
 	// Создаем плейсхолдеры для IN clause
 	placeholders := make([]string, len(userIDs))
-	args := make([]interface{}, len(userIDs))
+	args := make([]interface{}, len(userIDs), len(userIDs)+1)
 	for i, id := range userIDs {
 		placeholders[i] = fmt.Sprintf("$%d", i+1)
 		args[i] = id
 	}
+	args = append(args, tenant.OrgID(ctx))
 
 	query := fmt.Sprintf(`
         SELECT 
             pr.id, pr.pull_request_id, pr.pull_request_name, 
-            pr.author_id, pr.status_id, pr.merged_at, pr.created_at,
-            u.id, u.user_id, u.username, u.is_active, u.team_id, u.created_at
+            pr.author_id, pr.status_id, pr.merged_at, pr.version, pr.created_at,
+            u.id, u.user_id, u.username, u.is_active, u.team_id, u.role, u.created_at
         FROM pr_system.pull_requests pr
         JOIN pr_system.users u ON pr.author_id = u.id
-        WHERE u.user_id IN (%s) AND pr.status_id = 1`, // status_id = 1 для открытых PR
-		strings.Join(placeholders, ","))
+        WHERE u.user_id IN (%s) AND u.org_id = $%d AND pr.status_id = 1`, // status_id = 1 для открытых PR
+		strings.Join(placeholders, ","), len(args))
 
-	rows, err := r.storage.DB.Query(ctx, query, args...)
+	rows, err := r.storage.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -146,8 +243,8 @@ func (r *PRRepo) GetOpenPRsByUserIDs(ctx context.Context, userIDs []string) ([]d
 		var author domain.User
 		err := rows.Scan(
 			&pr.ID, &pr.PullRequestID, &pr.PullRequestName,
-			&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.CreatedAt,
-			&author.ID, &author.UserID, &author.Username, &author.IsActive, &author.TeamID, &author.CreatedAt,
+			&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.Version, &pr.CreatedAt,
+			&author.ID, &author.UserID, &author.Username, &author.IsActive, &author.TeamID, &author.Role, &author.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -159,27 +256,33 @@ func (r *PRRepo) GetOpenPRsByUserIDs(ctx context.Context, userIDs []string) ([]d
 	return prs, nil
 }
 
-func (r *PRRepo) AddReviewer(ctx context.Context, prID int64, reviewerID int64) error {
+func (r *PRRepo) AddReviewer(ctx context.Context, prID int64, reviewerID int64, reason string) error {
 	const op = "repository.PRRepo.AddReviewer"
 	const query = `
-        INSERT INTO pr_system.pr_reviewers (pr_id, reviewer_id) 
-        VALUES ($1, $2) 
+        INSERT INTO pr_system.pr_reviewers (pr_id, reviewer_id, reason, org_id) 
+        VALUES ($1, $2, $3, $4) 
         ON CONFLICT (pr_id, reviewer_id) DO NOTHING`
 
-	_, err := r.storage.DB.Exec(ctx, query, prID, reviewerID)
+	_, err := r.storage.conn(ctx).Exec(ctx, query, prID, reviewerID, reason, tenant.OrgID(ctx))
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
 
+// RemoveReviewer снимает ревьювера с PR и переносит назначение в историю
 func (r *PRRepo) RemoveReviewer(ctx context.Context, prID int64, reviewerID int64) error {
 	const op = "repository.PRRepo.RemoveReviewer"
 	const query = `
-        DELETE FROM pr_system.pr_reviewers 
-        WHERE pr_id = $1 AND reviewer_id = $2`
-
-	result, err := r.storage.DB.Exec(ctx, query, prID, reviewerID)
+        WITH removed AS (
+            DELETE FROM pr_system.pr_reviewers 
+            WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $3
+            RETURNING pr_id, reviewer_id, reason, assigned_at, reviewed_at, org_id
+        )
+        INSERT INTO pr_system.pr_reviewer_history (pr_id, reviewer_id, reason, assigned_at, reviewed_at, org_id)
+        SELECT pr_id, reviewer_id, reason, assigned_at, reviewed_at, org_id FROM removed`
+
+	result, err := r.storage.conn(ctx).Exec(ctx, query, prID, reviewerID, tenant.OrgID(ctx))
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -192,15 +295,36 @@ func (r *PRRepo) RemoveReviewer(ctx context.Context, prID int64, reviewerID int6
 	return nil
 }
 
+// MarkReviewed фиксирует первое действие ревьювера по PR. Повторные вызовы
+// не меняют время.
+func (r *PRRepo) MarkReviewed(ctx context.Context, prID int64, reviewerID int64, at time.Time) error {
+	const op = "repository.PRRepo.MarkReviewed"
+	const query = `
+        UPDATE pr_system.pr_reviewers 
+        SET reviewed_at = COALESCE(reviewed_at, $3) 
+        WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $4`
+
+	result, err := r.storage.conn(ctx).Exec(ctx, query, prID, reviewerID, at, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: reviewer not found for this PR", op)
+	}
+
+	return nil
+}
+
 func (r *PRRepo) GetReviewers(ctx context.Context, prID int64) ([]domain.User, error) {
 	const op = "repository.PRRepo.GetReviewers"
 	const query = `
-        SELECT u.id, u.user_id, u.username, u.is_active, u.team_id, u.created_at
+        SELECT u.id, u.user_id, u.username, u.is_active, u.team_id, u.role, u.created_at
         FROM pr_system.pr_reviewers prr
         JOIN pr_system.users u ON prr.reviewer_id = u.id
-        WHERE prr.pr_id = $1`
+        WHERE prr.pr_id = $1 AND prr.org_id = $2`
 
-	rows, err := r.storage.DB.Query(ctx, query, prID)
+	rows, err := r.storage.conn(ctx).Query(ctx, query, prID, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -211,7 +335,7 @@ func (r *PRRepo) GetReviewers(ctx context.Context, prID int64) ([]domain.User, e
 		var reviewer domain.User
 		err := rows.Scan(
 			&reviewer.ID, &reviewer.UserID, &reviewer.Username,
-			&reviewer.IsActive, &reviewer.TeamID, &reviewer.CreatedAt,
+			&reviewer.IsActive, &reviewer.TeamID, &reviewer.Role, &reviewer.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -221,3 +345,158 @@ func (r *PRRepo) GetReviewers(ctx context.Context, prID int64) ([]domain.User, e
 
 	return reviewers, nil
 }
+
+func (r *PRRepo) GetAssignments(ctx context.Context, prID int64) ([]domain.ReviewerAssignment, error) {
+	const op = "repository.PRRepo.GetAssignments"
+	const query = `
+        SELECT 
+            u.user_id, u.username, u.team_id, prr.reason,
+            prr.reason = 'BORROWED' AS borrowed,
+            prr.assigned_at, prr.reviewed_at
+        FROM pr_system.pr_reviewers prr
+        JOIN pr_system.users u ON prr.reviewer_id = u.id
+        WHERE prr.pr_id = $1 AND prr.org_id = $2
+        ORDER BY prr.assigned_at, prr.id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, prID, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var assignments []domain.ReviewerAssignment
+	for rows.Next() {
+		var a domain.ReviewerAssignment
+		err := rows.Scan(&a.UserID, &a.Username, &a.TeamID, &a.Reason, &a.Borrowed, &a.AssignedAt, &a.ReviewedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	return assignments, nil
+}
+
+// GetPairCounts возвращает число назначений каждого ревьювера на PR автора
+// начиная с момента since, включая снятых и переназначенных ревьюверов
+func (r *PRRepo) GetPairCounts(ctx context.Context, authorID int64, since time.Time) (map[int64]int, error) {
+	const op = "repository.PRRepo.GetPairCounts"
+	const query = `
+        WITH assignments AS (
+            SELECT pr_id, reviewer_id FROM pr_system.pr_reviewers 
+            WHERE org_id = $3 AND assigned_at >= $2
+            UNION ALL
+            SELECT pr_id, reviewer_id FROM pr_system.pr_reviewer_history 
+            WHERE org_id = $3 AND assigned_at >= $2
+        )
+        SELECT a.reviewer_id, COUNT(*)
+        FROM assignments a
+        JOIN pr_system.pull_requests pr ON a.pr_id = pr.id
+        WHERE pr.author_id = $1
+        GROUP BY a.reviewer_id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, authorID, since, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var reviewerID int64
+		var count int
+		if err := rows.Scan(&reviewerID, &count); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		counts[reviewerID] = count
+	}
+
+	return counts, nil
+}
+
+// List возвращает страницу PR с авторами по фильтру
+func (r *PRRepo) List(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	const op = "repository.PRRepo.List"
+
+	prs, err := listPRs(ctx, r.storage, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return prs, nil
+}
+
+// listPRs выбирает PR организации контекста с авторами. Фильтры передаются
+// параметрами (пустое значение не ограничивает выборку), в текст запроса
+// подставляется только направление сортировки. Страница продолжается после
+// filter.After по ключу (created_at, id); строк возвращается на одну больше
+// filter.Limit, чтобы было видно, есть ли следующая страница.
+func listPRs(ctx context.Context, s *Storage, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	order, cmp := "DESC", "<"
+	if filter.Sort == domain.PRSortCreatedAsc {
+		order, cmp = "ASC", ">"
+	}
+
+	query := fmt.Sprintf(`
+        SELECT 
+            pr.id, pr.pull_request_id, pr.pull_request_name, 
+            pr.author_id, pr.status_id, pr.merged_at, pr.version, pr.created_at,
+            u.id, u.user_id, u.username, u.is_active, u.team_id, u.role, u.created_at
+        FROM pr_system.pull_requests pr
+        JOIN pr_system.users u ON pr.author_id = u.id
+        LEFT JOIN pr_system.teams t ON u.team_id = t.id
+        WHERE pr.org_id = $1
+            AND ($2::text = '' OR EXISTS (
+                SELECT 1
+                FROM pr_system.pr_reviewers prr
+                JOIN pr_system.users reviewer ON prr.reviewer_id = reviewer.id
+                WHERE prr.pr_id = pr.id AND reviewer.user_id = $2 AND reviewer.org_id = $1
+            ))
+            AND ($3::text = '' OR u.user_id = $3)
+            AND ($4::int = 0 OR pr.status_id = $4)
+            AND ($5::text = '' OR t.name = $5)
+            AND ($6::timestamptz IS NULL OR pr.created_at > $6)
+            AND ($7::timestamptz IS NULL OR (pr.created_at, pr.id) %s ($7, $8::bigint))
+        ORDER BY pr.created_at %s, pr.id %s
+        LIMIT $9`, cmp, order, order)
+
+	var createdAfter, afterCreatedAt *time.Time
+	var afterID int64
+	if !filter.CreatedAfter.IsZero() {
+		createdAfter = &filter.CreatedAfter
+	}
+	if filter.After != nil {
+		afterCreatedAt, afterID = &filter.After.CreatedAt, filter.After.ID
+	}
+	var limit *int
+	if filter.Limit > 0 {
+		n := filter.Limit + 1
+		limit = &n
+	}
+
+	rows, err := s.conn(ctx).Query(ctx, query,
+		tenant.OrgID(ctx), filter.ReviewerID, filter.AuthorID, filter.StatusID, filter.TeamName,
+		createdAfter, afterCreatedAt, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []domain.PullRequest{}
+	for rows.Next() {
+		var pr domain.PullRequest
+		var author domain.User
+		err := rows.Scan(
+			&pr.ID, &pr.PullRequestID, &pr.PullRequestName,
+			&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.Version, &pr.CreatedAt,
+			&author.ID, &author.UserID, &author.Username, &author.IsActive, &author.TeamID, &author.Role, &author.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pr.Author = &author
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
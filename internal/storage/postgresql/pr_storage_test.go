@@ -2,7 +2,9 @@ package postgresql
 
 import (
 	"context"
+	"fmt"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
 	"testing"
 	"time"
 
@@ -14,7 +16,7 @@ func TestPRRepo_Create(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователя
@@ -59,7 +61,7 @@ func TestPRRepo_GetByPRID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователей
@@ -91,9 +93,9 @@ func TestPRRepo_GetByPRID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Назначаем ревьюверов
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer1.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer1.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer2.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer2.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	t.Run("get PR with reviewers", func(t *testing.T) {
@@ -113,7 +115,7 @@ func TestPRRepo_Update(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователя
@@ -150,6 +152,7 @@ func TestPRRepo_Update(t *testing.T) {
 		assert.Equal(t, 2, found.StatusID)
 		assert.NotNil(t, found.MergedAt)
 		assert.Equal(t, "Updated PR", found.PullRequestName)
+		assert.Equal(t, 2, found.Version)
 	})
 }
 
@@ -157,7 +160,7 @@ func TestPRRepo_AddReviewer(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователей
@@ -185,7 +188,7 @@ func TestPRRepo_AddReviewer(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("add reviewer", func(t *testing.T) {
-		err := prRepo.AddReviewer(ctx, pr.ID, reviewer.ID)
+		err := prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto)
 		require.NoError(t, err)
 
 		reviewers, err := prRepo.GetReviewers(ctx, pr.ID)
@@ -195,16 +198,24 @@ func TestPRRepo_AddReviewer(t *testing.T) {
 	})
 
 	t.Run("add duplicate reviewer", func(t *testing.T) {
-		err := prRepo.AddReviewer(ctx, pr.ID, reviewer.ID)
+		err := prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto)
 		require.NoError(t, err) // ON CONFLICT DO NOTHING, не должно быть ошибки
 	})
+
+	t.Run("assignment keeps reason", func(t *testing.T) {
+		assignments, err := prRepo.GetAssignments(ctx, pr.ID)
+		require.NoError(t, err)
+		require.Len(t, assignments, 1)
+		assert.Equal(t, "u2", assignments[0].UserID)
+		assert.Equal(t, domain.AssignReasonAuto, assignments[0].Reason)
+	})
 }
 
 func TestPRRepo_RemoveReviewer(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователей
@@ -231,7 +242,7 @@ func TestPRRepo_RemoveReviewer(t *testing.T) {
 	err = prRepo.Create(ctx, pr)
 	require.NoError(t, err)
 
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	t.Run("remove reviewer", func(t *testing.T) {
@@ -241,6 +252,15 @@ func TestPRRepo_RemoveReviewer(t *testing.T) {
 		reviewers, err := prRepo.GetReviewers(ctx, pr.ID)
 		require.NoError(t, err)
 		assert.Len(t, reviewers, 0)
+
+		// Снятое назначение сохраняется в истории
+		var reason string
+		err = storage.DB.QueryRow(ctx,
+			`SELECT reason FROM pr_system.pr_reviewer_history WHERE pr_id = $1 AND reviewer_id = $2`,
+			pr.ID, reviewer.ID,
+		).Scan(&reason)
+		require.NoError(t, err)
+		assert.Equal(t, domain.AssignReasonAuto, reason)
 	})
 
 	t.Run("remove non-existing reviewer", func(t *testing.T) {
@@ -253,7 +273,7 @@ func TestPRRepo_GetReviewers(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователей
@@ -283,9 +303,9 @@ func TestPRRepo_GetReviewers(t *testing.T) {
 	err = prRepo.Create(ctx, pr)
 	require.NoError(t, err)
 
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer1.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer1.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer2.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer2.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	t.Run("get reviewers", func(t *testing.T) {
@@ -314,7 +334,7 @@ func TestPRRepo_GetByReviewerID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	prRepo := NewPRRepo(storage)
 
 	// Создаем команду и пользователей
@@ -341,7 +361,7 @@ func TestPRRepo_GetByReviewerID(t *testing.T) {
 	err = prRepo.Create(ctx, pr)
 	require.NoError(t, err)
 
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	t.Run("get PRs by reviewer user_id", func(t *testing.T) {
@@ -352,3 +372,60 @@ func TestPRRepo_GetByReviewerID(t *testing.T) {
 	})
 }
 
+
+func TestPRRepo_GetPairCounts(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	prRepo := NewPRRepo(storage)
+
+	teamRepo := NewTeamRepo(storage)
+	team := &domain.Team{Name: "backend"}
+	require.NoError(t, teamRepo.Create(ctx, team))
+
+	userStorage := NewUserStorage(storage)
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: team.ID}
+	reviewer1 := &domain.User{UserID: "u2", Username: "Reviewer1", IsActive: true, TeamID: team.ID}
+	reviewer2 := &domain.User{UserID: "u3", Username: "Reviewer2", IsActive: true, TeamID: team.ID}
+	for _, u := range []*domain.User{author, reviewer1, reviewer2} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+
+	for i, reviewers := range [][]*domain.User{{reviewer1}, {reviewer1, reviewer2}} {
+		pr := &domain.PullRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: "Test PR",
+			AuthorID:        author.ID,
+			StatusID:        1,
+		}
+		require.NoError(t, prRepo.Create(ctx, pr))
+		for _, reviewer := range reviewers {
+			require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto))
+		}
+	}
+
+	t.Run("counts within window", func(t *testing.T) {
+		counts, err := prRepo.GetPairCounts(ctx, author.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, counts[reviewer1.ID])
+		assert.Equal(t, 1, counts[reviewer2.ID])
+	})
+
+	t.Run("removed reviewers are counted", func(t *testing.T) {
+		pr := &domain.PullRequest{PullRequestID: "pr-removed", PullRequestName: "Test PR", AuthorID: author.ID, StatusID: 1}
+		require.NoError(t, prRepo.Create(ctx, pr))
+		require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer2.ID, domain.AssignReasonAuto))
+		require.NoError(t, prRepo.RemoveReviewer(ctx, pr.ID, reviewer2.ID))
+
+		counts, err := prRepo.GetPairCounts(ctx, author.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, counts[reviewer2.ID])
+	})
+
+	t.Run("window in the future is empty", func(t *testing.T) {
+		counts, err := prRepo.GetPairCounts(ctx, author.ID, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, counts)
+	})
+}
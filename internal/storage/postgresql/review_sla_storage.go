@@ -0,0 +1,101 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ReviewSLARepo struct {
+	storage *Storage
+}
+
+func NewReviewSLARepo(storage *Storage) *ReviewSLARepo {
+	return &ReviewSLARepo{storage: storage}
+}
+
+// GetStaleReviews возвращает назначения в открытых PR, которые старше SLA
+// команды автора для события kind и по которым такое событие еще не
+// зафиксировано. Если у команды SLA не задан, используется defaultAfter.
+// Выборка идет по всем организациям; организация PR возвращается в OrgID.
+func (r *ReviewSLARepo) GetStaleReviews(ctx context.Context, kind string, defaultAfter time.Duration, now time.Time, limit int) ([]domain.StaleReview, error) {
+	const op = "repository.ReviewSLARepo.GetStaleReviews"
+
+	column := "reminder_after_minutes"
+	if kind == domain.ReviewEventEscalation {
+		column = "escalation_after_minutes"
+	}
+
+	query := fmt.Sprintf(`
+        SELECT 
+            pr.org_id, pr.id, pr.pull_request_id, reviewer.id, reviewer.user_id,
+            t.id, prr.assigned_at
+        FROM pr_system.pr_reviewers prr
+        JOIN pr_system.pull_requests pr ON prr.pr_id = pr.id
+        JOIN pr_system.users author ON pr.author_id = author.id
+        JOIN pr_system.teams t ON author.team_id = t.id
+        JOIN pr_system.users reviewer ON prr.reviewer_id = reviewer.id
+        WHERE pr.status_id = 1
+            AND prr.reviewed_at IS NULL
+            AND prr.assigned_at <= $1 - CASE 
+                WHEN t.%[1]s > 0 THEN make_interval(mins => t.%[1]s) 
+                ELSE make_interval(secs => $2) 
+            END
+            AND NOT EXISTS (
+                SELECT 1 FROM pr_system.review_events e 
+                WHERE e.pr_id = prr.pr_id AND e.reviewer_id = prr.reviewer_id
+                    AND e.assigned_at = prr.assigned_at AND e.kind = $3
+            )
+        ORDER BY prr.assigned_at
+        LIMIT $4`, column)
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, now, defaultAfter.Seconds(), kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var reviews []domain.StaleReview
+	for rows.Next() {
+		var review domain.StaleReview
+		err := rows.Scan(
+			&review.OrgID, &review.PRID, &review.PullRequestID, &review.ReviewerID, &review.ReviewerUserID,
+			&review.TeamID, &review.AssignedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// CreateEvent сохраняет событие. Возвращает false, если событие того же вида
+// по этому назначению (PR, ревьювер и время назначения) уже было записано.
+func (r *ReviewSLARepo) CreateEvent(ctx context.Context, event *domain.ReviewEvent) (bool, error) {
+	const op = "repository.ReviewSLARepo.CreateEvent"
+	const query = `
+        INSERT INTO pr_system.review_events (pr_id, reviewer_id, kind, action, target_id, org_id, assigned_at) 
+        VALUES ($1, $2, $3, $4, (SELECT id FROM pr_system.users WHERE user_id = $5 AND org_id = $6), $6, $7) 
+        ON CONFLICT (pr_id, reviewer_id, assigned_at, kind) DO NOTHING
+        RETURNING id, created_at`
+
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, event.PRID, event.ReviewerID, event.Kind, event.Action, event.TargetUserID, tenant.OrgID(ctx),
+		event.AssignedAt,
+	).Scan(&event.ID, &event.CreatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return true, nil
+}
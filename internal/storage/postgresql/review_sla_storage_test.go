@@ -0,0 +1,94 @@
+package postgresql
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewSLARepo_StaleReviews(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	repo := NewReviewSLARepo(storage)
+	prRepo := NewPRRepo(storage)
+
+	teamRepo := NewTeamRepo(storage)
+	team := &domain.Team{Name: "backend", MinReviewers: 1, MaxReviewers: 2, ReminderAfterMinutes: 60}
+	require.NoError(t, teamRepo.Create(ctx, team))
+
+	userStorage := NewUserStorage(storage)
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: team.ID}
+	reviewer := &domain.User{UserID: "u2", Username: "Reviewer", IsActive: true, TeamID: team.ID}
+	for _, u := range []*domain.User{author, reviewer} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+	assert.Equal(t, domain.RoleMember, reviewer.Role)
+
+	pr := &domain.PullRequest{PullRequestID: "pr-1", PullRequestName: "Test PR", AuthorID: author.ID, StatusID: 1}
+	require.NoError(t, prRepo.Create(ctx, pr))
+	require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto))
+
+	t.Run("team SLA overrides the default", func(t *testing.T) {
+		reviews, err := repo.GetStaleReviews(ctx, domain.ReviewEventReminder, 24*time.Hour, time.Now().Add(2*time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+		assert.Equal(t, "pr-1", reviews[0].PullRequestID)
+		assert.Equal(t, "u2", reviews[0].ReviewerUserID)
+
+		reviews, err = repo.GetStaleReviews(ctx, domain.ReviewEventReminder, 24*time.Hour, time.Now().Add(30*time.Minute), 10)
+		require.NoError(t, err)
+		assert.Empty(t, reviews)
+	})
+
+	t.Run("default SLA is used when team has none", func(t *testing.T) {
+		reviews, err := repo.GetStaleReviews(ctx, domain.ReviewEventEscalation, 72*time.Hour, time.Now().Add(48*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Empty(t, reviews)
+
+		reviews, err = repo.GetStaleReviews(ctx, domain.ReviewEventEscalation, 72*time.Hour, time.Now().Add(73*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Len(t, reviews, 1)
+	})
+
+	t.Run("recorded event is not returned again", func(t *testing.T) {
+		reviews, err := repo.GetStaleReviews(ctx, domain.ReviewEventReminder, 24*time.Hour, time.Now().Add(2*time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+		assignedAt := reviews[0].AssignedAt
+
+		event := &domain.ReviewEvent{PRID: pr.ID, ReviewerID: reviewer.ID, AssignedAt: assignedAt, Kind: domain.ReviewEventReminder}
+		created, err := repo.CreateEvent(ctx, event)
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		created, err = repo.CreateEvent(ctx, &domain.ReviewEvent{PRID: pr.ID, ReviewerID: reviewer.ID, AssignedAt: assignedAt, Kind: domain.ReviewEventReminder})
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		reviews, err = repo.GetStaleReviews(ctx, domain.ReviewEventReminder, 24*time.Hour, time.Now().Add(2*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Empty(t, reviews)
+	})
+
+	t.Run("reassigned reviewer is reminded again", func(t *testing.T) {
+		require.NoError(t, prRepo.RemoveReviewer(ctx, pr.ID, reviewer.ID))
+		require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonManual))
+
+		reviews, err := repo.GetStaleReviews(ctx, domain.ReviewEventReminder, 24*time.Hour, time.Now().Add(2*time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+
+		created, err := repo.CreateEvent(ctx, &domain.ReviewEvent{
+			PRID: pr.ID, ReviewerID: reviewer.ID, AssignedAt: reviews[0].AssignedAt, Kind: domain.ReviewEventReminder,
+		})
+		require.NoError(t, err)
+		assert.True(t, created)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
+	"time"
 )
 
 type StatsRepo struct {
@@ -14,12 +16,23 @@ func NewStatsRepo(storage *Storage) *StatsRepo {
 	return &StatsRepo{storage: storage}
 }
 
-func (r *StatsRepo) GetTotalPRs(ctx context.Context) (int, error) {
+// Во всех запросах $1 и $2 - границы периода [from, to) (NULL - без
+// границы), $3 - имя команды ('' - все команды), $4 - организация
+
+func (r *StatsRepo) GetTotalPRs(ctx context.Context, filter domain.StatsFilter) (int, error) {
 	const op = "repository.StatsRepo.GetTotalPRs"
-	const query = `SELECT COUNT(*) FROM pr_system.pull_requests`
+	const query = `
+        SELECT COUNT(*) 
+        FROM pr_system.pull_requests pr
+        JOIN pr_system.users author ON pr.author_id = author.id
+        LEFT JOIN pr_system.teams t ON author.team_id = t.id
+        WHERE ($1::timestamptz IS NULL OR pr.created_at >= $1)
+            AND ($2::timestamptz IS NULL OR pr.created_at < $2)
+            AND ($3::text = '' OR t.name = $3)
+            AND pr.org_id = $4`
 
 	var count int
-	err := r.storage.DB.QueryRow(ctx, query).Scan(&count)
+	err := r.storage.conn(ctx).QueryRow(ctx, query, filterArgs(ctx, filter)...).Scan(&count)
 
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -27,12 +40,16 @@ func (r *StatsRepo) GetTotalPRs(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (r *StatsRepo) GetTotalUsers(ctx context.Context) (int, error) {
+func (r *StatsRepo) GetTotalUsers(ctx context.Context, filter domain.StatsFilter) (int, error) {
 	const op = "repository.StatsRepo.GetTotalUsers"
-	const query = `SELECT COUNT(*) FROM pr_system.users`
+	const query = `
+        SELECT COUNT(*) 
+        FROM pr_system.users u
+        LEFT JOIN pr_system.teams t ON u.team_id = t.id
+        WHERE u.org_id = $2 AND ($1::text = '' OR t.name = $1)`
 
 	var count int
-	err := r.storage.DB.QueryRow(ctx, query).Scan(&count)
+	err := r.storage.conn(ctx).QueryRow(ctx, query, filter.TeamName, tenant.OrgID(ctx)).Scan(&count)
 
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -40,12 +57,16 @@ func (r *StatsRepo) GetTotalUsers(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (r *StatsRepo) GetActiveUsers(ctx context.Context) (int, error) {
+func (r *StatsRepo) GetActiveUsers(ctx context.Context, filter domain.StatsFilter) (int, error) {
 	const op = "repository.StatsRepo.GetActiveUsers"
-	const query = `SELECT COUNT(*) FROM pr_system.users WHERE is_active = true`
+	const query = `
+        SELECT COUNT(*) 
+        FROM pr_system.users u
+        LEFT JOIN pr_system.teams t ON u.team_id = t.id
+        WHERE u.org_id = $2 AND u.is_active = true AND ($1::text = '' OR t.name = $1)`
 
 	var count int
-	err := r.storage.DB.QueryRow(ctx, query).Scan(&count)
+	err := r.storage.conn(ctx).QueryRow(ctx, query, filter.TeamName, tenant.OrgID(ctx)).Scan(&count)
 
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -53,15 +74,23 @@ func (r *StatsRepo) GetActiveUsers(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (r *StatsRepo) GetPRsByStatus(ctx context.Context) (map[string]int, error) {
+func (r *StatsRepo) GetPRsByStatus(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
 	const op = "repository.StatsRepo.GetPRsByStatus"
 	const query = `
         SELECT s.name, COUNT(pr.id) 
         FROM pr_system.statuses s 
-        LEFT JOIN pr_system.pull_requests pr ON s.id = pr.status_id 
+        LEFT JOIN (
+            pr_system.pull_requests pr
+            JOIN pr_system.users author ON pr.author_id = author.id
+            LEFT JOIN pr_system.teams t ON author.team_id = t.id
+        ) ON s.id = pr.status_id
+            AND ($1::timestamptz IS NULL OR pr.created_at >= $1)
+            AND ($2::timestamptz IS NULL OR pr.created_at < $2)
+            AND ($3::text = '' OR t.name = $3)
+            AND pr.org_id = $4
         GROUP BY s.id, s.name`
 
-	rows, err := r.storage.DB.Query(ctx, query)
+	rows, err := r.storage.conn(ctx).Query(ctx, query, filterArgs(ctx, filter)...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -81,7 +110,9 @@ func (r *StatsRepo) GetPRsByStatus(ctx context.Context) (map[string]int, error)
 	return result, nil
 }
 
-func (r *StatsRepo) GetTopReviewers(ctx context.Context, limit int) ([]domain.ReviewerStats, error) {
+// GetTopReviewers возвращает filter.Limit активных ревьюверов с наибольшим
+// числом текущих назначений за период. Фильтр по команде относится к команде ревьювера.
+func (r *StatsRepo) GetTopReviewers(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStats, error) {
 	const op = "repository.StatsRepo.GetTopReviewers"
 	const query = `
         SELECT 
@@ -90,12 +121,16 @@ func (r *StatsRepo) GetTopReviewers(ctx context.Context, limit int) ([]domain.Re
             COUNT(prr.id) as review_count
         FROM pr_system.users u
         JOIN pr_system.pr_reviewers prr ON u.id = prr.reviewer_id
-        WHERE u.is_active = true
+        LEFT JOIN pr_system.teams t ON u.team_id = t.id
+        WHERE u.org_id = $4 AND u.is_active = true
+            AND ($1::timestamptz IS NULL OR prr.assigned_at >= $1)
+            AND ($2::timestamptz IS NULL OR prr.assigned_at < $2)
+            AND ($3::text = '' OR t.name = $3)
         GROUP BY u.id, u.user_id, u.username
-        ORDER BY review_count DESC
-        LIMIT $1`
+        ORDER BY review_count DESC, u.user_id
+        LIMIT $5`
 
-	rows, err := r.storage.DB.Query(ctx, query, limit)
+	rows, err := r.storage.conn(ctx).Query(ctx, query, append(filterArgs(ctx, filter), filter.Limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -113,3 +148,447 @@ func (r *StatsRepo) GetTopReviewers(ctx context.Context, limit int) ([]domain.Re
 
 	return stats, nil
 }
+
+// GetBorrowedReviews возвращает число назначений за период, в которых
+// ревьювер был заимствован из другой команды, в разрезе команд ревьюверов.
+// Заимствование определяется причиной назначения, сохраненной при
+// назначении, поэтому переход ревьювера в другую команду не меняет прошлую
+// статистику. Снятые и переназначенные ревьюверы тоже учитываются.
+func (r *StatsRepo) GetBorrowedReviews(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	const op = "repository.StatsRepo.GetBorrowedReviews"
+	const query = `
+        WITH borrowed AS (
+            SELECT reviewer_id, assigned_at FROM pr_system.pr_reviewers 
+            WHERE org_id = $4 AND reason = 'BORROWED'
+            UNION ALL
+            SELECT reviewer_id, assigned_at FROM pr_system.pr_reviewer_history 
+            WHERE org_id = $4 AND reason = 'BORROWED'
+        )
+        SELECT t.name, COUNT(*)
+        FROM borrowed b
+        JOIN pr_system.users reviewer ON b.reviewer_id = reviewer.id
+        JOIN pr_system.teams t ON reviewer.team_id = t.id
+        WHERE ($1::timestamptz IS NULL OR b.assigned_at >= $1)
+            AND ($2::timestamptz IS NULL OR b.assigned_at < $2)
+            AND ($3::text = '' OR t.name = $3)
+        GROUP BY t.name`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, filterArgs(ctx, filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var teamName string
+		var count int
+		if err := rows.Scan(&teamName, &count); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		result[teamName] = count
+	}
+
+	return result, nil
+}
+
+// GetTeamStats возвращает разбивку по командам: открытые и смерженные PR
+// авторов команды, созданные за период, число активных и неактивных
+// участников и число назначений на ревью каждого участника за период,
+// включая снятые и переназначенные
+func (r *StatsRepo) GetTeamStats(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamStats, error) {
+	const op = "repository.StatsRepo.GetTeamStats"
+	const teamsQuery = `
+        WITH pr_counts AS (
+            SELECT 
+                author.team_id,
+                COUNT(*) FILTER (WHERE pr.status_id = 1) AS open_prs,
+                COUNT(*) FILTER (WHERE pr.status_id = 2) AS merged_prs
+            FROM pr_system.pull_requests pr
+            JOIN pr_system.users author ON pr.author_id = author.id
+            WHERE pr.org_id = $4
+                AND ($1::timestamptz IS NULL OR pr.created_at >= $1)
+                AND ($2::timestamptz IS NULL OR pr.created_at < $2)
+            GROUP BY author.team_id
+        ),
+        member_counts AS (
+            SELECT 
+                team_id,
+                COUNT(*) FILTER (WHERE is_active) AS active_members,
+                COUNT(*) FILTER (WHERE NOT is_active) AS inactive_members
+            FROM pr_system.users
+            WHERE org_id = $4
+            GROUP BY team_id
+        )
+        SELECT 
+            t.name,
+            COALESCE(pc.open_prs, 0), COALESCE(pc.merged_prs, 0),
+            COALESCE(mc.active_members, 0), COALESCE(mc.inactive_members, 0)
+        FROM pr_system.teams t
+        LEFT JOIN pr_counts pc ON pc.team_id = t.id
+        LEFT JOIN member_counts mc ON mc.team_id = t.id
+        WHERE t.org_id = $4 AND ($3::text = '' OR t.name = $3)
+        ORDER BY t.name`
+	const membersQuery = `
+        WITH assignments AS (
+            SELECT reviewer_id FROM pr_system.pr_reviewers
+            WHERE org_id = $4
+                AND ($1::timestamptz IS NULL OR assigned_at >= $1)
+                AND ($2::timestamptz IS NULL OR assigned_at < $2)
+            UNION ALL
+            SELECT reviewer_id FROM pr_system.pr_reviewer_history
+            WHERE org_id = $4
+                AND ($1::timestamptz IS NULL OR assigned_at >= $1)
+                AND ($2::timestamptz IS NULL OR assigned_at < $2)
+        )
+        SELECT t.name, u.user_id, u.username, COUNT(a.reviewer_id) AS review_count
+        FROM pr_system.users u
+        JOIN pr_system.teams t ON u.team_id = t.id
+        LEFT JOIN assignments a ON a.reviewer_id = u.id
+        WHERE t.org_id = $4 AND ($3::text = '' OR t.name = $3)
+        GROUP BY t.name, u.id, u.user_id, u.username
+        ORDER BY t.name, review_count DESC, u.user_id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, teamsQuery, filterArgs(ctx, filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	teams := []domain.TeamStats{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var ts domain.TeamStats
+		err := rows.Scan(&ts.TeamName, &ts.OpenPRs, &ts.MergedPRs, &ts.ActiveMembers, &ts.InactiveMembers)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ts.ReviewsPerMember = []domain.ReviewerStats{}
+		index[ts.TeamName] = len(teams)
+		teams = append(teams, ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	memberRows, err := r.storage.conn(ctx).Query(ctx, membersQuery, filterArgs(ctx, filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var teamName string
+		var stat domain.ReviewerStats
+		if err := memberRows.Scan(&teamName, &stat.UserID, &stat.Username, &stat.ReviewCount); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if i, ok := index[teamName]; ok {
+			teams[i].ReviewsPerMember = append(teams[i].ReviewsPerMember, stat)
+		}
+	}
+
+	return teams, nil
+}
+
+// GetMemberWorkloads возвращает для каждого участника команд число назначений
+// на ревью за период [filter.From, filter.To) - включая снятые и
+// переназначенные - и время, когда участник был активен в этом периоде
+func (r *StatsRepo) GetMemberWorkloads(ctx context.Context, filter domain.StatsFilter) ([]domain.MemberWorkload, error) {
+	const op = "repository.StatsRepo.GetMemberWorkloads"
+	const query = `
+        WITH periods AS (
+            SELECT 
+                user_id, is_active, changed_at,
+                LEAD(changed_at) OVER (PARTITION BY user_id ORDER BY changed_at, id) AS next_changed_at
+            FROM pr_system.user_activity_log
+            WHERE org_id = $4 AND changed_at < $2
+        ),
+        active_time AS (
+            SELECT 
+                user_id,
+                SUM(EXTRACT(EPOCH FROM 
+                    LEAST(COALESCE(next_changed_at, $2), $2) - GREATEST(changed_at, $1)
+                ))::float8 AS active_seconds
+            FROM periods
+            WHERE is_active AND COALESCE(next_changed_at, $2) > $1
+            GROUP BY user_id
+        ),
+        assignments AS (
+            SELECT 
+                reviewer_id,
+                COUNT(*) AS total,
+                COUNT(*) FILTER (WHERE removed) AS reassigned_away
+            FROM (
+                SELECT reviewer_id, false AS removed
+                FROM pr_system.pr_reviewers
+                WHERE org_id = $4 AND assigned_at >= $1 AND assigned_at < $2
+                UNION ALL
+                SELECT reviewer_id, true AS removed
+                FROM pr_system.pr_reviewer_history
+                WHERE org_id = $4 AND assigned_at >= $1 AND assigned_at < $2
+            ) a
+            GROUP BY reviewer_id
+        )
+        SELECT 
+            t.name, u.user_id, u.username,
+            COALESCE(a.total, 0), COALESCE(a.reassigned_away, 0),
+            COALESCE(at.active_seconds, 0)
+        FROM pr_system.users u
+        JOIN pr_system.teams t ON u.team_id = t.id
+        LEFT JOIN assignments a ON a.reviewer_id = u.id
+        LEFT JOIN active_time at ON at.user_id = u.id
+        WHERE u.org_id = $4 AND ($3::text = '' OR t.name = $3)
+        ORDER BY t.name, u.user_id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, filter.From, filter.To, filter.TeamName, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var workloads []domain.MemberWorkload
+	for rows.Next() {
+		var w domain.MemberWorkload
+		err := rows.Scan(&w.TeamName, &w.UserID, &w.Username, &w.Assignments, &w.ReassignedAway, &w.ActiveSeconds)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		workloads = append(workloads, w)
+	}
+
+	return workloads, nil
+}
+
+// GetOpenPRGroups возвращает открытые PR, сгруппированные по границам числа
+// ревьюверов команды автора и числу назначенных ревьюверов. Это метрика для
+// операторов сервиса, поэтому она считается по всем организациям.
+func (r *StatsRepo) GetOpenPRGroups(ctx context.Context) ([]domain.OpenPRGroup, error) {
+	const op = "repository.StatsRepo.GetOpenPRGroups"
+	const query = `
+        SELECT 
+            t.id IS NOT NULL,
+            COALESCE(t.min_reviewers, 0),
+            COALESCE(t.max_reviewers, 0),
+            COALESCE(r.reviewers, 0),
+            COUNT(*)
+        FROM pr_system.pull_requests pr
+        JOIN pr_system.users u ON pr.author_id = u.id
+        LEFT JOIN pr_system.teams t ON u.team_id = t.id
+        LEFT JOIN (
+            SELECT pr_id, COUNT(*) AS reviewers
+            FROM pr_system.pr_reviewers
+            GROUP BY pr_id
+        ) r ON r.pr_id = pr.id
+        WHERE pr.status_id = 1
+        GROUP BY 1, 2, 3, 4`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var groups []domain.OpenPRGroup
+	for rows.Next() {
+		var g domain.OpenPRGroup
+		if err := rows.Scan(&g.HasTeam, &g.MinReviewers, &g.MaxReviewers, &g.Reviewers, &g.Count); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return groups, nil
+}
+
+// filterArgs возвращает границы периода, команду и организацию контекста как
+// параметры $1..$4. Нулевое время передается как NULL.
+func filterArgs(ctx context.Context, filter domain.StatsFilter) []interface{} {
+	var from, to *time.Time
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+	return []interface{}{from, to, filter.TeamName, tenant.OrgID(ctx)}
+}
+
+// GetReviewPairs возвращает матрицу "автор -> ревьювер" за период начиная с
+// since, включая снятых и переназначенных ревьюверов
+func (r *StatsRepo) GetReviewPairs(ctx context.Context, since time.Time) ([]domain.ReviewPairStats, error) {
+	const op = "repository.StatsRepo.GetReviewPairs"
+	const query = `
+        WITH assignments AS (
+            SELECT pr_id, reviewer_id FROM pr_system.pr_reviewers 
+            WHERE org_id = $2 AND assigned_at >= $1
+            UNION ALL
+            SELECT pr_id, reviewer_id FROM pr_system.pr_reviewer_history 
+            WHERE org_id = $2 AND assigned_at >= $1
+        )
+        SELECT 
+            author.user_id, author.username,
+            reviewer.user_id, reviewer.username,
+            COUNT(*) AS review_count
+        FROM assignments a
+        JOIN pr_system.pull_requests pr ON a.pr_id = pr.id
+        JOIN pr_system.users author ON pr.author_id = author.id
+        JOIN pr_system.users reviewer ON a.reviewer_id = reviewer.id
+        GROUP BY author.id, author.user_id, author.username, reviewer.id, reviewer.user_id, reviewer.username
+        ORDER BY review_count DESC, author.user_id, reviewer.user_id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, since, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var pairs []domain.ReviewPairStats
+	for rows.Next() {
+		var p domain.ReviewPairStats
+		err := rows.Scan(&p.AuthorID, &p.AuthorUsername, &p.ReviewerID, &p.ReviewerUsername, &p.ReviewCount)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		pairs = append(pairs, p)
+	}
+
+	return pairs, nil
+}
+
+// GetLatency считает медиану и p90 времени от создания PR до первого ревью
+// и до мержа по PR, созданным в [filter.From, filter.To): в целом, по командам
+// авторов и по ревьюверам. Ревью снятых и переназначенных ревьюверов тоже
+// учитываются.
+func (r *StatsRepo) GetLatency(ctx context.Context, filter domain.StatsFilter) (*domain.LatencyReport, error) {
+	const op = "repository.StatsRepo.GetLatency"
+	const teamsQuery = `
+        WITH prs AS (
+            SELECT pr.id, pr.created_at, pr.merged_at, t.name AS team_name
+            FROM pr_system.pull_requests pr
+            JOIN pr_system.users author ON pr.author_id = author.id
+            LEFT JOIN pr_system.teams t ON author.team_id = t.id
+            WHERE pr.org_id = $4 AND pr.created_at >= $1 AND pr.created_at < $2
+                AND ($3::text = '' OR t.name = $3)
+        ),
+        reviews AS (
+            SELECT rv.pr_id, rv.reviewed_at FROM pr_system.pr_reviewers rv
+            JOIN prs ON prs.id = rv.pr_id
+            WHERE rv.org_id = $4 AND rv.reviewed_at IS NOT NULL
+            UNION ALL
+            SELECT h.pr_id, h.reviewed_at FROM pr_system.pr_reviewer_history h
+            JOIN prs ON prs.id = h.pr_id
+            WHERE h.org_id = $4 AND h.reviewed_at IS NOT NULL
+        ),
+        first_reviews AS (
+            SELECT pr_id, MIN(reviewed_at) AS reviewed_at
+            FROM reviews
+            GROUP BY pr_id
+        )
+        SELECT 
+            GROUPING(prs.team_name) = 1 AS overall,
+            prs.team_name,
+            COUNT(fr.reviewed_at),
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM fr.reviewed_at - prs.created_at)::float8),
+            percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM fr.reviewed_at - prs.created_at)::float8),
+            COUNT(prs.merged_at),
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM prs.merged_at - prs.created_at)::float8),
+            percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM prs.merged_at - prs.created_at)::float8)
+        FROM prs
+        LEFT JOIN first_reviews fr ON fr.pr_id = prs.id
+        GROUP BY GROUPING SETS ((prs.team_name), ())
+        ORDER BY overall DESC, prs.team_name`
+	const reviewersQuery = `
+        WITH prs AS (
+            SELECT id FROM pr_system.pull_requests
+            WHERE org_id = $4 AND created_at >= $1 AND created_at < $2
+        ),
+        reviews AS (
+            SELECT rv.pr_id, rv.reviewer_id, rv.reviewed_at FROM pr_system.pr_reviewers rv
+            JOIN prs ON prs.id = rv.pr_id
+            WHERE rv.org_id = $4 AND rv.reviewed_at IS NOT NULL
+            UNION ALL
+            SELECT h.pr_id, h.reviewer_id, h.reviewed_at FROM pr_system.pr_reviewer_history h
+            JOIN prs ON prs.id = h.pr_id
+            WHERE h.org_id = $4 AND h.reviewed_at IS NOT NULL
+        )
+        SELECT 
+            u.user_id, u.username,
+            COUNT(*),
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM r.reviewed_at - pr.created_at)::float8),
+            percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM r.reviewed_at - pr.created_at)::float8),
+            COUNT(pr.merged_at),
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)::float8),
+            percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)::float8)
+        FROM reviews r
+        JOIN pr_system.pull_requests pr ON r.pr_id = pr.id
+        JOIN pr_system.users author ON pr.author_id = author.id
+        LEFT JOIN pr_system.teams t ON author.team_id = t.id
+        JOIN pr_system.users u ON r.reviewer_id = u.id
+        WHERE pr.created_at >= $1 AND pr.created_at < $2
+            AND ($3::text = '' OR t.name = $3)
+        GROUP BY u.id, u.user_id, u.username
+        ORDER BY u.user_id`
+
+	report := &domain.LatencyReport{
+		From:      filter.From,
+		To:        filter.To,
+		Teams:     []domain.TeamLatency{},
+		Reviewers: []domain.ReviewerLatency{},
+	}
+
+	rows, err := r.storage.conn(ctx).Query(ctx, teamsQuery, filter.From, filter.To, filter.TeamName, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var overall bool
+		var teamName *string
+		var l domain.TeamLatency
+		err := rows.Scan(
+			&overall, &teamName,
+			&l.TimeToFirstReview.Count, &l.TimeToFirstReview.MedianSeconds, &l.TimeToFirstReview.P90Seconds,
+			&l.TimeToMerge.Count, &l.TimeToMerge.MedianSeconds, &l.TimeToMerge.P90Seconds,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		switch {
+		case overall:
+			report.Overall = l
+		case teamName != nil:
+			l.TeamName = *teamName
+			report.Teams = append(report.Teams, l)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reviewerRows, err := r.storage.conn(ctx).Query(ctx, reviewersQuery, filter.From, filter.To, filter.TeamName, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer reviewerRows.Close()
+
+	for reviewerRows.Next() {
+		var l domain.ReviewerLatency
+		err := reviewerRows.Scan(
+			&l.UserID, &l.Username,
+			&l.TimeToReview.Count, &l.TimeToReview.MedianSeconds, &l.TimeToReview.P90Seconds,
+			&l.TimeToMerge.Count, &l.TimeToMerge.MedianSeconds, &l.TimeToMerge.P90Seconds,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		report.Reviewers = append(report.Reviewers, l)
+	}
+
+	return report, nil
+}
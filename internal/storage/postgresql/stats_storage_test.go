@@ -3,7 +3,9 @@ package postgresql
 import (
 	"context"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,7 +15,7 @@ func TestStatsRepo_GetTotalPRs(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	statsRepo := NewStatsRepo(storage)
 	prRepo := NewPRRepo(storage)
 
@@ -29,7 +31,7 @@ func TestStatsRepo_GetTotalPRs(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("empty database", func(t *testing.T) {
-		count, err := statsRepo.GetTotalPRs(ctx)
+		count, err := statsRepo.GetTotalPRs(ctx, domain.StatsFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, 0, count)
 	})
@@ -46,7 +48,7 @@ func TestStatsRepo_GetTotalPRs(t *testing.T) {
 			require.NoError(t, err)
 		}
 
-		count, err := statsRepo.GetTotalPRs(ctx)
+		count, err := statsRepo.GetTotalPRs(ctx, domain.StatsFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, 3, count)
 	})
@@ -56,7 +58,7 @@ func TestStatsRepo_GetTotalUsers(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	statsRepo := NewStatsRepo(storage)
 	userStorage := NewUserStorage(storage)
 
@@ -67,7 +69,7 @@ func TestStatsRepo_GetTotalUsers(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("empty database", func(t *testing.T) {
-		count, err := statsRepo.GetTotalUsers(ctx)
+		count, err := statsRepo.GetTotalUsers(ctx, domain.StatsFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, 0, count)
 	})
@@ -84,7 +86,7 @@ func TestStatsRepo_GetTotalUsers(t *testing.T) {
 			require.NoError(t, err)
 		}
 
-		count, err := statsRepo.GetTotalUsers(ctx)
+		count, err := statsRepo.GetTotalUsers(ctx, domain.StatsFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, 3, count)
 	})
@@ -94,7 +96,7 @@ func TestStatsRepo_GetActiveUsers(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	statsRepo := NewStatsRepo(storage)
 	userStorage := NewUserStorage(storage)
 
@@ -116,7 +118,7 @@ func TestStatsRepo_GetActiveUsers(t *testing.T) {
 	}
 
 	t.Run("get active users count", func(t *testing.T) {
-		count, err := statsRepo.GetActiveUsers(ctx)
+		count, err := statsRepo.GetActiveUsers(ctx, domain.StatsFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, 2, count)
 	})
@@ -126,7 +128,7 @@ func TestStatsRepo_GetPRsByStatus(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	statsRepo := NewStatsRepo(storage)
 	prRepo := NewPRRepo(storage)
 
@@ -153,7 +155,7 @@ func TestStatsRepo_GetPRsByStatus(t *testing.T) {
 	}
 
 	t.Run("get PRs by status", func(t *testing.T) {
-		stats, err := statsRepo.GetPRsByStatus(ctx)
+		stats, err := statsRepo.GetPRsByStatus(ctx, domain.StatsFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, 2, stats["OPEN"])
 		assert.Equal(t, 1, stats["MERGED"])
@@ -164,7 +166,7 @@ func TestStatsRepo_GetTopReviewers(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	statsRepo := NewStatsRepo(storage)
 	prRepo := NewPRRepo(storage)
 
@@ -195,9 +197,9 @@ func TestStatsRepo_GetTopReviewers(t *testing.T) {
 	}
 	err = prRepo.Create(ctx, pr1)
 	require.NoError(t, err)
-	err = prRepo.AddReviewer(ctx, pr1.ID, reviewer1.ID)
+	err = prRepo.AddReviewer(ctx, pr1.ID, reviewer1.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
-	err = prRepo.AddReviewer(ctx, pr1.ID, reviewer2.ID)
+	err = prRepo.AddReviewer(ctx, pr1.ID, reviewer2.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	pr2 := &domain.PullRequest{
@@ -208,11 +210,11 @@ func TestStatsRepo_GetTopReviewers(t *testing.T) {
 	}
 	err = prRepo.Create(ctx, pr2)
 	require.NoError(t, err)
-	err = prRepo.AddReviewer(ctx, pr2.ID, reviewer1.ID)
+	err = prRepo.AddReviewer(ctx, pr2.ID, reviewer1.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	t.Run("get top reviewers", func(t *testing.T) {
-		stats, err := statsRepo.GetTopReviewers(ctx, 10)
+		stats, err := statsRepo.GetTopReviewers(ctx, domain.StatsFilter{Limit: 10})
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(stats), 2)
 
@@ -220,5 +222,350 @@ func TestStatsRepo_GetTopReviewers(t *testing.T) {
 		assert.Equal(t, "u2", stats[0].UserID)
 		assert.Equal(t, 2, stats[0].ReviewCount)
 	})
+
+	t.Run("limit", func(t *testing.T) {
+		stats, err := statsRepo.GetTopReviewers(ctx, domain.StatsFilter{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, "u2", stats[0].UserID)
+	})
+
+	t.Run("period without assignments", func(t *testing.T) {
+		stats, err := statsRepo.GetTopReviewers(ctx, domain.StatsFilter{
+			To:    time.Now().Add(-time.Hour),
+			Limit: 10,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, stats)
+	})
+
+	t.Run("other team", func(t *testing.T) {
+		stats, err := statsRepo.GetTopReviewers(ctx, domain.StatsFilter{TeamName: "frontend", Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, stats)
+	})
+}
+
+func TestStatsRepo_GetTeamStats(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	statsRepo := NewStatsRepo(storage)
+	prRepo := NewPRRepo(storage)
+
+	teamRepo := NewTeamRepo(storage)
+	backend := &domain.Team{Name: "backend"}
+	frontend := &domain.Team{Name: "frontend"}
+	require.NoError(t, teamRepo.Create(ctx, backend))
+	require.NoError(t, teamRepo.Create(ctx, frontend))
+
+	userStorage := NewUserStorage(storage)
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: backend.ID}
+	reviewer := &domain.User{UserID: "u2", Username: "Reviewer", IsActive: true, TeamID: backend.ID}
+	inactive := &domain.User{UserID: "u3", Username: "Inactive", IsActive: false, TeamID: backend.ID}
+	designer := &domain.User{UserID: "u4", Username: "Designer", IsActive: true, TeamID: frontend.ID}
+	for _, u := range []*domain.User{author, reviewer, inactive, designer} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+
+	for i, status := range []int{1, 1, 2} {
+		pr := &domain.PullRequest{
+			PullRequestID:   []string{"pr-1", "pr-2", "pr-3"}[i],
+			PullRequestName: "PR",
+			AuthorID:        author.ID,
+			StatusID:        status,
+		}
+		require.NoError(t, prRepo.Create(ctx, pr))
+		require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto))
+	}
+
+	t.Run("all teams", func(t *testing.T) {
+		teams, err := statsRepo.GetTeamStats(ctx, domain.StatsFilter{})
+		require.NoError(t, err)
+		require.Len(t, teams, 2)
+
+		assert.Equal(t, "backend", teams[0].TeamName)
+		assert.Equal(t, 2, teams[0].OpenPRs)
+		assert.Equal(t, 1, teams[0].MergedPRs)
+		assert.Equal(t, 2, teams[0].ActiveMembers)
+		assert.Equal(t, 1, teams[0].InactiveMembers)
+		require.Len(t, teams[0].ReviewsPerMember, 3)
+		assert.Equal(t, "u2", teams[0].ReviewsPerMember[0].UserID)
+		assert.Equal(t, 3, teams[0].ReviewsPerMember[0].ReviewCount)
+
+		assert.Equal(t, "frontend", teams[1].TeamName)
+		assert.Equal(t, 0, teams[1].OpenPRs)
+		assert.Len(t, teams[1].ReviewsPerMember, 1)
+	})
+
+	t.Run("single team in a past period", func(t *testing.T) {
+		teams, err := statsRepo.GetTeamStats(ctx, domain.StatsFilter{
+			To:       time.Now().Add(-time.Hour),
+			TeamName: "backend",
+		})
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		assert.Equal(t, 0, teams[0].OpenPRs)
+		assert.Equal(t, 2, teams[0].ActiveMembers)
+		assert.Equal(t, 0, teams[0].ReviewsPerMember[0].ReviewCount)
+	})
+
+	t.Run("removed reviewers are counted", func(t *testing.T) {
+		pr, err := prRepo.GetByPRID(ctx, "pr-1")
+		require.NoError(t, err)
+		require.NoError(t, prRepo.RemoveReviewer(ctx, pr.ID, reviewer.ID))
+
+		teams, err := statsRepo.GetTeamStats(ctx, domain.StatsFilter{TeamName: "backend"})
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		assert.Equal(t, "u2", teams[0].ReviewsPerMember[0].UserID)
+		assert.Equal(t, 3, teams[0].ReviewsPerMember[0].ReviewCount)
+	})
+}
+
+
+func TestStatsRepo_GetLatency(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	statsRepo := NewStatsRepo(storage)
+	prRepo := NewPRRepo(storage)
+
+	teamRepo := NewTeamRepo(storage)
+	team := &domain.Team{Name: "backend"}
+	require.NoError(t, teamRepo.Create(ctx, team))
+
+	userStorage := NewUserStorage(storage)
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: team.ID}
+	reviewer := &domain.User{UserID: "u2", Username: "Reviewer", IsActive: true, TeamID: team.ID}
+	for _, u := range []*domain.User{author, reviewer} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+
+	created := time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC)
+	for i, hours := range []int{1, 3} {
+		pr := &domain.PullRequest{
+			PullRequestID:   []string{"pr-1", "pr-2"}[i],
+			PullRequestName: "Test PR",
+			AuthorID:        author.ID,
+			StatusID:        1,
+		}
+		require.NoError(t, prRepo.Create(ctx, pr))
+		require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto))
+
+		_, err := storage.DB.Exec(ctx, `UPDATE pr_system.pull_requests SET created_at = $1 WHERE id = $2`, created, pr.ID)
+		require.NoError(t, err)
+		require.NoError(t, prRepo.MarkReviewed(ctx, pr.ID, reviewer.ID, created.Add(time.Duration(hours)*time.Hour)))
+		// Повторная отметка не меняет время первого ревью
+		require.NoError(t, prRepo.MarkReviewed(ctx, pr.ID, reviewer.ID, created.Add(48*time.Hour)))
+	}
+
+	report, err := statsRepo.GetLatency(ctx, domain.StatsFilter{
+		From: created.Add(-time.Hour),
+		To:   created.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Overall.TimeToFirstReview.Count)
+	require.NotNil(t, report.Overall.TimeToFirstReview.MedianSeconds)
+	assert.InDelta(t, 7200, *report.Overall.TimeToFirstReview.MedianSeconds, 0.001)
+	assert.Equal(t, 0, report.Overall.TimeToMerge.Count)
+	assert.Nil(t, report.Overall.TimeToMerge.MedianSeconds)
+
+	require.Len(t, report.Teams, 1)
+	assert.Equal(t, "backend", report.Teams[0].TeamName)
+
+	require.Len(t, report.Reviewers, 1)
+	assert.Equal(t, "u2", report.Reviewers[0].UserID)
+	assert.Equal(t, 2, report.Reviewers[0].TimeToReview.Count)
+
+	t.Run("unknown team is empty", func(t *testing.T) {
+		report, err := statsRepo.GetLatency(ctx, domain.StatsFilter{
+			From:     created.Add(-time.Hour),
+			To:       created.Add(time.Hour),
+			TeamName: "frontend",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, report.Teams)
+		assert.Empty(t, report.Reviewers)
+	})
+
+	t.Run("removed reviewers are counted", func(t *testing.T) {
+		pr := &domain.PullRequest{PullRequestID: "pr-3", PullRequestName: "Test PR", AuthorID: author.ID, StatusID: 1}
+		require.NoError(t, prRepo.Create(ctx, pr))
+		require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto))
+
+		_, err := storage.DB.Exec(ctx, `UPDATE pr_system.pull_requests SET created_at = $1 WHERE id = $2`, created, pr.ID)
+		require.NoError(t, err)
+		require.NoError(t, prRepo.MarkReviewed(ctx, pr.ID, reviewer.ID, created.Add(5*time.Hour)))
+		require.NoError(t, prRepo.RemoveReviewer(ctx, pr.ID, reviewer.ID))
+
+		report, err := statsRepo.GetLatency(ctx, domain.StatsFilter{
+			From: created.Add(-time.Hour),
+			To:   created.Add(time.Hour),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Overall.TimeToFirstReview.Count)
+		require.Len(t, report.Reviewers, 1)
+		assert.Equal(t, 3, report.Reviewers[0].TimeToReview.Count)
+	})
 }
 
+func TestStatsRepo_GetMemberWorkloads(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	statsRepo := NewStatsRepo(storage)
+	prRepo := NewPRRepo(storage)
+
+	teamRepo := NewTeamRepo(storage)
+	team := &domain.Team{Name: "backend"}
+	require.NoError(t, teamRepo.Create(ctx, team))
+
+	userStorage := NewUserStorage(storage)
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: team.ID}
+	full := &domain.User{UserID: "u2", Username: "Full", IsActive: true, TeamID: team.ID}
+	half := &domain.User{UserID: "u3", Username: "Half", IsActive: false, TeamID: team.ID}
+	for _, u := range []*domain.User{author, full, half} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+
+	// u3 активен первые 15 дней периода, остальные - весь период
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(30 * 24 * time.Hour)
+	_, err := storage.DB.Exec(ctx, `DELETE FROM pr_system.user_activity_log`)
+	require.NoError(t, err)
+	_, err = storage.DB.Exec(ctx, `
+        INSERT INTO pr_system.user_activity_log (user_id, is_active, changed_at)
+        VALUES ($1, true, $4), ($2, true, $4), ($3, true, $4), ($3, false, $5)`,
+		author.ID, full.ID, half.ID, from.Add(-24*time.Hour), from.Add(15*24*time.Hour),
+	)
+	require.NoError(t, err)
+
+	pr := &domain.PullRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Test PR",
+		AuthorID:        author.ID,
+		StatusID:        1,
+	}
+	require.NoError(t, prRepo.Create(ctx, pr))
+	require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, full.ID, domain.AssignReasonAuto))
+	require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, half.ID, domain.AssignReasonAuto))
+	_, err = storage.DB.Exec(ctx, `UPDATE pr_system.pr_reviewers SET assigned_at = $1 WHERE pr_id = $2`,
+		from.Add(24*time.Hour), pr.ID)
+	require.NoError(t, err)
+	// Переназначенное ревью остается в нагрузке u3
+	require.NoError(t, prRepo.RemoveReviewer(ctx, pr.ID, half.ID))
+
+	workloads, err := statsRepo.GetMemberWorkloads(ctx, domain.StatsFilter{From: from, To: to})
+	require.NoError(t, err)
+	require.Len(t, workloads, 3)
+
+	day := float64(24 * 60 * 60)
+	assert.Equal(t, "backend", workloads[0].TeamName)
+	assert.Equal(t, 0, workloads[0].Assignments)
+	assert.InDelta(t, 30*day, workloads[0].ActiveSeconds, 0.001)
+
+	assert.Equal(t, "u2", workloads[1].UserID)
+	assert.Equal(t, 1, workloads[1].Assignments)
+	assert.Equal(t, 0, workloads[1].ReassignedAway)
+
+	assert.Equal(t, "u3", workloads[2].UserID)
+	assert.Equal(t, 1, workloads[2].Assignments)
+	assert.Equal(t, 1, workloads[2].ReassignedAway)
+	assert.InDelta(t, 15*day, workloads[2].ActiveSeconds, 0.001)
+
+	t.Run("other team", func(t *testing.T) {
+		workloads, err := statsRepo.GetMemberWorkloads(ctx, domain.StatsFilter{From: from, To: to, TeamName: "frontend"})
+		require.NoError(t, err)
+		assert.Empty(t, workloads)
+	})
+}
+
+func TestStatsRepo_GetOpenPRGroups(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	statsRepo := NewStatsRepo(storage)
+	prRepo := NewPRRepo(storage)
+
+	teamRepo := NewTeamRepo(storage)
+	team := &domain.Team{Name: "backend", MinReviewers: 1, MaxReviewers: 2}
+	require.NoError(t, teamRepo.Create(ctx, team))
+
+	userStorage := NewUserStorage(storage)
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: team.ID}
+	reviewer := &domain.User{UserID: "u2", Username: "Reviewer", IsActive: true, TeamID: team.ID}
+	for _, u := range []*domain.User{author, reviewer} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+
+	for i, status := range []int{1, 1, 2} {
+		pr := &domain.PullRequest{
+			PullRequestID:   []string{"pr-1", "pr-2", "pr-3"}[i],
+			PullRequestName: "Test PR",
+			AuthorID:        author.ID,
+			StatusID:        status,
+		}
+		require.NoError(t, prRepo.Create(ctx, pr))
+		if i == 0 {
+			require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto))
+		}
+	}
+
+	groups, err := statsRepo.GetOpenPRGroups(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.OpenPRGroup{
+		{HasTeam: true, MinReviewers: 1, MaxReviewers: 2, Reviewers: 1, Count: 1},
+		{HasTeam: true, MinReviewers: 1, MaxReviewers: 2, Reviewers: 0, Count: 1},
+	}, groups)
+}
+
+func TestStatsRepo_GetBorrowedReviews(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	statsRepo := NewStatsRepo(storage)
+	prRepo := NewPRRepo(storage)
+	teamRepo := NewTeamRepo(storage)
+	userStorage := NewUserStorage(storage)
+
+	backend := &domain.Team{Name: "backend"}
+	frontend := &domain.Team{Name: "frontend"}
+	require.NoError(t, teamRepo.Create(ctx, backend))
+	require.NoError(t, teamRepo.Create(ctx, frontend))
+
+	author := &domain.User{UserID: "u1", Username: "Author", IsActive: true, TeamID: backend.ID}
+	borrowed := &domain.User{UserID: "f1", Username: "Borrowed", IsActive: true, TeamID: frontend.ID}
+	manual := &domain.User{UserID: "f2", Username: "Manual", IsActive: true, TeamID: frontend.ID}
+	for _, u := range []*domain.User{author, borrowed, manual} {
+		require.NoError(t, userStorage.Create(ctx, u))
+	}
+
+	pr := &domain.PullRequest{PullRequestID: "pr-1", PullRequestName: "PR 1", AuthorID: author.ID, StatusID: 1}
+	require.NoError(t, prRepo.Create(ctx, pr))
+	require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, borrowed.ID, domain.AssignReasonBorrowed))
+	require.NoError(t, prRepo.AddReviewer(ctx, pr.ID, manual.ID, domain.AssignReasonManual))
+
+	// Заимствование определяется причиной назначения, а не текущими командами
+	borrowed.TeamID = backend.ID
+	require.NoError(t, userStorage.Update(ctx, borrowed))
+
+	assignments, err := prRepo.GetAssignments(ctx, pr.ID)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.True(t, assignments[0].Borrowed)
+	assert.False(t, assignments[1].Borrowed)
+
+	// Снятое назначение остается в статистике
+	require.NoError(t, prRepo.RemoveReviewer(ctx, pr.ID, borrowed.ID))
+
+	counts, err := statsRepo.GetBorrowedReviews(ctx, domain.StatsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"backend": 1}, counts)
+}
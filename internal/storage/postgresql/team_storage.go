@@ -2,8 +2,13 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type TeamRepo struct {
@@ -17,11 +22,14 @@ func NewTeamRepo(storage *Storage) *TeamRepo {
 func (r *TeamRepo) Create(ctx context.Context, team *domain.Team) error {
 	const op = "repository.TeamRepo.Create"
 	const query = `
-        INSERT INTO pr_system.teams (name) 
-        VALUES ($1) 
-        RETURNING id, created_at`
+        INSERT INTO pr_system.teams (name, min_reviewers, max_reviewers, reminder_after_minutes, escalation_after_minutes, org_id) 
+        VALUES ($1, $2, $3, $4, $5, $6) 
+        RETURNING id, version, created_at`
 
-	err := r.storage.DB.QueryRow(ctx, query, team.Name).Scan(&team.ID, &team.CreatedAt)
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, team.Name, team.MinReviewers, team.MaxReviewers,
+		team.ReminderAfterMinutes, team.EscalationAfterMinutes, tenant.OrgID(ctx),
+	).Scan(&team.ID, &team.Version, &team.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -32,13 +40,14 @@ func (r *TeamRepo) Create(ctx context.Context, team *domain.Team) error {
 func (r *TeamRepo) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
 	const op = "repository.TeamRepo.GetByName"
 	const query = `
-        SELECT id, name, created_at 
+        SELECT id, name, min_reviewers, max_reviewers, reminder_after_minutes, escalation_after_minutes, version, created_at 
         FROM pr_system.teams 
-        WHERE name = $1`
+        WHERE name = $1 AND org_id = $2`
 
 	var team domain.Team
-	err := r.storage.DB.QueryRow(ctx, query, teamName).Scan(
-		&team.ID, &team.Name, &team.CreatedAt,
+	err := r.storage.conn(ctx).QueryRow(ctx, query, teamName, tenant.OrgID(ctx)).Scan(
+		&team.ID, &team.Name, &team.MinReviewers, &team.MaxReviewers,
+		&team.ReminderAfterMinutes, &team.EscalationAfterMinutes, &team.Version, &team.CreatedAt,
 	)
 
 	if err != nil {
@@ -50,13 +59,14 @@ func (r *TeamRepo) GetByName(ctx context.Context, teamName string) (*domain.Team
 func (r *TeamRepo) GetByID(ctx context.Context, teamID int64) (*domain.Team, error) {
 	const op = "repository.TeamRepo.GetByID"
 	const query = `
-        SELECT id, name, created_at 
+        SELECT id, name, min_reviewers, max_reviewers, reminder_after_minutes, escalation_after_minutes, version, created_at 
         FROM pr_system.teams 
-        WHERE id = $1`
+        WHERE id = $1 AND org_id = $2`
 
 	var team domain.Team
-	err := r.storage.DB.QueryRow(ctx, query, teamID).Scan(
-		&team.ID, &team.Name, &team.CreatedAt,
+	err := r.storage.conn(ctx).QueryRow(ctx, query, teamID, tenant.OrgID(ctx)).Scan(
+		&team.ID, &team.Name, &team.MinReviewers, &team.MaxReviewers,
+		&team.ReminderAfterMinutes, &team.EscalationAfterMinutes, &team.Version, &team.CreatedAt,
 	)
 
 	if err != nil {
@@ -68,21 +78,22 @@ func (r *TeamRepo) GetByID(ctx context.Context, teamID int64) (*domain.Team, err
 func (r *TeamRepo) GetWithUsers(ctx context.Context, teamID int64) (*domain.Team, error) {
 	const op = "repository.TeamRepo.GetWithUsers"
 
-	teamQuery := `SELECT id, name, created_at FROM pr_system.teams WHERE id = $1`
+	teamQuery := `SELECT id, name, min_reviewers, max_reviewers, reminder_after_minutes, escalation_after_minutes, version, created_at FROM pr_system.teams WHERE id = $1 AND org_id = $2`
 	var team domain.Team
-	err := r.storage.DB.QueryRow(ctx, teamQuery, teamID).Scan(
-		&team.ID, &team.Name, &team.CreatedAt,
+	err := r.storage.conn(ctx).QueryRow(ctx, teamQuery, teamID, tenant.OrgID(ctx)).Scan(
+		&team.ID, &team.Name, &team.MinReviewers, &team.MaxReviewers,
+		&team.ReminderAfterMinutes, &team.EscalationAfterMinutes, &team.Version, &team.CreatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	usersQuery := `
-        SELECT id, user_id, username, is_active, team_id, created_at 
+        SELECT id, user_id, username, is_active, team_id, role, version, created_at 
         FROM pr_system.users 
-        WHERE team_id = $1`
+        WHERE team_id = $1 AND org_id = $2`
 
-	rows, err := r.storage.DB.Query(ctx, usersQuery, teamID)
+	rows, err := r.storage.conn(ctx).Query(ctx, usersQuery, teamID, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -93,7 +104,7 @@ func (r *TeamRepo) GetWithUsers(ctx context.Context, teamID int64) (*domain.Team
 		var user domain.User
 		err := rows.Scan(
 			&user.ID, &user.UserID, &user.Username,
-			&user.IsActive, &user.TeamID, &user.CreatedAt,
+			&user.IsActive, &user.TeamID, &user.Role, &user.Version, &user.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -102,56 +113,81 @@ func (r *TeamRepo) GetWithUsers(ctx context.Context, teamID int64) (*domain.Team
 	}
 
 	team.Users = users
+
+	buddies, err := r.GetBuddyTeams(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, buddy := range buddies {
+		team.BuddyTeams = append(team.BuddyTeams, buddy.Name)
+	}
+
 	return &team, nil
 }
 
-func (r *TeamRepo) GetAllWithUsers(ctx context.Context) ([]domain.Team, error) {
+// GetAllWithUsers возвращает команды по имени после after (не больше limit,
+// limit <= 0 - все) и загружает их активных участников одним запросом
+func (r *TeamRepo) GetAllWithUsers(ctx context.Context, after string, limit int) ([]domain.Team, error) {
 	const op = "repository.TeamRepo.GetAllWithUsers"
 
-	teamsQuery := `SELECT id, name, created_at FROM pr_system.teams ORDER BY name`
-	rows, err := r.storage.DB.Query(ctx, teamsQuery)
+	var pageLimit *int
+	if limit > 0 {
+		pageLimit = &limit
+	}
+
+	teamsQuery := `
+        SELECT id, name, min_reviewers, max_reviewers, reminder_after_minutes, escalation_after_minutes, version, created_at 
+        FROM pr_system.teams 
+        WHERE org_id = $1 AND name > $2 
+        ORDER BY name 
+        LIMIT $3`
+	rows, err := r.storage.conn(ctx).Query(ctx, teamsQuery, tenant.OrgID(ctx), after, pageLimit)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
 	var teams []domain.Team
+	teamIDs := []int64{}
+	index := make(map[int64]int)
 	for rows.Next() {
 		var team domain.Team
-		err := rows.Scan(&team.ID, &team.Name, &team.CreatedAt)
+		err := rows.Scan(&team.ID, &team.Name, &team.MinReviewers, &team.MaxReviewers,
+			&team.ReminderAfterMinutes, &team.EscalationAfterMinutes, &team.Version, &team.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+		index[team.ID] = len(teams)
+		teamIDs = append(teamIDs, team.ID)
 		teams = append(teams, team)
 	}
+	if len(teams) == 0 {
+		return teams, nil
+	}
 
-	for i := range teams {
-		usersQuery := `
-            SELECT id, user_id, username, is_active, team_id, created_at 
-            FROM pr_system.users 
-            WHERE team_id = $1 AND is_active = true`
+	usersQuery := `
+        SELECT id, user_id, username, is_active, team_id, role, version, created_at 
+        FROM pr_system.users 
+        WHERE team_id = ANY($1) AND org_id = $2 AND is_active = true
+        ORDER BY id`
 
-		userRows, err := r.storage.DB.Query(ctx, usersQuery, teams[i].ID)
+	userRows, err := r.storage.conn(ctx).Query(ctx, usersQuery, teamIDs, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var user domain.User
+		err := userRows.Scan(
+			&user.ID, &user.UserID, &user.Username,
+			&user.IsActive, &user.TeamID, &user.Role, &user.Version, &user.CreatedAt,
+		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-
-		var users []domain.User
-		for userRows.Next() {
-			var user domain.User
-			err := userRows.Scan(
-				&user.ID, &user.UserID, &user.Username,
-				&user.IsActive, &user.TeamID, &user.CreatedAt,
-			)
-			if err != nil {
-				userRows.Close()
-				return nil, fmt.Errorf("%s: %w", op, err)
-			}
-			users = append(users, user)
-		}
-		userRows.Close()
-
-		teams[i].Users = users
+		i := index[user.TeamID]
+		teams[i].Users = append(teams[i].Users, user)
 	}
 
 	return teams, nil
@@ -159,13 +195,111 @@ func (r *TeamRepo) GetAllWithUsers(ctx context.Context) ([]domain.Team, error) {
 
 func (r *TeamRepo) ExistsByName(ctx context.Context, teamName string) (bool, error) {
 	const op = "repository.TeamRepo.ExistsByName"
-	const query = `SELECT EXISTS(SELECT 1 FROM pr_system.teams WHERE name = $1)`
+	const query = `SELECT EXISTS(SELECT 1 FROM pr_system.teams WHERE name = $1 AND org_id = $2)`
 
 	var exists bool
-	err := r.storage.DB.QueryRow(ctx, query, teamName).Scan(&exists)
+	err := r.storage.conn(ctx).QueryRow(ctx, query, teamName, tenant.OrgID(ctx)).Scan(&exists)
 
 	if err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 	return exists, nil
 }
+
+// GetBuddyTeams возвращает команды-партнеры в порядке приоритета
+func (r *TeamRepo) GetBuddyTeams(ctx context.Context, teamID int64) ([]domain.Team, error) {
+	const op = "repository.TeamRepo.GetBuddyTeams"
+	const query = `
+        SELECT t.id, t.name, t.min_reviewers, t.max_reviewers, t.reminder_after_minutes, t.escalation_after_minutes, t.version, t.created_at
+        FROM pr_system.team_buddies tb
+        JOIN pr_system.teams t ON tb.buddy_team_id = t.id
+        WHERE tb.team_id = $1 AND tb.org_id = $2
+        ORDER BY tb.priority`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, teamID, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var teams []domain.Team
+	for rows.Next() {
+		var team domain.Team
+		err := rows.Scan(&team.ID, &team.Name, &team.MinReviewers, &team.MaxReviewers,
+			&team.ReminderAfterMinutes, &team.EscalationAfterMinutes, &team.Version, &team.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, nil
+}
+
+// Update сохраняет настройки команды, если ее версия равна team.Version, и
+// увеличивает версию. Иначе - ErrVersionConflict.
+func (r *TeamRepo) Update(ctx context.Context, team *domain.Team) error {
+	const op = "repository.TeamRepo.Update"
+	const query = `
+        UPDATE pr_system.teams 
+        SET min_reviewers = $1, max_reviewers = $2, reminder_after_minutes = $3, escalation_after_minutes = $4, 
+            version = version + 1 
+        WHERE id = $5 AND org_id = $6 AND version = $7 
+        RETURNING version`
+
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, team.MinReviewers, team.MaxReviewers, team.ReminderAfterMinutes, team.EscalationAfterMinutes,
+		team.ID, tenant.OrgID(ctx), team.Version,
+	).Scan(&team.Version)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetBuddyTeams заменяет список команд-партнеров, если версия команды равна
+// version, и увеличивает версию. Иначе - ErrVersionConflict. Приоритет
+// определяется порядком buddyTeamIDs.
+func (r *TeamRepo) SetBuddyTeams(ctx context.Context, teamID int64, version int, buddyTeamIDs []int64) error {
+	const op = "repository.TeamRepo.SetBuddyTeams"
+
+	tx, err := r.storage.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	orgID := tenant.OrgID(ctx)
+	tag, err := tx.Exec(ctx, `
+            UPDATE pr_system.teams SET version = version + 1 
+            WHERE id = $1 AND org_id = $2 AND version = $3`, teamID, orgID, version)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM pr_system.team_buddies WHERE team_id = $1 AND org_id = $2`, teamID, orgID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, buddyID := range buddyTeamIDs {
+		_, err = tx.Exec(ctx, `
+            INSERT INTO pr_system.team_buddies (team_id, buddy_team_id, priority, org_id) 
+            VALUES ($1, $2, $3, $4)`, teamID, buddyID, i+1, orgID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
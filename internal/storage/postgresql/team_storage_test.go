@@ -3,6 +3,7 @@ package postgresql
 import (
 	"context"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestTeamRepo_Create(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	teamRepo := NewTeamRepo(storage)
 
 	t.Run("successful creation", func(t *testing.T) {
@@ -24,6 +25,17 @@ func TestTeamRepo_Create(t *testing.T) {
 		assert.False(t, team.CreatedAt.IsZero())
 	})
 
+	t.Run("reviewer bounds are persisted", func(t *testing.T) {
+		team := &domain.Team{Name: "frontend", MinReviewers: 0, MaxReviewers: 3}
+		err := teamRepo.Create(ctx, team)
+		require.NoError(t, err)
+
+		found, err := teamRepo.GetByID(ctx, team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, found.MinReviewers)
+		assert.Equal(t, 3, found.MaxReviewers)
+	})
+
 	t.Run("duplicate team name", func(t *testing.T) {
 		team := &domain.Team{Name: "backend"}
 		err := teamRepo.Create(ctx, team)
@@ -35,7 +47,7 @@ func TestTeamRepo_GetByName(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	teamRepo := NewTeamRepo(storage)
 
 	// Создаем команду
@@ -60,7 +72,7 @@ func TestTeamRepo_GetByID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	teamRepo := NewTeamRepo(storage)
 
 	// Создаем команду
@@ -85,7 +97,7 @@ func TestTeamRepo_GetWithUsers(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	teamRepo := NewTeamRepo(storage)
 	userStorage := NewUserStorage(storage)
 
@@ -118,7 +130,7 @@ func TestTeamRepo_GetAllWithUsers(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	teamRepo := NewTeamRepo(storage)
 	userStorage := NewUserStorage(storage)
 
@@ -144,20 +156,33 @@ func TestTeamRepo_GetAllWithUsers(t *testing.T) {
 	}
 
 	t.Run("get all teams with users", func(t *testing.T) {
-		found, err := teamRepo.GetAllWithUsers(ctx)
+		found, err := teamRepo.GetAllWithUsers(ctx, "", 0)
 		require.NoError(t, err)
 		assert.Len(t, found, 2)
 		for _, team := range found {
 			assert.Greater(t, len(team.Users), 0)
 		}
 	})
+
+	t.Run("pages by name", func(t *testing.T) {
+		first, err := teamRepo.GetAllWithUsers(ctx, "", 1)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, "backend", first[0].Name)
+
+		second, err := teamRepo.GetAllWithUsers(ctx, first[0].Name, 1)
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, "frontend", second[0].Name)
+		assert.Equal(t, "u-frontend", second[0].Users[0].UserID)
+	})
 }
 
 func TestTeamRepo_ExistsByName(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	teamRepo := NewTeamRepo(storage)
 
 	// Создаем команду
@@ -178,3 +203,43 @@ func TestTeamRepo_ExistsByName(t *testing.T) {
 	})
 }
 
+
+func TestTeamRepo_BuddyTeams(t *testing.T) {
+	storage, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	teamRepo := NewTeamRepo(storage)
+
+	tiny := &domain.Team{Name: "tiny"}
+	backend := &domain.Team{Name: "backend"}
+	frontend := &domain.Team{Name: "frontend"}
+	for _, team := range []*domain.Team{tiny, backend, frontend} {
+		require.NoError(t, teamRepo.Create(ctx, team))
+	}
+
+	t.Run("buddies keep priority order", func(t *testing.T) {
+		err := teamRepo.SetBuddyTeams(ctx, tiny.ID, tiny.Version, []int64{frontend.ID, backend.ID})
+		require.NoError(t, err)
+
+		buddies, err := teamRepo.GetBuddyTeams(ctx, tiny.ID)
+		require.NoError(t, err)
+		require.Len(t, buddies, 2)
+		assert.Equal(t, "frontend", buddies[0].Name)
+		assert.Equal(t, "backend", buddies[1].Name)
+
+		found, err := teamRepo.GetWithUsers(ctx, tiny.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"frontend", "backend"}, found.BuddyTeams)
+	})
+
+	t.Run("set replaces previous list", func(t *testing.T) {
+		err := teamRepo.SetBuddyTeams(ctx, tiny.ID, tiny.Version+1, []int64{backend.ID})
+		require.NoError(t, err)
+
+		buddies, err := teamRepo.GetBuddyTeams(ctx, tiny.ID)
+		require.NoError(t, err)
+		require.Len(t, buddies, 1)
+		assert.Equal(t, "backend", buddies[0].Name)
+	})
+}
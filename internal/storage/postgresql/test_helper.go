@@ -127,6 +127,12 @@ func cleanupDB(t *testing.T, db *pgxpool.Pool) {
 	ctx := context.Background()
 
 	tables := []string{
+		"pr_system.idempotency_keys",
+		"pr_system.api_tokens",
+		"pr_system.user_activity_log",
+		"pr_system.pr_reviewer_history",
+		"pr_system.review_events",
+		"pr_system.team_buddies",
 		"pr_system.pr_reviewers",
 		"pr_system.pull_requests",
 		"pr_system.users",
@@ -142,6 +148,9 @@ func cleanupDB(t *testing.T, db *pgxpool.Pool) {
 		}
 	}
 
+	// Организации, созданные тестами; организация по умолчанию остается
+	_, _ = db.Exec(ctx, `DELETE FROM pr_system.organizations WHERE id <> 1`)
+
 	// Вставляем статусы обратно
 	_, _ = db.Exec(ctx, `
 		INSERT INTO pr_system.statuses (id, name) 
@@ -204,6 +213,209 @@ func runMigrations(db *pgxpool.Pool, ctx context.Context) error {
 
 		CREATE INDEX IF NOT EXISTS idx_pr_reviewers_reviewer_id ON pr_system.pr_reviewers(reviewer_id);
 		CREATE INDEX IF NOT EXISTS idx_pr_reviewers_pr_id ON pr_system.pr_reviewers(pr_id);
+
+		ALTER TABLE pr_system.teams
+			ADD COLUMN IF NOT EXISTS min_reviewers INTEGER DEFAULT 1 NOT NULL,
+			ADD COLUMN IF NOT EXISTS max_reviewers INTEGER DEFAULT 2 NOT NULL;
+
+		ALTER TABLE pr_system.pr_reviewers
+			ADD COLUMN IF NOT EXISTS reason VARCHAR(32) DEFAULT 'AUTO' NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS pr_system.team_buddies (
+			team_id BIGINT NOT NULL REFERENCES pr_system.teams(id) ON DELETE CASCADE,
+			buddy_team_id BIGINT NOT NULL REFERENCES pr_system.teams(id) ON DELETE CASCADE,
+			priority INTEGER NOT NULL,
+			PRIMARY KEY (team_id, buddy_team_id),
+			CHECK (team_id <> buddy_team_id)
+		);
+
+		ALTER TABLE pr_system.users
+			ADD COLUMN IF NOT EXISTS role VARCHAR(16) DEFAULT 'member' NOT NULL;
+
+		ALTER TABLE pr_system.teams
+			ADD COLUMN IF NOT EXISTS reminder_after_minutes INTEGER DEFAULT 0 NOT NULL,
+			ADD COLUMN IF NOT EXISTS escalation_after_minutes INTEGER DEFAULT 0 NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS pr_system.review_events (
+			id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			pr_id BIGINT NOT NULL REFERENCES pr_system.pull_requests(id) ON DELETE CASCADE,
+			reviewer_id BIGINT NOT NULL REFERENCES pr_system.users(id),
+			kind VARCHAR(32) NOT NULL,
+			action VARCHAR(32) NOT NULL DEFAULT '',
+			target_id BIGINT REFERENCES pr_system.users(id),
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			UNIQUE (pr_id, reviewer_id, kind)
+		);
+
+		ALTER TABLE pr_system.pr_reviewers
+			ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+		CREATE TABLE IF NOT EXISTS pr_system.pr_reviewer_history (
+			id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			pr_id BIGINT NOT NULL REFERENCES pr_system.pull_requests(id) ON DELETE CASCADE,
+			reviewer_id BIGINT NOT NULL REFERENCES pr_system.users(id),
+			reason VARCHAR(32) NOT NULL,
+			assigned_at TIMESTAMPTZ NOT NULL,
+			reviewed_at TIMESTAMPTZ,
+			removed_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS pr_system.user_activity_log (
+			id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES pr_system.users(id) ON DELETE CASCADE,
+			is_active BOOLEAN NOT NULL,
+			changed_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS pr_system.schema_migrations (
+			version BIGINT PRIMARY KEY,
+			applied_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS pr_system.api_tokens (
+			id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			token_hash CHAR(64) NOT NULL UNIQUE,
+			prefix VARCHAR(16) NOT NULL,
+			scopes TEXT[] NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			expires_at TIMESTAMPTZ,
+			last_used_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ
+		);
+
+		ALTER TABLE pr_system.api_tokens
+			ADD COLUMN IF NOT EXISTS user_id BIGINT REFERENCES pr_system.users(id) ON DELETE CASCADE;
+
+		-- Организации (арендаторы). Команды, пользователи, PR и все связанные с ними
+		-- данные принадлежат одной организации; имена команд, user_id и
+		-- pull_request_id уникальны только внутри нее.
+		CREATE TABLE IF NOT EXISTS pr_system.organizations (
+			id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			slug VARCHAR(64) NOT NULL UNIQUE,
+			name VARCHAR(255) NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+		);
+
+		INSERT INTO pr_system.organizations (id, slug, name) VALUES (1, 'default', 'Default')
+		ON CONFLICT (id) DO NOTHING;
+
+		SELECT setval(pg_get_serial_sequence('pr_system.organizations', 'id'), (SELECT MAX(id) FROM pr_system.organizations));
+
+		-- Существующие данные относятся к организации по умолчанию. Значение по
+		-- умолчанию затем снимается: запрос, забывший org_id, должен падать, а не
+		-- записывать данные в чужую организацию.
+		ALTER TABLE pr_system.teams ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.users ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.pull_requests ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.pr_reviewers ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.team_buddies ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.review_events ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.pr_reviewer_history ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.user_activity_log ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+		ALTER TABLE pr_system.api_tokens ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+
+		ALTER TABLE pr_system.teams ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.users ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.pull_requests ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.pr_reviewers ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.team_buddies ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.review_events ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.pr_reviewer_history ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.user_activity_log ALTER COLUMN org_id DROP DEFAULT;
+		ALTER TABLE pr_system.api_tokens ALTER COLUMN org_id DROP DEFAULT;
+
+		-- Бизнес-идентификаторы уникальны внутри организации
+		ALTER TABLE pr_system.teams DROP CONSTRAINT IF EXISTS teams_name_key;
+		ALTER TABLE pr_system.users DROP CONSTRAINT IF EXISTS users_user_id_key;
+		ALTER TABLE pr_system.pull_requests DROP CONSTRAINT IF EXISTS pull_requests_pull_request_id_key;
+
+		CREATE UNIQUE INDEX IF NOT EXISTS teams_org_name_key ON pr_system.teams(org_id, name);
+		CREATE UNIQUE INDEX IF NOT EXISTS users_org_user_id_key ON pr_system.users(org_id, user_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS pull_requests_org_pull_request_id_key ON pr_system.pull_requests(org_id, pull_request_id);
+
+		-- Составные ключи (org_id, id) позволяют ссылаться только на строки той же
+		-- организации: назначить ревьювером пользователя другой организации, взять
+		-- ее команду в партнеры и т.п. не даст сама БД
+		CREATE UNIQUE INDEX IF NOT EXISTS teams_org_id_key ON pr_system.teams(org_id, id);
+		CREATE UNIQUE INDEX IF NOT EXISTS users_org_id_key ON pr_system.users(org_id, id);
+		CREATE UNIQUE INDEX IF NOT EXISTS pull_requests_org_id_key ON pr_system.pull_requests(org_id, id);
+
+		ALTER TABLE pr_system.users
+			DROP CONSTRAINT IF EXISTS users_org_team_fkey,
+			ADD CONSTRAINT users_org_team_fkey FOREIGN KEY (org_id, team_id) REFERENCES pr_system.teams(org_id, id);
+
+		ALTER TABLE pr_system.pull_requests
+			DROP CONSTRAINT IF EXISTS pull_requests_org_author_fkey,
+			ADD CONSTRAINT pull_requests_org_author_fkey FOREIGN KEY (org_id, author_id) REFERENCES pr_system.users(org_id, id);
+
+		ALTER TABLE pr_system.pr_reviewers
+			DROP CONSTRAINT IF EXISTS pr_reviewers_org_pr_fkey,
+			ADD CONSTRAINT pr_reviewers_org_pr_fkey FOREIGN KEY (org_id, pr_id) REFERENCES pr_system.pull_requests(org_id, id) ON DELETE CASCADE,
+			DROP CONSTRAINT IF EXISTS pr_reviewers_org_reviewer_fkey,
+			ADD CONSTRAINT pr_reviewers_org_reviewer_fkey FOREIGN KEY (org_id, reviewer_id) REFERENCES pr_system.users(org_id, id);
+
+		ALTER TABLE pr_system.team_buddies
+			DROP CONSTRAINT IF EXISTS team_buddies_org_team_fkey,
+			ADD CONSTRAINT team_buddies_org_team_fkey FOREIGN KEY (org_id, team_id) REFERENCES pr_system.teams(org_id, id) ON DELETE CASCADE,
+			DROP CONSTRAINT IF EXISTS team_buddies_org_buddy_fkey,
+			ADD CONSTRAINT team_buddies_org_buddy_fkey FOREIGN KEY (org_id, buddy_team_id) REFERENCES pr_system.teams(org_id, id) ON DELETE CASCADE;
+
+		ALTER TABLE pr_system.review_events
+			DROP CONSTRAINT IF EXISTS review_events_org_pr_fkey,
+			ADD CONSTRAINT review_events_org_pr_fkey FOREIGN KEY (org_id, pr_id) REFERENCES pr_system.pull_requests(org_id, id) ON DELETE CASCADE,
+			DROP CONSTRAINT IF EXISTS review_events_org_reviewer_fkey,
+			ADD CONSTRAINT review_events_org_reviewer_fkey FOREIGN KEY (org_id, reviewer_id) REFERENCES pr_system.users(org_id, id),
+			DROP CONSTRAINT IF EXISTS review_events_org_target_fkey,
+			ADD CONSTRAINT review_events_org_target_fkey FOREIGN KEY (org_id, target_id) REFERENCES pr_system.users(org_id, id);
+
+		ALTER TABLE pr_system.pr_reviewer_history
+			DROP CONSTRAINT IF EXISTS pr_reviewer_history_org_pr_fkey,
+			ADD CONSTRAINT pr_reviewer_history_org_pr_fkey FOREIGN KEY (org_id, pr_id) REFERENCES pr_system.pull_requests(org_id, id) ON DELETE CASCADE,
+			DROP CONSTRAINT IF EXISTS pr_reviewer_history_org_reviewer_fkey,
+			ADD CONSTRAINT pr_reviewer_history_org_reviewer_fkey FOREIGN KEY (org_id, reviewer_id) REFERENCES pr_system.users(org_id, id);
+
+		ALTER TABLE pr_system.user_activity_log
+			DROP CONSTRAINT IF EXISTS user_activity_log_org_user_fkey,
+			ADD CONSTRAINT user_activity_log_org_user_fkey FOREIGN KEY (org_id, user_id) REFERENCES pr_system.users(org_id, id) ON DELETE CASCADE;
+
+		ALTER TABLE pr_system.api_tokens
+			DROP CONSTRAINT IF EXISTS api_tokens_org_user_fkey,
+			ADD CONSTRAINT api_tokens_org_user_fkey FOREIGN KEY (org_id, user_id) REFERENCES pr_system.users(org_id, id) ON DELETE CASCADE;
+
+		CREATE TABLE IF NOT EXISTS pr_system.idempotency_keys (
+			org_id BIGINT NOT NULL REFERENCES pr_system.organizations(id) ON DELETE CASCADE,
+			client VARCHAR(128) NOT NULL,
+			key VARCHAR(255) NOT NULL,
+			request_hash CHAR(64) NOT NULL,
+			status_code INTEGER,
+			content_type VARCHAR(255),
+			response_body BYTEA,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (org_id, client, key)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON pr_system.idempotency_keys(expires_at);
+
+		ALTER TABLE pr_system.pull_requests ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1 NOT NULL;
+		ALTER TABLE pr_system.teams ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1 NOT NULL;
+		ALTER TABLE pr_system.users ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1 NOT NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_pull_requests_org_created ON pr_system.pull_requests(org_id, created_at, id);
+
+		ALTER TABLE pr_system.review_events ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ NOT NULL;
+		ALTER TABLE pr_system.review_events
+			DROP CONSTRAINT IF EXISTS review_events_pr_id_reviewer_id_kind_key,
+			DROP CONSTRAINT IF EXISTS review_events_assignment_kind_key,
+			ADD CONSTRAINT review_events_assignment_kind_key UNIQUE (pr_id, reviewer_id, assigned_at, kind);
+
+		CREATE INDEX IF NOT EXISTS idx_pr_reviewer_history_reviewed_at ON pr_system.pr_reviewer_history(pr_id, reviewed_at)
+			WHERE reviewed_at IS NOT NULL;
+
+		INSERT INTO pr_system.schema_migrations (version)
+		SELECT generate_series(1, 16)
+		ON CONFLICT (version) DO NOTHING;
 	`
 
 	_, err := db.Exec(ctx, migrationSQL)
@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// lastUsedResolution - как часто обновляется last_used_at, чтобы не писать
+// в БД на каждый запрос
+const lastUsedResolution = time.Minute
+
+type TokenRepo struct {
+	storage *Storage
+}
+
+func NewTokenRepo(storage *Storage) *TokenRepo {
+	return &TokenRepo{storage: storage}
+}
+
+const selectTokens = `
+        SELECT 
+            t.id, t.org_id, t.name, t.token_hash, t.prefix, t.scopes, COALESCE(u.user_id, ''), 
+            t.created_at, t.expires_at, t.last_used_at, t.revoked_at
+        FROM pr_system.api_tokens t
+        LEFT JOIN pr_system.users u ON t.user_id = u.id`
+
+func scanToken(row pgx.Row, token *domain.APIToken) error {
+	return row.Scan(
+		&token.ID, &token.OrgID, &token.Name, &token.Hash, &token.Prefix, &token.Scopes, &token.UserID,
+		&token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt,
+	)
+}
+
+func (r *TokenRepo) Create(ctx context.Context, token *domain.APIToken) error {
+	const op = "repository.TokenRepo.Create"
+	const query = `
+        INSERT INTO pr_system.api_tokens (name, token_hash, prefix, scopes, expires_at, user_id, org_id) 
+        VALUES ($1, $2, $3, $4, $5, (SELECT id FROM pr_system.users WHERE user_id = NULLIF($6, '') AND org_id = $7), $7) 
+        RETURNING id, org_id, created_at`
+
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, token.Name, token.Hash, token.Prefix, token.Scopes, token.ExpiresAt, token.UserID, tenant.OrgID(ctx),
+	).Scan(&token.ID, &token.OrgID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetByHash ищет токен среди всех организаций: именно токен определяет
+// организацию запроса
+func (r *TokenRepo) GetByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	const op = "repository.TokenRepo.GetByHash"
+	const query = selectTokens + ` WHERE t.token_hash = $1`
+
+	var token domain.APIToken
+	err := scanToken(r.storage.conn(ctx).QueryRow(ctx, query, hash), &token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &token, nil
+}
+
+func (r *TokenRepo) List(ctx context.Context) ([]domain.APIToken, error) {
+	const op = "repository.TokenRepo.List"
+	const query = selectTokens + ` WHERE t.org_id = $1 ORDER BY t.id`
+
+	rows, err := r.storage.conn(ctx).Query(ctx, query, tenant.OrgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	tokens := []domain.APIToken{}
+	for rows.Next() {
+		var token domain.APIToken
+		if err := scanToken(rows, &token); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke отзывает токен. Повторный отзыв не меняет время первого.
+func (r *TokenRepo) Revoke(ctx context.Context, id int64, at time.Time) error {
+	const op = "repository.TokenRepo.Revoke"
+	const query = `
+        UPDATE pr_system.api_tokens 
+        SET revoked_at = COALESCE(revoked_at, $2) 
+        WHERE id = $1 AND org_id = $3`
+
+	tag, err := r.storage.conn(ctx).Exec(ctx, query, id, at, tenant.OrgID(ctx))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// TouchLastUsed обновляет время последнего использования не чаще, чем раз
+// в lastUsedResolution
+func (r *TokenRepo) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	const op = "repository.TokenRepo.TouchLastUsed"
+	const query = `
+        UPDATE pr_system.api_tokens 
+        SET last_used_at = $2 
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - make_interval(secs => $3))`
+
+	if _, err := r.storage.conn(ctx).Exec(ctx, query, id, at, lastUsedResolution.Seconds()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRepo(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	repo := NewTokenRepo(s)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	token := &domain.APIToken{
+		Name:      "ci",
+		Prefix:    "ras_abcdefgh",
+		Scopes:    []string{domain.ScopeRead, domain.ScopePRWrite},
+		ExpiresAt: &expiresAt,
+		Hash:      "4b227777d4dd1fc61c6f884f48641d02b4d121d3fd328cb08b5531fcacdabf8a",
+	}
+	require.NoError(t, repo.Create(ctx, token))
+	assert.NotZero(t, token.ID)
+
+	t.Run("get by hash", func(t *testing.T) {
+		got, err := repo.GetByHash(ctx, token.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, got.ID)
+		assert.Equal(t, token.Scopes, got.Scopes)
+		assert.True(t, expiresAt.Equal(*got.ExpiresAt))
+		assert.Nil(t, got.LastUsedAt)
+
+		_, err = repo.GetByHash(ctx, "unknown")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("bound to user", func(t *testing.T) {
+		team := &domain.Team{Name: "backend", MinReviewers: 1, MaxReviewers: 2}
+		require.NoError(t, NewTeamRepo(s).Create(ctx, team))
+		user := &domain.User{UserID: "u1", Username: "Lead", IsActive: true, TeamID: team.ID, Role: domain.RoleLead}
+		require.NoError(t, NewUserStorage(s).Create(ctx, user))
+
+		bound := &domain.APIToken{
+			Name:   "lead",
+			Prefix: "ras_ijklmnop",
+			Scopes: []string{domain.ScopeTeamWrite},
+			UserID: "u1",
+			Hash:   "ef2d127de37b942baad06145e54b0c619a1f22327b2ebbcfbec78f5564afe39d",
+		}
+		require.NoError(t, repo.Create(ctx, bound))
+
+		got, err := repo.GetByHash(ctx, bound.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "u1", got.UserID)
+
+		unbound, err := repo.GetByHash(ctx, token.Hash)
+		require.NoError(t, err)
+		assert.Empty(t, unbound.UserID)
+	})
+
+	t.Run("touch last used is throttled", func(t *testing.T) {
+		first := time.Now().UTC().Truncate(time.Microsecond)
+		require.NoError(t, repo.TouchLastUsed(ctx, token.ID, first))
+		require.NoError(t, repo.TouchLastUsed(ctx, token.ID, first.Add(time.Second)))
+
+		got, err := repo.GetByHash(ctx, token.Hash)
+		require.NoError(t, err)
+		require.NotNil(t, got.LastUsedAt)
+		assert.True(t, first.Equal(*got.LastUsedAt))
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		at := time.Now().UTC().Truncate(time.Microsecond)
+		require.NoError(t, repo.Revoke(ctx, token.ID, at))
+		require.NoError(t, repo.Revoke(ctx, token.ID, at.Add(time.Hour)))
+
+		tokens, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		require.NotNil(t, tokens[0].RevokedAt)
+		assert.True(t, at.Equal(*tokens[0].RevokedAt))
+
+		assert.ErrorIs(t, repo.Revoke(ctx, token.ID+1000, at), storage.ErrNotFound)
+	})
+}
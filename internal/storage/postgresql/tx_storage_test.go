@@ -0,0 +1,72 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_InTx(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	teams := NewTeamRepo(s)
+	users := NewUserStorage(s)
+
+	t.Run("error rolls back every repository", func(t *testing.T) {
+		errStop := errors.New("stop")
+		err := s.InTx(ctx, func(ctx context.Context) error {
+			team := &domain.Team{Name: "rolled-back"}
+			require.NoError(t, teams.Create(ctx, team))
+			require.NoError(t, users.Create(ctx, &domain.User{UserID: "tx1", Username: "Tx", IsActive: true, TeamID: team.ID}))
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+
+		_, err = teams.GetByName(ctx, "rolled-back")
+		assert.Error(t, err)
+		_, err = users.GetByUserID(ctx, "tx1")
+		assert.Error(t, err)
+	})
+
+	t.Run("success commits", func(t *testing.T) {
+		err := s.InTx(ctx, func(ctx context.Context) error {
+			return teams.Create(ctx, &domain.Team{Name: "committed"})
+		})
+		require.NoError(t, err)
+
+		_, err = teams.GetByName(ctx, "committed")
+		assert.NoError(t, err)
+	})
+}
+
+func TestTeamRepo_Update(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	teams := NewTeamRepo(s)
+
+	team := &domain.Team{Name: "backend", MinReviewers: 1, MaxReviewers: 2}
+	require.NoError(t, teams.Create(ctx, team))
+	stale := *team
+
+	team.MaxReviewers = 3
+	team.ReminderAfterMinutes = 60
+	require.NoError(t, teams.Update(ctx, team))
+	assert.Equal(t, stale.Version+1, team.Version)
+
+	got, err := teams.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.MaxReviewers)
+	assert.Equal(t, 60, got.ReminderAfterMinutes)
+
+	assert.ErrorIs(t, teams.Update(ctx, &stale), storage.ErrVersionConflict)
+}
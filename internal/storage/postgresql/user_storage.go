@@ -2,8 +2,14 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type UserStorage struct {
@@ -18,13 +24,19 @@ func (r *UserStorage) Create(ctx context.Context, user *domain.User) error {
 	const op = "storage.postgresql.UserStorage.Create"
 
 	query := `
-		INSERT INTO pr_system.users (user_id, username, team_id, is_active) 
-		VALUES ($1, $2, $3, $4) 
-		RETURNING id, created_at`
+		WITH created AS (
+			INSERT INTO pr_system.users (user_id, username, team_id, is_active, role, org_id) 
+			VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'member'), $6) 
+			RETURNING id, is_active, role, created_at, version, org_id
+		), logged AS (
+			INSERT INTO pr_system.user_activity_log (user_id, is_active, changed_at, org_id)
+			SELECT id, is_active, created_at, org_id FROM created
+		)
+		SELECT id, role, created_at, version FROM created`
 
-	err := r.storage.DB.QueryRow(
-		ctx, query, user.UserID, user.Username, user.TeamID, user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt)
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, user.UserID, user.Username, user.TeamID, user.IsActive, user.Role, tenant.OrgID(ctx),
+	).Scan(&user.ID, &user.Role, &user.CreatedAt, &user.Version)
 
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -32,38 +44,88 @@ func (r *UserStorage) Create(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
+// Update сохраняет пользователя, если его версия не изменилась с момента
+// чтения (user.Version), и увеличивает версию. Иначе - ErrVersionConflict.
 func (r *UserStorage) Update(ctx context.Context, user *domain.User) error {
 	const op = "storage.postgresql.UserStorage.Update"
 
 	query := `
-		UPDATE pr_system.users 
-		SET username = $1, team_id = $2, is_active = $3 
-		WHERE user_id = $4 
-		RETURNING id, created_at`
+		WITH old AS (
+			SELECT id, is_active, version FROM pr_system.users WHERE user_id = $5 AND org_id = $6 FOR UPDATE
+		), updated AS (
+			UPDATE pr_system.users u
+			SET username = $1, team_id = $2, is_active = $3, role = COALESCE(NULLIF($4, ''), u.role), version = u.version + 1 
+			FROM old
+			WHERE u.id = old.id AND old.version = $7 
+			RETURNING u.id, u.is_active, u.role, u.created_at, u.version, u.org_id, old.is_active AS was_active
+		), logged AS (
+			INSERT INTO pr_system.user_activity_log (user_id, is_active, org_id)
+			SELECT id, is_active, org_id FROM updated WHERE is_active <> was_active
+		)
+		SELECT old.id, updated.role, updated.created_at, updated.version 
+		FROM old LEFT JOIN updated ON updated.id = old.id`
 
-	err := r.storage.DB.QueryRow(
-		ctx, query, user.Username, user.TeamID, user.IsActive, user.UserID,
-	).Scan(&user.ID, &user.CreatedAt)
+	var (
+		role      *string
+		createdAt *time.Time
+		version   *int
+	)
+	err := r.storage.conn(ctx).QueryRow(
+		ctx, query, user.Username, user.TeamID, user.IsActive, user.Role, user.UserID, tenant.OrgID(ctx), user.Version,
+	).Scan(&user.ID, &role, &createdAt, &version)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if version == nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+	}
+
+	user.Role, user.CreatedAt, user.Version = *role, *createdAt, *version
 	return nil
 }
 
+func (r *UserStorage) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	const op = "storage.postgresql.UserStorage.GetByID"
+
+	query := `
+		SELECT id, user_id, username, is_active, team_id, role, version, created_at 
+		FROM pr_system.users 
+		WHERE id = $1 AND org_id = $2`
+
+	var user domain.User
+	err := r.storage.conn(ctx).QueryRow(ctx, query, id, tenant.OrgID(ctx)).Scan(
+		&user.ID, &user.UserID, &user.Username, &user.IsActive, &user.TeamID, &user.Role, &user.Version, &user.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &user, nil
+}
+
 func (r *UserStorage) GetByUserID(ctx context.Context, userID string) (*domain.User, error) {
 	const op = "storage.postgresql.UserStorage.GetByUserID"
 
 	query := `
-		SELECT id, user_id, username, is_active, team_id, created_at 
+		SELECT id, user_id, username, is_active, team_id, role, version, created_at 
 		FROM pr_system.users 
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND org_id = $2`
 
 	var user domain.User
-	err := r.storage.DB.QueryRow(ctx, query, userID).Scan(
-		&user.ID, &user.UserID, &user.Username, &user.IsActive, &user.TeamID, &user.CreatedAt,
+	err := r.storage.conn(ctx).QueryRow(ctx, query, userID, tenant.OrgID(ctx)).Scan(
+		&user.ID, &user.UserID, &user.Username, &user.IsActive, &user.TeamID, &user.Role, &user.Version, &user.CreatedAt,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -74,11 +136,11 @@ func (r *UserStorage) GetByTeamID(ctx context.Context, teamID int64) ([]domain.U
 	const op = "storage.postgresql.UserStorage.GetByTeamID"
 
 	query := `
-	SELECT id, user_id, username, is_active, team_id, created_at 
+	SELECT id, user_id, username, is_active, team_id, role, version, created_at 
 	FROM pr_system.users 
-	WHERE team_id = $1`
+	WHERE team_id = $1 AND org_id = $2`
 
-	rows, err := r.storage.DB.Query(ctx, query, teamID)
+	rows, err := r.storage.conn(ctx).Query(ctx, query, teamID, tenant.OrgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -89,7 +151,7 @@ func (r *UserStorage) GetByTeamID(ctx context.Context, teamID int64) ([]domain.U
 		var user domain.User
 		err := rows.Scan(
 			&user.ID, &user.UserID, &user.Username,
-			&user.IsActive, &user.TeamID, &user.CreatedAt,
+			&user.IsActive, &user.TeamID, &user.Role, &user.Version, &user.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
@@ -100,63 +162,49 @@ func (r *UserStorage) GetByTeamID(ctx context.Context, teamID int64) ([]domain.U
 	return users, nil
 }
 
+// SetIsActive меняет флаг активности и записывает изменение в журнал
+// активности, если значение действительно изменилось
 func (r *UserStorage) SetIsActive(ctx context.Context, userID string, isActive bool) error {
 	const op = "storage.postgresql.UserStorage.SetIsActive"
 
 	query := `
-		UPDATE pr_system.users 
-		SET is_active = $1 
-		WHERE user_id = $2`
+		WITH old AS (
+			SELECT id, is_active FROM pr_system.users WHERE user_id = $2 AND org_id = $3 FOR UPDATE
+		), updated AS (
+			UPDATE pr_system.users u
+			SET is_active = $1, version = u.version + 1 
+			FROM old
+			WHERE u.id = old.id
+			RETURNING u.id, u.org_id, old.is_active AS was_active
+		), logged AS (
+			INSERT INTO pr_system.user_activity_log (user_id, is_active, org_id)
+			SELECT id, $1, org_id FROM updated WHERE was_active <> $1
+		)
+		SELECT COUNT(*) FROM updated`
 
-	result, err := r.storage.DB.Exec(ctx, query, isActive, userID)
+	var updated int
+	err := r.storage.conn(ctx).QueryRow(ctx, query, isActive, userID, tenant.OrgID(ctx)).Scan(&updated)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
+	if updated == 0 {
 		return fmt.Errorf("%s: user not found", op)
 	}
 
 	return nil
 }
 
-func (r *UserStorage) GetByReviewerID(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+// GetByReviewerID возвращает страницу PR, где пользователь назначен
+// ревьювером. Фильтры и сортировка выполняются в запросе (см. listPRs).
+func (r *UserStorage) GetByReviewerID(ctx context.Context, userID string, filter domain.PRFilter) ([]domain.PullRequest, error) {
 	const op = "storage.postgresql.UserStorage.GetByReviewerID"
 
-	query := `
-		SELECT 
-			pr.id, pr.pull_request_id, pr.pull_request_name, 
-			pr.author_id, pr.status_id, pr.merged_at, pr.created_at,
-			u.id, u.user_id, u.username, u.is_active, u.team_id, u.created_at
-		FROM pr_system.pull_requests pr
-		JOIN pr_system.pr_reviewers prr ON pr.id = prr.pr_id
-		JOIN pr_system.users u ON pr.author_id = u.id
-		JOIN pr_system.users reviewer ON prr.reviewer_id = reviewer.id
-		WHERE reviewer.user_id = $1`
-
-	rows, err := r.storage.DB.Query(ctx, query, userID)
+	filter.ReviewerID = userID
+	prs, err := listPRs(ctx, r.storage, filter)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer rows.Close()
-
-	var prs []domain.PullRequest
-	for rows.Next() {
-		var pr domain.PullRequest
-		var author domain.User
-		err := rows.Scan(
-			&pr.ID, &pr.PullRequestID, &pr.PullRequestName,
-			&pr.AuthorID, &pr.StatusID, &pr.MergedAt, &pr.CreatedAt,
-			&author.ID, &author.UserID, &author.Username, &author.IsActive, &author.TeamID, &author.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		pr.Author = &author
-		prs = append(prs, pr)
-	}
-
 	return prs, nil
 }
 
@@ -164,11 +212,16 @@ func (r *UserStorage) DeactivateByTeamID(ctx context.Context, teamID int64) erro
 	const op = "storage.postgresql.UserStorage.DeactivateByTeamID"
 
 	query := `
-		UPDATE pr_system.users 
-		SET is_active = false 
-		WHERE team_id = $1`
+		WITH deactivated AS (
+			UPDATE pr_system.users 
+			SET is_active = false, version = version + 1 
+			WHERE team_id = $1 AND org_id = $2 AND is_active = true
+			RETURNING id, org_id
+		)
+		INSERT INTO pr_system.user_activity_log (user_id, is_active, org_id)
+		SELECT id, false, org_id FROM deactivated`
 
-	_, err := r.storage.DB.Exec(ctx, query, teamID)
+	_, err := r.storage.conn(ctx).Exec(ctx, query, teamID, tenant.OrgID(ctx))
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -3,6 +3,7 @@ package postgresql
 import (
 	"context"
 	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/tenant"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestUserStorage_Create(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 
 	// Создаем команду для пользователя
@@ -53,7 +54,7 @@ func TestUserStorage_GetByUserID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 
 	// Создаем команду и пользователя
@@ -90,7 +91,7 @@ func TestUserStorage_GetByTeamID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 
 	// Создаем команду
@@ -122,7 +123,7 @@ func TestUserStorage_SetIsActive(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 
 	// Создаем команду и пользователя
@@ -162,13 +163,30 @@ func TestUserStorage_SetIsActive(t *testing.T) {
 		err := userStorage.SetIsActive(ctx, "non-existent", false)
 		assert.Error(t, err)
 	})
+
+	t.Run("activity log records only changes", func(t *testing.T) {
+		err := userStorage.SetIsActive(ctx, "u1", true)
+		require.NoError(t, err)
+
+		var states []bool
+		rows, err := storage.DB.Query(ctx,
+			`SELECT is_active FROM pr_system.user_activity_log WHERE user_id = $1 ORDER BY id`, user.ID)
+		require.NoError(t, err)
+		defer rows.Close()
+		for rows.Next() {
+			var isActive bool
+			require.NoError(t, rows.Scan(&isActive))
+			states = append(states, isActive)
+		}
+		assert.Equal(t, []bool{true, false, true}, states)
+	})
 }
 
 func TestUserStorage_Update(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 
 	// Создаем команду и пользователя
@@ -196,6 +214,7 @@ func TestUserStorage_Update(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "Alice Updated", found.Username)
 		assert.False(t, found.IsActive)
+		assert.Equal(t, 2, found.Version)
 	})
 }
 
@@ -203,7 +222,7 @@ func TestUserStorage_DeactivateByTeamID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 
 	// Создаем команду и пользователей
@@ -238,7 +257,7 @@ func TestUserStorage_GetByReviewerID(t *testing.T) {
 	storage, teardown := setupTestDB(t)
 	defer teardown()
 
-	ctx := context.Background()
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
 	userStorage := NewUserStorage(storage)
 	prRepo := NewPRRepo(storage)
 
@@ -266,14 +285,21 @@ func TestUserStorage_GetByReviewerID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Назначаем ревьювера
-	err = prRepo.AddReviewer(ctx, pr.ID, reviewer.ID)
+	err = prRepo.AddReviewer(ctx, pr.ID, reviewer.ID, domain.AssignReasonAuto)
 	require.NoError(t, err)
 
 	t.Run("get PRs by reviewer", func(t *testing.T) {
-		prs, err := userStorage.GetByReviewerID(ctx, "u2")
+		prs, err := userStorage.GetByReviewerID(ctx, "u2", domain.PRFilter{})
 		require.NoError(t, err)
 		assert.Len(t, prs, 1)
 		assert.Equal(t, "pr-1", prs[0].PullRequestID)
+		assert.Equal(t, "u1", prs[0].Author.UserID)
+	})
+
+	t.Run("filters by status", func(t *testing.T) {
+		prs, err := userStorage.GetByReviewerID(ctx, "u2", domain.PRFilter{StatusID: 2})
+		require.NoError(t, err)
+		assert.Empty(t, prs)
 	})
 }
 
@@ -0,0 +1,83 @@
+package postgresql
+
+import (
+	"context"
+	"reviewer-appointment-service/internal/models/domain"
+	"reviewer-appointment-service/internal/storage"
+	"reviewer-appointment-service/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionConflicts(t *testing.T) {
+	s, teardown := setupTestDB(t)
+	defer teardown()
+
+	ctx := tenant.WithOrg(context.Background(), tenant.DefaultOrgID)
+	teams := NewTeamRepo(s)
+	users := NewUserStorage(s)
+	prs := NewPRRepo(s)
+
+	team, author, _, pr := seedOrg(t, s, ctx, "u1", "u2", "pr-1")
+	require.Equal(t, 1, team.Version)
+	require.Equal(t, 1, author.Version)
+
+	t.Run("pull request", func(t *testing.T) {
+		found, err := prs.GetByPRID(ctx, pr.PullRequestID)
+		require.NoError(t, err)
+		require.Equal(t, 1, found.Version)
+
+		now := time.Now()
+		merged := *found
+		merged.StatusID, merged.MergedAt = 2, &now
+		require.NoError(t, prs.Update(ctx, &merged))
+		assert.Equal(t, 2, merged.Version)
+
+		// Вторая запись с прочитанной ранее версией
+		stale := *found
+		stale.PullRequestName = "stale"
+		assert.ErrorIs(t, prs.Update(ctx, &stale), storage.ErrVersionConflict)
+		assert.ErrorIs(t, prs.BumpVersion(ctx, pr.ID, 1), storage.ErrVersionConflict)
+		require.NoError(t, prs.BumpVersion(ctx, pr.ID, 2))
+
+		missing := domain.PullRequest{PullRequestID: "pr-404", Version: 1}
+		assert.ErrorIs(t, prs.Update(ctx, &missing), storage.ErrNotFound)
+
+		found, err = prs.GetByPRID(ctx, pr.PullRequestID)
+		require.NoError(t, err)
+		assert.Equal(t, pr.PullRequestName, found.PullRequestName)
+		assert.Equal(t, 3, found.Version)
+	})
+
+	t.Run("user", func(t *testing.T) {
+		updated := *author
+		updated.Username = "Author"
+		require.NoError(t, users.Update(ctx, &updated))
+		assert.Equal(t, 2, updated.Version)
+
+		stale := *author
+		stale.Username = "stale"
+		assert.ErrorIs(t, users.Update(ctx, &stale), storage.ErrVersionConflict)
+
+		missing := domain.User{UserID: "ghost", TeamID: team.ID, Version: 1}
+		assert.ErrorIs(t, users.Update(ctx, &missing), storage.ErrNotFound)
+
+		require.NoError(t, users.SetIsActive(ctx, author.UserID, false))
+		found, err := users.GetByUserID(ctx, author.UserID)
+		require.NoError(t, err)
+		assert.Equal(t, "Author", found.Username)
+		assert.Equal(t, 3, found.Version)
+	})
+
+	t.Run("team", func(t *testing.T) {
+		require.NoError(t, teams.SetBuddyTeams(ctx, team.ID, 1, nil))
+		assert.ErrorIs(t, teams.SetBuddyTeams(ctx, team.ID, 1, nil), storage.ErrVersionConflict)
+
+		found, err := teams.GetByID(ctx, team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, found.Version)
+	})
+}
@@ -15,6 +15,35 @@ var (
 	ErrNoCandidate = errors.New("no active replacement candidate")
 	ErrNotFound    = errors.New("resource not found")
 	ErrUserExists  = errors.New("user already exists")
+	ErrOrgExists   = errors.New("organization already exists")
+
+	ErrVersionConflict = errors.New("resource was modified concurrently")
+
+	ErrReviewerInactive = errors.New("reviewer is not active")
+	ErrReviewerIsAuthor = errors.New("reviewer is the PR author")
+	ErrAlreadyAssigned  = errors.New("reviewer already assigned")
+	ErrReviewerLimit    = errors.New("reviewer limit reached")
+
+	ErrInvalidReviewerBounds = errors.New("invalid reviewer bounds")
+	ErrInvalidBuddyTeam      = errors.New("invalid buddy team")
+	ErrInvalidRole           = errors.New("invalid user role")
+	ErrInvalidReviewSLA      = errors.New("invalid review SLA")
+	ErrInvalidScope          = errors.New("invalid token scope")
+	ErrInvalidTokenExpiry    = errors.New("invalid token expiry")
+	ErrInvalidOrgSlug        = errors.New("invalid organization slug")
+	ErrTooManyMembers        = errors.New("too many team members")
+	ErrTooManyPRs            = errors.New("too many pull requests in batch")
+	ErrInvalidSyncDocument   = errors.New("invalid team sync document")
+
+	ErrUnauthorized = errors.New("missing or invalid API token")
+	ErrForbidden    = errors.New("caller is not allowed to perform this action")
+
+	ErrRateLimited  = errors.New("rate limit exceeded")
+	ErrBodyTooLarge = errors.New("request body too large")
+
+	ErrInvalidIdempotencyKey  = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyKeyInUse    = errors.New("request with this idempotency key is in progress")
 )
 
 func GetDBConnectionString(cfg *config.Config) string {
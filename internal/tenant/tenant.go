@@ -0,0 +1,34 @@
+// Package tenant передает организацию (арендатора) текущего запроса через
+// context. Все репозитории читают и пишут данные только этой организации.
+package tenant
+
+import "context"
+
+// DefaultOrgID - организация по умолчанию, созданная миграцией; к ней
+// относятся данные, существовавшие до появления организаций
+const DefaultOrgID int64 = 1
+
+// DefaultSlug - slug организации по умолчанию
+const DefaultSlug = "default"
+
+// Header - заголовок, которым клиент выбирает организацию по ее slug
+const Header = "X-Org"
+
+type ctxKey struct{}
+
+// WithOrg возвращает контекст, привязанный к организации orgID
+func WithOrg(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, ctxKey{}, orgID)
+}
+
+// OrgID возвращает организацию контекста. Контекст без организации - ошибка
+// вызывающего кода: молча подставить DefaultOrgID значило бы читать и
+// писать чужие данные, поэтому OrgID паникует. Вызывающий код задает
+// организацию явно через WithOrg, в том числе DefaultOrgID.
+func OrgID(ctx context.Context) int64 {
+	id, ok := ctx.Value(ctxKey{}).(int64)
+	if !ok {
+		panic("tenant: organization is not set in context")
+	}
+	return id
+}
@@ -0,0 +1,32 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"reviewer-appointment-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("none", func(t *testing.T) {
+		shutdown, err := Setup(ctx, config.Tracing{Exporter: ExporterNone})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(ctx))
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		shutdown, err := Setup(ctx, config.Tracing{Exporter: ExporterStdout, ServiceName: "test", SampleRatio: 1})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(ctx))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Setup(ctx, config.Tracing{Exporter: "jaeger"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,7 @@
+// Package version хранит версию сборки. Значение задается при сборке:
+//
+//	go build -ldflags "-X reviewer-appointment-service/internal/version.Version=v1.2.3"
+package version
+
+// Version - версия сервиса; без ldflags - "dev"
+var Version = "dev"
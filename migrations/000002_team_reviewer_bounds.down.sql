@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS pr_system.teams DROP CONSTRAINT IF EXISTS chk_teams_reviewer_bounds;
+ALTER TABLE IF EXISTS pr_system.teams DROP COLUMN IF EXISTS max_reviewers;
+ALTER TABLE IF EXISTS pr_system.teams DROP COLUMN IF EXISTS min_reviewers;
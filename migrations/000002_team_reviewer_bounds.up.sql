@@ -0,0 +1,6 @@
+ALTER TABLE pr_system.teams
+    ADD COLUMN IF NOT EXISTS min_reviewers INTEGER DEFAULT 1 NOT NULL,
+    ADD COLUMN IF NOT EXISTS max_reviewers INTEGER DEFAULT 2 NOT NULL;
+
+ALTER TABLE pr_system.teams
+    ADD CONSTRAINT chk_teams_reviewer_bounds CHECK (min_reviewers >= 0 AND max_reviewers >= min_reviewers);
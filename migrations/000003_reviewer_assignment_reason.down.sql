@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS pr_system.pr_reviewers DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,2 @@
+ALTER TABLE pr_system.pr_reviewers
+    ADD COLUMN IF NOT EXISTS reason VARCHAR(32) DEFAULT 'AUTO' NOT NULL;
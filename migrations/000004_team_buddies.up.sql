@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS pr_system.team_buddies (
+    team_id BIGINT NOT NULL REFERENCES pr_system.teams(id) ON DELETE CASCADE,
+    buddy_team_id BIGINT NOT NULL REFERENCES pr_system.teams(id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL,
+    PRIMARY KEY (team_id, buddy_team_id),
+    CHECK (team_id <> buddy_team_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_buddies_team_priority ON pr_system.team_buddies(team_id, priority);
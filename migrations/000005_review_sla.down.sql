@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS pr_system.idx_pr_reviewers_assigned_at;
+DROP TABLE IF EXISTS pr_system.review_events;
+ALTER TABLE IF EXISTS pr_system.teams DROP CONSTRAINT IF EXISTS chk_teams_review_sla;
+ALTER TABLE IF EXISTS pr_system.teams DROP COLUMN IF EXISTS escalation_after_minutes;
+ALTER TABLE IF EXISTS pr_system.teams DROP COLUMN IF EXISTS reminder_after_minutes;
+ALTER TABLE IF EXISTS pr_system.users DROP CONSTRAINT IF EXISTS chk_users_role;
+ALTER TABLE IF EXISTS pr_system.users DROP COLUMN IF EXISTS role;
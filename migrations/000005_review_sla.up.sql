@@ -0,0 +1,26 @@
+ALTER TABLE pr_system.users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) DEFAULT 'member' NOT NULL;
+
+ALTER TABLE pr_system.users
+    ADD CONSTRAINT chk_users_role CHECK (role IN ('member', 'lead'));
+
+-- 0 означает значение по умолчанию из конфигурации сервиса
+ALTER TABLE pr_system.teams
+    ADD COLUMN IF NOT EXISTS reminder_after_minutes INTEGER DEFAULT 0 NOT NULL,
+    ADD COLUMN IF NOT EXISTS escalation_after_minutes INTEGER DEFAULT 0 NOT NULL;
+
+ALTER TABLE pr_system.teams
+    ADD CONSTRAINT chk_teams_review_sla CHECK (reminder_after_minutes >= 0 AND escalation_after_minutes >= 0);
+
+CREATE TABLE IF NOT EXISTS pr_system.review_events (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    pr_id BIGINT NOT NULL REFERENCES pr_system.pull_requests(id) ON DELETE CASCADE,
+    reviewer_id BIGINT NOT NULL REFERENCES pr_system.users(id),
+    kind VARCHAR(32) NOT NULL,
+    action VARCHAR(32) NOT NULL DEFAULT '',
+    target_id BIGINT REFERENCES pr_system.users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (pr_id, reviewer_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_assigned_at ON pr_system.pr_reviewers(assigned_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS pr_system.idx_pr_reviewers_reviewed_at;
+DROP INDEX IF EXISTS pr_system.idx_pull_requests_created_at;
+ALTER TABLE IF EXISTS pr_system.pr_reviewers DROP COLUMN IF EXISTS reviewed_at;
@@ -0,0 +1,6 @@
+ALTER TABLE pr_system.pr_reviewers
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created_at ON pr_system.pull_requests(created_at);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_reviewed_at ON pr_system.pr_reviewers(pr_id, reviewed_at)
+    WHERE reviewed_at IS NOT NULL;
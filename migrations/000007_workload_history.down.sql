@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS pr_system.idx_pr_reviewers_assigned_at_reviewer;
+DROP TABLE IF EXISTS pr_system.user_activity_log;
+DROP TABLE IF EXISTS pr_system.pr_reviewer_history;
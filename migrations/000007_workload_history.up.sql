@@ -0,0 +1,28 @@
+-- Снятые и переназначенные ревьюверы: pr_reviewers хранит только текущие назначения
+CREATE TABLE IF NOT EXISTS pr_system.pr_reviewer_history (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    pr_id BIGINT NOT NULL REFERENCES pr_system.pull_requests(id) ON DELETE CASCADE,
+    reviewer_id BIGINT NOT NULL REFERENCES pr_system.users(id),
+    reason VARCHAR(32) NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL,
+    reviewed_at TIMESTAMPTZ,
+    removed_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewer_history_assigned_at ON pr_system.pr_reviewer_history(assigned_at);
+
+-- Изменения флага активности пользователей (отпуска, увольнения)
+CREATE TABLE IF NOT EXISTS pr_system.user_activity_log (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES pr_system.users(id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL,
+    changed_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_activity_log_user_changed ON pr_system.user_activity_log(user_id, changed_at);
+
+-- Текущее состояние существующих пользователей считается действующим с момента их создания
+INSERT INTO pr_system.user_activity_log (user_id, is_active, changed_at)
+SELECT id, is_active, COALESCE(created_at, NOW()) FROM pr_system.users;
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_assigned_at_reviewer ON pr_system.pr_reviewers(assigned_at, reviewer_id);
@@ -0,0 +1,10 @@
+-- Версия схемы для проверки готовности сервиса. Каждая следующая миграция
+-- добавляет сюда свой номер, а down-миграция удаляет его.
+CREATE TABLE IF NOT EXISTS pr_system.schema_migrations (
+    version BIGINT PRIMARY KEY,
+    applied_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+INSERT INTO pr_system.schema_migrations (version)
+SELECT generate_series(1, 8)
+ON CONFLICT (version) DO NOTHING;
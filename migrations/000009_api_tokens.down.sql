@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pr_system.api_tokens;
+DELETE FROM pr_system.schema_migrations WHERE version = 9;
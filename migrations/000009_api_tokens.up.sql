@@ -0,0 +1,16 @@
+-- Токены доступа к API. Хранится только SHA-256 хеш токена, сам токен
+-- показывается один раз при создании.
+CREATE TABLE IF NOT EXISTS pr_system.api_tokens (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(16) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (9)
+ON CONFLICT (version) DO NOTHING;
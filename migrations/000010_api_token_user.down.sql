@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS pr_system.api_tokens DROP COLUMN IF EXISTS user_id;
+DELETE FROM pr_system.schema_migrations WHERE version = 10;
@@ -0,0 +1,7 @@
+-- Токен может принадлежать пользователю: тогда запросы с ним выполняются
+-- от имени этого пользователя и проверяются по его роли в команде
+ALTER TABLE pr_system.api_tokens
+    ADD COLUMN IF NOT EXISTS user_id BIGINT REFERENCES pr_system.users(id) ON DELETE CASCADE;
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (10)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,27 @@
+ALTER TABLE IF EXISTS pr_system.api_tokens DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.user_activity_log DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.pr_reviewer_history DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.review_events DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.team_buddies DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.pr_reviewers DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.pull_requests DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.users DROP COLUMN IF EXISTS org_id CASCADE;
+ALTER TABLE IF EXISTS pr_system.teams DROP COLUMN IF EXISTS org_id CASCADE;
+DROP TABLE IF EXISTS pr_system.organizations;
+
+-- Возвращаем глобальную уникальность (ограничения могут уже существовать,
+-- если up-миграция не применялась)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'teams_name_key') THEN
+        ALTER TABLE pr_system.teams ADD CONSTRAINT teams_name_key UNIQUE (name);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_user_id_key') THEN
+        ALTER TABLE pr_system.users ADD CONSTRAINT users_user_id_key UNIQUE (user_id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'pull_requests_pull_request_id_key') THEN
+        ALTER TABLE pr_system.pull_requests ADD CONSTRAINT pull_requests_pull_request_id_key UNIQUE (pull_request_id);
+    END IF;
+END $$;
+
+DELETE FROM pr_system.schema_migrations WHERE version = 11;
@@ -0,0 +1,98 @@
+-- Организации (арендаторы). Команды, пользователи, PR и все связанные с ними
+-- данные принадлежат одной организации; имена команд, user_id и
+-- pull_request_id уникальны только внутри нее.
+CREATE TABLE IF NOT EXISTS pr_system.organizations (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+
+INSERT INTO pr_system.organizations (id, slug, name) VALUES (1, 'default', 'Default')
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('pr_system.organizations', 'id'), (SELECT MAX(id) FROM pr_system.organizations));
+
+-- Существующие данные относятся к организации по умолчанию. Значение по
+-- умолчанию затем снимается: запрос, забывший org_id, должен падать, а не
+-- записывать данные в чужую организацию.
+ALTER TABLE pr_system.teams ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.users ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.pull_requests ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.pr_reviewers ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.team_buddies ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.review_events ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.pr_reviewer_history ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.user_activity_log ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+ALTER TABLE pr_system.api_tokens ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES pr_system.organizations(id);
+
+ALTER TABLE pr_system.teams ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.users ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.pull_requests ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.pr_reviewers ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.team_buddies ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.review_events ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.pr_reviewer_history ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.user_activity_log ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_system.api_tokens ALTER COLUMN org_id DROP DEFAULT;
+
+-- Бизнес-идентификаторы уникальны внутри организации
+ALTER TABLE pr_system.teams DROP CONSTRAINT IF EXISTS teams_name_key;
+ALTER TABLE pr_system.users DROP CONSTRAINT IF EXISTS users_user_id_key;
+ALTER TABLE pr_system.pull_requests DROP CONSTRAINT IF EXISTS pull_requests_pull_request_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS teams_org_name_key ON pr_system.teams(org_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS users_org_user_id_key ON pr_system.users(org_id, user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS pull_requests_org_pull_request_id_key ON pr_system.pull_requests(org_id, pull_request_id);
+
+-- Составные ключи (org_id, id) позволяют ссылаться только на строки той же
+-- организации: назначить ревьювером пользователя другой организации, взять
+-- ее команду в партнеры и т.п. не даст сама БД
+CREATE UNIQUE INDEX IF NOT EXISTS teams_org_id_key ON pr_system.teams(org_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS users_org_id_key ON pr_system.users(org_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS pull_requests_org_id_key ON pr_system.pull_requests(org_id, id);
+
+ALTER TABLE pr_system.users
+    DROP CONSTRAINT IF EXISTS users_org_team_fkey,
+    ADD CONSTRAINT users_org_team_fkey FOREIGN KEY (org_id, team_id) REFERENCES pr_system.teams(org_id, id);
+
+ALTER TABLE pr_system.pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_org_author_fkey,
+    ADD CONSTRAINT pull_requests_org_author_fkey FOREIGN KEY (org_id, author_id) REFERENCES pr_system.users(org_id, id);
+
+ALTER TABLE pr_system.pr_reviewers
+    DROP CONSTRAINT IF EXISTS pr_reviewers_org_pr_fkey,
+    ADD CONSTRAINT pr_reviewers_org_pr_fkey FOREIGN KEY (org_id, pr_id) REFERENCES pr_system.pull_requests(org_id, id) ON DELETE CASCADE,
+    DROP CONSTRAINT IF EXISTS pr_reviewers_org_reviewer_fkey,
+    ADD CONSTRAINT pr_reviewers_org_reviewer_fkey FOREIGN KEY (org_id, reviewer_id) REFERENCES pr_system.users(org_id, id);
+
+ALTER TABLE pr_system.team_buddies
+    DROP CONSTRAINT IF EXISTS team_buddies_org_team_fkey,
+    ADD CONSTRAINT team_buddies_org_team_fkey FOREIGN KEY (org_id, team_id) REFERENCES pr_system.teams(org_id, id) ON DELETE CASCADE,
+    DROP CONSTRAINT IF EXISTS team_buddies_org_buddy_fkey,
+    ADD CONSTRAINT team_buddies_org_buddy_fkey FOREIGN KEY (org_id, buddy_team_id) REFERENCES pr_system.teams(org_id, id) ON DELETE CASCADE;
+
+ALTER TABLE pr_system.review_events
+    DROP CONSTRAINT IF EXISTS review_events_org_pr_fkey,
+    ADD CONSTRAINT review_events_org_pr_fkey FOREIGN KEY (org_id, pr_id) REFERENCES pr_system.pull_requests(org_id, id) ON DELETE CASCADE,
+    DROP CONSTRAINT IF EXISTS review_events_org_reviewer_fkey,
+    ADD CONSTRAINT review_events_org_reviewer_fkey FOREIGN KEY (org_id, reviewer_id) REFERENCES pr_system.users(org_id, id),
+    DROP CONSTRAINT IF EXISTS review_events_org_target_fkey,
+    ADD CONSTRAINT review_events_org_target_fkey FOREIGN KEY (org_id, target_id) REFERENCES pr_system.users(org_id, id);
+
+ALTER TABLE pr_system.pr_reviewer_history
+    DROP CONSTRAINT IF EXISTS pr_reviewer_history_org_pr_fkey,
+    ADD CONSTRAINT pr_reviewer_history_org_pr_fkey FOREIGN KEY (org_id, pr_id) REFERENCES pr_system.pull_requests(org_id, id) ON DELETE CASCADE,
+    DROP CONSTRAINT IF EXISTS pr_reviewer_history_org_reviewer_fkey,
+    ADD CONSTRAINT pr_reviewer_history_org_reviewer_fkey FOREIGN KEY (org_id, reviewer_id) REFERENCES pr_system.users(org_id, id);
+
+ALTER TABLE pr_system.user_activity_log
+    DROP CONSTRAINT IF EXISTS user_activity_log_org_user_fkey,
+    ADD CONSTRAINT user_activity_log_org_user_fkey FOREIGN KEY (org_id, user_id) REFERENCES pr_system.users(org_id, id) ON DELETE CASCADE;
+
+ALTER TABLE pr_system.api_tokens
+    DROP CONSTRAINT IF EXISTS api_tokens_org_user_fkey,
+    ADD CONSTRAINT api_tokens_org_user_fkey FOREIGN KEY (org_id, user_id) REFERENCES pr_system.users(org_id, id) ON DELETE CASCADE;
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (11)
+ON CONFLICT (version) DO NOTHING;
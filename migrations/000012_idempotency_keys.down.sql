@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pr_system.idempotency_keys;
+DELETE FROM pr_system.schema_migrations WHERE version = 12;
@@ -0,0 +1,21 @@
+-- Ответы на запросы с заголовком Idempotency-Key. Ключ уникален в пределах
+-- организации и клиента (токена API или IP-адреса). Пока запрос
+-- выполняется, status_code пустой, а expires_at ограничивает время
+-- резервирования ключа на случай падения экземпляра.
+CREATE TABLE IF NOT EXISTS pr_system.idempotency_keys (
+    org_id BIGINT NOT NULL REFERENCES pr_system.organizations(id) ON DELETE CASCADE,
+    client VARCHAR(128) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (org_id, client, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON pr_system.idempotency_keys(expires_at);
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (12)
+ON CONFLICT (version) DO NOTHING;
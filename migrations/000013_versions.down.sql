@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS pr_system.users DROP COLUMN IF EXISTS version;
+ALTER TABLE IF EXISTS pr_system.teams DROP COLUMN IF EXISTS version;
+ALTER TABLE IF EXISTS pr_system.pull_requests DROP COLUMN IF EXISTS version;
+
+DELETE FROM pr_system.schema_migrations WHERE version = 13;
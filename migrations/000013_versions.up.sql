@@ -0,0 +1,8 @@
+-- Версии для оптимистичной блокировки: каждое изменение увеличивает
+-- version, а запись с устаревшей версией отклоняется
+ALTER TABLE pr_system.pull_requests ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1 NOT NULL;
+ALTER TABLE pr_system.teams ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1 NOT NULL;
+ALTER TABLE pr_system.users ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1 NOT NULL;
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (13)
+ON CONFLICT (version) DO NOTHING;
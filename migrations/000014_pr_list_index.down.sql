@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS pr_system.idx_pull_requests_org_created;
+
+DELETE FROM pr_system.schema_migrations WHERE version = 14;
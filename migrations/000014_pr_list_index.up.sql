@@ -0,0 +1,6 @@
+-- Индекс для постраничного списка PR: фильтр по организации и порядок
+-- (created_at, id), по которому продолжается страница
+CREATE INDEX IF NOT EXISTS idx_pull_requests_org_created ON pr_system.pull_requests(org_id, created_at, id);
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (14)
+ON CONFLICT (version) DO NOTHING;
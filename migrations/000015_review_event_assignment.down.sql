@@ -0,0 +1,16 @@
+-- Из событий одного вида по разным назначениям остается самое раннее
+DELETE FROM pr_system.review_events e
+USING pr_system.review_events older
+WHERE older.pr_id = e.pr_id AND older.reviewer_id = e.reviewer_id AND older.kind = e.kind AND older.id < e.id;
+
+-- На пустой базе (docker-entrypoint-initdb.d выполняет down перед up)
+-- ограничение из 000005 уже есть, поэтому оно пересоздается
+ALTER TABLE IF EXISTS pr_system.review_events
+    DROP CONSTRAINT IF EXISTS review_events_assignment_kind_key,
+    DROP CONSTRAINT IF EXISTS review_events_pr_id_reviewer_id_kind_key,
+    DROP COLUMN IF EXISTS assigned_at;
+
+ALTER TABLE IF EXISTS pr_system.review_events
+    ADD CONSTRAINT review_events_pr_id_reviewer_id_kind_key UNIQUE (pr_id, reviewer_id, kind);
+
+DELETE FROM pr_system.schema_migrations WHERE version = 15;
@@ -0,0 +1,20 @@
+-- События SLA относятся к назначению, а не к паре PR и ревьювера: после
+-- снятия и повторного назначения ревьювер снова получает напоминание
+ALTER TABLE pr_system.review_events ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ;
+
+-- События текущих назначений привязываются к ним; события более ранних
+-- назначений, от которых не осталось времени назначения, - к моменту события
+UPDATE pr_system.review_events e
+SET assigned_at = prr.assigned_at
+FROM pr_system.pr_reviewers prr
+WHERE prr.pr_id = e.pr_id AND prr.reviewer_id = e.reviewer_id AND prr.assigned_at <= e.created_at;
+
+UPDATE pr_system.review_events SET assigned_at = created_at WHERE assigned_at IS NULL;
+
+ALTER TABLE pr_system.review_events
+    ALTER COLUMN assigned_at SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS review_events_pr_id_reviewer_id_kind_key,
+    ADD CONSTRAINT review_events_assignment_kind_key UNIQUE (pr_id, reviewer_id, assigned_at, kind);
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (15)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS pr_system.idx_pr_reviewer_history_reviewed_at;
+
+DELETE FROM pr_system.schema_migrations WHERE version = 16;
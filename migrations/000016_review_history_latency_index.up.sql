@@ -0,0 +1,7 @@
+-- Индекс для задержек ревью: первое ревью PR ищется и среди снятых и
+-- переназначенных ревьюверов
+CREATE INDEX IF NOT EXISTS idx_pr_reviewer_history_reviewed_at ON pr_system.pr_reviewer_history(pr_id, reviewed_at)
+    WHERE reviewed_at IS NOT NULL;
+
+INSERT INTO pr_system.schema_migrations (version) VALUES (16)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,38 @@
+// Package migrations встраивает SQL-миграции в бинарный файл, чтобы сервис
+// знал, какую версию схемы он ожидает.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion возвращает номер последней миграции (префикс NNNNNN_ в имени
+// файла)
+func LatestVersion() (int64, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, name := range files {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("migrations: unexpected file name %q", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migrations: unexpected file name %q: %w", name, err)
+		}
+		latest = max(latest, version)
+	}
+
+	return latest, nil
+}
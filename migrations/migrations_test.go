@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	version, err := LatestVersion()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, version, int64(8))
+}
+
+// Каждая up-миграция, начиная с 000008, должна записывать свою версию в
+// schema_migrations, иначе /health/ready не станет готовым
+func TestMigrationsRecordVersion(t *testing.T) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+
+	for _, name := range files {
+		if name < "000008" {
+			continue
+		}
+		body, err := fs.ReadFile(FS, name)
+		require.NoError(t, err)
+		assert.True(t, strings.Contains(string(body), "pr_system.schema_migrations"), name)
+	}
+}
@@ -0,0 +1,322 @@
+#!/usr/bin/env python3
+# Generates api/openapi.json, the OpenAPI 3 spec served at /openapi.json.
+# Edit this script instead of the JSON and run: make openapi
+# The contract tests in internal/contract_test.go check that the spec lists
+# exactly the registered routes and matches real responses.
+
+import json
+import os
+from collections import OrderedDict as O
+
+def ref(name): return {"$ref": "#/components/schemas/" + name}
+def arr(s): return {"type": "array", "items": s}
+S = {"type": "string"}
+I = {"type": "integer"}
+I64 = {"type": "integer", "format": "int64"}
+B = {"type": "boolean"}
+N = {"type": "number", "format": "double"}
+DT = {"type": "string", "format": "date-time"}
+def nullable(s): d = dict(s); d["nullable"] = True; return d
+def obj(props, required=None, desc=None):
+    o = {"type": "object"}
+    if desc: o["description"] = desc
+    if required: o["required"] = required
+    o["properties"] = props
+    return o
+def mapof(s): return {"type": "object", "additionalProperties": s}
+
+schemas = O()
+schemas["Error"] = obj({"code": {"type": "string", "description": "Код ошибки, например NOT_FOUND или PR_MERGED"}, "message": S}, ["code", "message"])
+schemas["ErrorResponse"] = obj({"error": ref("Error")}, ["error"], "Конверт ответа с ошибкой")
+schemas["User"] = obj({
+    "id": I64, "user_id": S, "username": S, "is_active": B, "team_id": I64,
+    "role": {"type": "string", "enum": ["member", "lead"]},
+    "version": {"type": "integer", "description": "Версия пользователя для If-Match"},
+    "created_at": DT}, ["id", "user_id", "username", "is_active", "team_id", "created_at"])
+schemas["TeamMemberInput"] = obj({
+    "user_id": S, "username": S, "is_active": B,
+    "role": {"type": "string", "enum": ["member", "lead"], "description": "По умолчанию member"}}, ["user_id", "username"])
+schemas["TeamInput"] = obj({
+    "name": S,
+    "min_reviewers": {"type": "integer", "minimum": 0, "description": "По умолчанию 1"},
+    "max_reviewers": {"type": "integer", "minimum": 0, "description": "По умолчанию 2"},
+    "reminder_after_minutes": {"type": "integer", "minimum": 0, "description": "0 - SLA по умолчанию"},
+    "escalation_after_minutes": {"type": "integer", "minimum": 0, "description": "0 - SLA по умолчанию"},
+    "buddy_teams": arr(S),
+    "users": arr(ref("TeamMemberInput"))}, ["name"])
+schemas["Team"] = obj({
+    "id": I64, "name": S, "min_reviewers": I, "max_reviewers": I,
+    "reminder_after_minutes": I, "escalation_after_minutes": I,
+    "buddy_teams": {"type": "array", "items": S, "description": "Команды-партнеры в порядке приоритета"},
+    "version": {"type": "integer", "description": "Версия команды для If-Match"},
+    "created_at": DT, "users": arr(ref("User"))},
+    ["id", "name", "min_reviewers", "max_reviewers", "reminder_after_minutes", "escalation_after_minutes", "version", "created_at"])
+schemas["ReviewerAssignment"] = obj({
+    "user_id": S, "username": S, "team_id": I64,
+    "reason": {"type": "string", "enum": ["AUTO", "MANUAL", "BACKFILL", "REASSIGN", "REQUESTED", "PREFERRED", "BORROWED", "ESCALATED"]},
+    "borrowed": B, "assigned_at": DT, "reviewed_at": DT}, ["user_id", "username", "team_id", "reason", "borrowed", "assigned_at"])
+schemas["PullRequest"] = obj({
+    "id": I64, "pull_request_id": S, "pull_request_name": S, "author_id": I64,
+    "status_id": {"type": "integer", "enum": [1, 2], "description": "1 - OPEN, 2 - MERGED"},
+    "merged_at": nullable(DT),
+    "version": {"type": "integer", "description": "Версия PR для If-Match"},
+    "created_at": DT, "author": ref("User"), "reviewers": arr(ref("User")),
+    "assignments": arr(ref("ReviewerAssignment"))},
+    ["id", "pull_request_id", "pull_request_name", "author_id", "status_id", "merged_at", "version", "created_at"])
+schemas["CreatePRRequest"] = obj({"pull_request_id": S, "pull_request_name": S, "author_id": S}, ["pull_request_id", "pull_request_name", "author_id"])
+schemas["MergePRRequest"] = obj({"pull_request_id": S}, ["pull_request_id"])
+reassignOpts = {"new_reviewer_id": {"type": "string", "description": "Конкретная замена"},
+    "exclude": {"type": "array", "items": S, "description": "Исключить из кандидатов"},
+    "prefer": {"type": "array", "items": S, "description": "Предпочесть среди кандидатов"},
+    "team_name": {"type": "string", "description": "Команда, из которой выбирается замена"}}
+schemas["ReassignReviewerRequest"] = obj(dict(O([("pull_request_id", S), ("old_reviewer_id", S)]), **reassignOpts), ["pull_request_id", "old_reviewer_id"])
+schemas["ReviewerRequest"] = obj({"pull_request_id": S, "reviewer_id": S}, ["pull_request_id", "reviewer_id"])
+schemas["SetBuddyTeamsRequest"] = obj({"team_name": S, "buddy_teams": arr(S)}, ["team_name"])
+schemas["SetIsActiveRequest"] = obj({"user_id": S, "is_active": B}, ["user_id", "is_active"])
+schemas["V2UpdateTeamRequest"] = obj({"buddy_teams": arr(S)}, ["buddy_teams"])
+schemas["V2UpdateUserRequest"] = obj({"is_active": B}, ["is_active"])
+schemas["V2AddReviewerRequest"] = obj({"reviewer_id": S}, ["reviewer_id"])
+schemas["V2ReassignRequest"] = obj(reassignOpts)
+schemas["V2ReassignResponse"] = obj({"replaced_by": S, "reviewers": arr(ref("ReviewerAssignment"))}, ["replaced_by", "reviewers"])
+schemas["PRResponse"] = obj({"pr": ref("PullRequest")}, ["pr"])
+schemas["BatchCreatePRRequest"] = obj({
+    "pull_requests": {"type": "array", "minItems": 1, "items": ref("CreatePRRequest"), "description": "Не больше limits.max_batch_prs PR"},
+    "atomic": {"type": "boolean", "description": "Создать PR, только если ни у одного нет ошибки"}}, ["pull_requests"])
+schemas["BatchCreatePRResult"] = obj({
+    "pull_request_id": S,
+    "status": {"type": "string", "enum": ["CREATED", "FAILED", "SKIPPED"], "description": "SKIPPED - PR без ошибки из atomic-пакета, который не создан из-за другого PR"},
+    "pr": ref("PullRequest"),
+    "error": ref("Error")}, ["pull_request_id", "status"])
+schemas["BatchCreatePRResponse"] = obj({"created": I, "failed": I, "results": arr(ref("BatchCreatePRResult"))}, ["created", "failed", "results"], "Итоги в порядке PR запроса")
+schemas["TeamResponse"] = obj({"team": ref("Team")}, ["team"])
+schemas["UserResponse"] = obj({"user": ref("User")}, ["user"])
+schemas["ReassignReviewerResponse"] = obj({"pr": ref("PullRequest"), "replaced_by": S, "reviewers": arr(ref("ReviewerAssignment"))}, ["pr", "replaced_by", "reviewers"])
+schemas["PRPage"] = obj({"user_id": {"type": "string", "description": "Только в /users/getReview"}, "pull_requests": arr(ref("PullRequest")),
+    "next_cursor": {"type": "string", "description": "Курсор следующей страницы; нет на последней"}}, ["pull_requests"])
+schemas["PageMeta"] = obj({"next_cursor": {"type": "string", "description": "Курсор следующей страницы; нет на последней"}})
+schemas["ReviewerStats"] = obj({"user_id": S, "username": S, "review_count": I}, ["user_id", "username", "review_count"])
+schemas["TeamStats"] = obj({"team_name": S, "open_prs": I, "merged_prs": I, "active_members": I, "inactive_members": I,
+    "reviews_per_member": arr(ref("ReviewerStats"))}, ["team_name", "open_prs", "merged_prs", "active_members", "inactive_members", "reviews_per_member"])
+schemas["Stats"] = obj({"total_prs": I, "total_users": I, "prs_by_status": mapof(I), "active_users": I,
+    "top_reviewers": arr(ref("ReviewerStats")), "borrowed_reviews": mapof(I), "teams": arr(ref("TeamStats"))},
+    ["total_prs", "total_users", "prs_by_status", "active_users", "top_reviewers", "borrowed_reviews", "teams"])
+schemas["ReviewPairStats"] = obj({"author_id": S, "author_username": S, "reviewer_id": S, "reviewer_username": S, "review_count": I},
+    ["author_id", "author_username", "reviewer_id", "reviewer_username", "review_count"])
+schemas["ReviewPairs"] = obj({"since": DT, "pairs": arr(ref("ReviewPairStats"))}, ["since", "pairs"])
+schemas["Latency"] = obj({"count": I, "median_seconds": nullable(N), "p90_seconds": nullable(N)}, ["count", "median_seconds", "p90_seconds"])
+schemas["TeamLatency"] = obj({"team_name": S, "time_to_first_review": ref("Latency"), "time_to_merge": ref("Latency")}, ["time_to_first_review", "time_to_merge"])
+schemas["ReviewerLatency"] = obj({"user_id": S, "username": S, "time_to_review": ref("Latency"), "time_to_merge": ref("Latency")}, ["user_id", "username", "time_to_review", "time_to_merge"])
+schemas["LatencyReport"] = obj({"from": DT, "to": DT, "overall": ref("TeamLatency"), "teams": arr(ref("TeamLatency")), "reviewers": arr(ref("ReviewerLatency"))},
+    ["from", "to", "overall", "teams", "reviewers"])
+schemas["MemberWorkload"] = obj({"user_id": S, "username": S, "assignments": I, "reassigned_away": I, "active_seconds": N,
+    "actual_share": N, "expected_share": N, "ratio": nullable(N)},
+    ["user_id", "username", "assignments", "reassigned_away", "active_seconds", "actual_share", "expected_share", "ratio"])
+schemas["TeamFairness"] = obj({"team_name": S, "total_assignments": I, "gini": nullable(N), "max_min_ratio": nullable(N),
+    "members": arr(ref("MemberWorkload")), "outliers": arr(S)}, ["team_name", "total_assignments", "gini", "max_min_ratio", "members", "outliers"])
+schemas["FairnessReport"] = obj({"from": DT, "to": DT, "threshold": N, "teams": arr(ref("TeamFairness"))}, ["from", "to", "threshold", "teams"])
+schemas["Health"] = obj({"status": {"type": "string", "enum": ["ok", "unavailable"]}, "version": S}, ["status"])
+schemas["HealthCheck"] = obj({"status": {"type": "string", "enum": ["ok", "unavailable"]}, "error": S, "details": {"type": "object", "additionalProperties": True}}, ["status"])
+schemas["HealthReport"] = obj({"status": {"type": "string", "enum": ["ok", "unavailable"]}, "version": S, "checks": mapof(ref("HealthCheck"))}, ["status", "version", "checks"])
+schemas["APIToken"] = obj({"id": I64, "org_id": I64, "name": S, "prefix": S,
+    "scopes": arr({"type": "string", "enum": ["admin", "team:write", "pr:write", "read"]}), "user_id": S,
+    "created_at": DT, "expires_at": DT, "last_used_at": DT, "revoked_at": DT}, ["id", "org_id", "name", "prefix", "scopes", "created_at"])
+schemas["CreateTokenRequest"] = obj({"name": S, "scopes": arr({"type": "string", "enum": ["admin", "team:write", "pr:write", "read"]}),
+    "user_id": S, "expires_at": DT}, ["name", "scopes"])
+schemas["CreatedToken"] = obj({"token": {"type": "string", "description": "Значение токена; показывается один раз"}, "api_token": ref("APIToken")}, ["token", "api_token"])
+schemas["TokenList"] = obj({"tokens": arr(ref("APIToken"))}, ["tokens"])
+schemas["RevokeTokenRequest"] = obj({"id": I64}, ["id"])
+schemas["RevokedToken"] = obj({"revoked_id": I64}, ["revoked_id"])
+schemas["MemberSpec"] = obj({"user_id": S, "username": S,
+    "is_active": {"type": "boolean", "description": "По умолчанию true"},
+    "role": {"type": "string", "enum": ["member", "lead"], "description": "По умолчанию member"}}, ["user_id", "username"])
+schemas["TeamSpec"] = obj({
+    "name": S,
+    "min_reviewers": {"type": "integer", "minimum": 0, "description": "По умолчанию 1"},
+    "max_reviewers": {"type": "integer", "minimum": 0, "description": "По умолчанию 2"},
+    "reminder_after_minutes": {"type": "integer", "minimum": 0, "description": "0 - SLA по умолчанию"},
+    "escalation_after_minutes": {"type": "integer", "minimum": 0, "description": "0 - SLA по умолчанию"},
+    "buddy_teams": {"type": "array", "items": S, "description": "Команды-партнеры в порядке приоритета"},
+    "members": arr(ref("MemberSpec"))}, ["name"])
+schemas["TeamsDocument"] = obj({"teams": {"type": "array", "minItems": 1, "items": ref("TeamSpec")}}, ["teams"],
+    "Желаемый состав команд. Активные участники, которых нет в документе, деактивируются")
+SYNC_ACTION = {"type": "string", "enum": ["create", "update", "move", "deactivate"]}
+schemas["TeamChange"] = obj({"action": SYNC_ACTION, "team": S,
+    "changes": {"type": "array", "items": S, "description": "Измененные поля в виде \"поле: было -> стало\""}}, ["action", "team"])
+schemas["UserChange"] = obj({"action": SYNC_ACTION, "user_id": S, "team": S,
+    "from_team": {"type": "string", "description": "Прежняя команда при переводе"}, "changes": arr(S)}, ["action", "user_id", "team"])
+schemas["SyncReassignment"] = obj({"pull_request_id": S, "old_reviewer_id": S,
+    "new_reviewer_id": {"type": "string", "description": "Замена; нет в dry run и если замены не нашлось"}}, ["pull_request_id", "old_reviewer_id"])
+schemas["SyncPlan"] = obj({"dry_run": B, "teams": arr(ref("TeamChange")), "users": arr(ref("UserChange")),
+    "reassignments": arr(ref("SyncReassignment"))}, ["dry_run", "teams", "users", "reassignments"])
+
+def envelope(s): return obj({"data": s}, ["data"])
+def jcontent(s): return {"application/json": {"schema": s}}
+
+ERRORS = {
+    "400": "Некорректный запрос", "401": "Нет действующего токена", "403": "Недостаточно прав",
+    "404": "Ресурс не найден", "409": "Конфликт состояния или версии", "413": "Слишком большое тело запроса",
+    "422": "Ключ идемпотентности использован с другим запросом", "429": "Превышен лимит запросов", "500": "Внутренняя ошибка",
+}
+def err(code): return {"$ref": "#/components/responses/E" + code}
+
+ETAG = {"ETag": {"$ref": "#/components/headers/ETag"}}
+LOCATION = {"Location": {"description": "Адрес созданного ресурса", "schema": S}}
+
+def resp(status, desc, schema, headers=None):
+    r = {"description": desc, "content": jcontent(schema)}
+    if headers: r["headers"] = headers
+    return status, r
+
+def op(tag, summary, responses, errors, params=None, body=None, body_required=True, desc=None, security=None, write=False):
+    o = O()
+    o["tags"] = [tag]
+    o["summary"] = summary
+    if desc: o["description"] = desc
+    ps = list(params or [])
+    if write:
+        ps += [{"$ref": "#/components/parameters/IfMatch"}]
+        if write == "post":
+            ps += [{"$ref": "#/components/parameters/IdempotencyKey"}]
+    if security is None:
+        ps += [{"$ref": "#/components/parameters/XOrg"}]
+    if ps: o["parameters"] = ps
+    if body is not None:
+        o["requestBody"] = {"required": body_required, "content": jcontent(body)}
+    rs = O()
+    for status, r in responses: rs[status] = r
+    std = ["401", "403", "429", "500"] if security is None else []
+    if body is not None or write: std += ["413"]
+    if write == "post": std += ["409", "422"]
+    for code in sorted(set(errors) | set(std)):
+        rs[code] = err(code)
+    o["responses"] = rs
+    if security is not None: o["security"] = security
+    return o
+
+def q(name, desc, required=False, schema=S):
+    return {"name": name, "in": "query", "required": required, "description": desc, "schema": schema}
+def p(name, desc):
+    return {"name": name, "in": "path", "required": True, "description": desc, "schema": S}
+
+prListParams = [
+    q("status", "Статус PR", schema={"type": "string", "enum": ["OPEN", "MERGED"]}),
+    q("team", "Команда автора"),
+    q("author_id", "ID автора"),
+    q("created_after", "Созданные позже (RFC3339 или YYYY-MM-DD)"),
+    q("sort", "Порядок по времени создания", schema={"type": "string", "enum": ["created_at", "-created_at"], "default": "-created_at"}),
+    q("limit", "Размер страницы", schema={"type": "integer", "minimum": 1, "maximum": 100, "default": 50}),
+    q("cursor", "Курсор next_cursor предыдущей страницы"),
+]
+# v1 /users/getReview без limit и cursor отдает все PR
+reviewListParams = [p if p["name"] != "limit" else q("limit", "Размер страницы; без limit и cursor - все PR", schema={"type": "integer", "minimum": 1, "maximum": 100})
+                    for p in prListParams]
+statsParams = [q("from", "Начало периода (RFC3339 или YYYY-MM-DD)"), q("to", "Конец периода, не включая (RFC3339 или YYYY-MM-DD)"),
+               q("team_name", "Команда")]
+
+paths = O()
+OPEN = []
+paths["/health"] = {"get": op("Health", "Проверка liveness (синоним /health/live)", [resp("200", "Процесс жив", envelope(ref("Health")))], [], security=OPEN)}
+paths["/health/live"] = {"get": op("Health", "Проверка liveness", [resp("200", "Процесс жив", envelope(ref("Health")))], [], security=OPEN,
+    desc="Не обращается к зависимостям: ответ 200 означает только, что процесс не завис")}
+paths["/health/ready"] = {"get": op("Health", "Проверка readiness",
+    [resp("200", "Сервис готов", envelope(ref("HealthReport"))), resp("503", "Сервис не готов", envelope(ref("HealthReport")))], [], security=OPEN,
+    desc="Ping PostgreSQL, проверка версии схемы и заполненности пула соединений")}
+paths["/metrics"] = {"get": op("Health", "Метрики Prometheus", [("200", {"description": "Метрики в текстовом формате Prometheus", "content": {"text/plain": {"schema": S}}})], [], security=OPEN)}
+paths["/openapi.json"] = {"get": op("Docs", "Спецификация OpenAPI 3", [("200", {"description": "Этот документ", "content": {"application/json": {"schema": {"type": "object"}}}})], [], security=OPEN)}
+paths["/docs"] = {"get": op("Docs", "Swagger UI", [("200", {"description": "HTML-страница Swagger UI", "content": {"text/html": {"schema": S}}})], [], security=OPEN)}
+
+# v1
+paths["/team/add"] = {"post": op("Teams", "Создать команду с участниками", [resp("201", "Команда создана", ref("TeamResponse"), ETAG)], ["400"], body=ref("TeamInput"), write="post",
+    desc="Создает команду и создает или обновляет ее участников")}
+paths["/team/get"] = {"get": op("Teams", "Получить команду с участниками", [resp("200", "Команда", ref("Team"), ETAG)], ["400", "404"], params=[q("team_name", "Название команды", True)])}
+paths["/team/setBuddies"] = {"post": op("Teams", "Задать команды-партнеры", [resp("200", "Команда", ref("TeamResponse"), ETAG)], ["400", "404"], body=ref("SetBuddyTeamsRequest"), write="post")}
+paths["/users/setIsActive"] = {"post": op("Users", "Установить флаг активности пользователя", [resp("200", "Пользователь", ref("UserResponse"), ETAG)], ["400", "404"], body=ref("SetIsActiveRequest"), write="post")}
+paths["/users/getReview"] = {"get": op("Users", "PR, где пользователь назначен ревьювером", [resp("200", "Страница PR пользователя", ref("PRPage"))], ["400", "404"],
+    params=[q("user_id", "ID пользователя", True)] + reviewListParams,
+    desc="По умолчанию сначала новые; фильтры - как у /pullRequest/list. Без limit и cursor возвращает все PR, с ними - страницу и next_cursor следующей")}
+paths["/pullRequest/get"] = {"get": op("PullRequests", "Получить PR", [resp("200", "PR", ref("PRResponse"), ETAG)], ["400", "404"], params=[q("pull_request_id", "ID PR", True)])}
+paths["/pullRequest/list"] = {"get": op("PullRequests", "Список PR", [resp("200", "Страница PR", ref("PRPage"))], ["400", "404"], params=prListParams,
+    desc="PR с авторами, по умолчанию сначала новые. Следующая страница запрашивается с cursor из next_cursor. Неизвестные team и author_id - 404")}
+paths["/pullRequest/create"] = {"post": op("PullRequests", "Создать PR и назначить ревьюверов", [resp("201", "PR создан", ref("PRResponse"), ETAG)], ["400", "404"], body=ref("CreatePRRequest"), write="post")}
+paths["/pullRequest/batchCreate"] = {"post": op("PullRequests", "Создать пакет PR и назначить ревьюверов", [resp("200", "Пакет обработан", ref("BatchCreatePRResponse"))], ["400"], body=ref("BatchCreatePRRequest"), write="post", desc="Ревьюверы выбираются с учетом нагрузки внутри пакета. С atomic=true PR создаются, только если ни у одного нет ошибки; иначе каждый PR создается независимо.")}
+paths["/pullRequest/merge"] = {"post": op("PullRequests", "Пометить PR как MERGED (идемпотентно)", [resp("200", "PR", ref("PRResponse"), ETAG)], ["400", "404"], body=ref("MergePRRequest"), write="post")}
+paths["/pullRequest/reassign"] = {"post": op("PullRequests", "Переназначить ревьювера", [resp("200", "PR и новый ревьювер", ref("ReassignReviewerResponse"), ETAG)], ["400", "404"], body=ref("ReassignReviewerRequest"), write="post")}
+paths["/pullRequest/addReviewer"] = {"post": op("PullRequests", "Назначить конкретного ревьювера", [resp("200", "PR", ref("PRResponse"), ETAG)], ["400", "404"], body=ref("ReviewerRequest"), write="post")}
+paths["/pullRequest/removeReviewer"] = {"post": op("PullRequests", "Снять ревьювера", [resp("200", "PR", ref("PRResponse"), ETAG)], ["400", "404"], body=ref("ReviewerRequest"), write="post")}
+paths["/pullRequest/review"] = {"post": op("PullRequests", "Отметить ревью", [resp("200", "PR", ref("PRResponse"), ETAG)], ["400", "404"], body=ref("ReviewerRequest"), write="post")}
+paths["/stats"] = {"get": op("Stats", "Статистика сервиса", [resp("200", "Статистика", envelope(ref("Stats")))], ["400", "404"],
+    params=statsParams + [q("limit", "Размер top_reviewers (1-100)", schema={"type": "integer", "minimum": 1, "maximum": 100, "default": 10})])}
+paths["/stats/pairs"] = {"get": op("Stats", "Матрица автор -> ревьювер", [resp("200", "Пары", envelope(ref("ReviewPairs")))], ["400"],
+    params=[q("days", "Период в днях; по умолчанию assignment.rotation_lookback (30 дней)", schema={"type": "integer", "minimum": 1})],
+    desc="Назначения за период, включая снятых и переназначенных ревьюверов")}
+paths["/stats/latency"] = {"get": op("Stats", "Задержки ревью и мержа", [resp("200", "Отчет", envelope(ref("LatencyReport")))], ["400", "404"], params=statsParams)}
+paths["/stats/fairness"] = {"get": op("Stats", "Равномерность нагрузки", [resp("200", "Отчет", envelope(ref("FairnessReport")))], ["400", "404"],
+    params=statsParams + [q("threshold", "Допустимое отклонение доли от ожидаемой", schema={"type": "number", "default": 0.5})])}
+paths["/admin/tokens"] = {
+    "post": op("Admin", "Выпустить токен", [resp("201", "Токен", ref("CreatedToken"))], ["400", "404"], body=ref("CreateTokenRequest"), write="post"),
+    "get": op("Admin", "Список токенов", [resp("200", "Токены организации", ref("TokenList"))], []),
+}
+paths["/admin/tokens/revoke"] = {"post": op("Admin", "Отозвать токен", [resp("200", "Токен отозван", ref("RevokedToken"))], ["400", "404"], body=ref("RevokeTokenRequest"), write="post")}
+paths["/admin/sync"] = {"post": op("Admin", "Синхронизировать команды с документом", [resp("200", "План синхронизации", ref("SyncPlan"))], ["400", "404"],
+    params=[q("dry_run", "Только показать план", schema={"type": "boolean", "default": False})], body=ref("TeamsDocument"), write="post",
+    desc="Создает и меняет команды и участников, деактивирует активных участников, которых нет в документе, и переназначает их открытые ревью. Все изменения применяются в одной транзакции.")}
+paths["/admin/sync"]["post"]["requestBody"]["content"]["application/yaml"] = {"schema": ref("TeamsDocument")}
+
+# v2
+name, uid, prid = p("name", "Название команды"), p("id", "ID пользователя"), p("id", "ID PR")
+rid = p("user_id", "ID ревьювера")
+assignments = envelope(arr(ref("ReviewerAssignment")))
+paths["/api/v2/teams"] = {"post": op("v2 Teams", "Создать команду", [resp("201", "Команда создана", envelope(ref("Team")), dict(ETAG, **LOCATION))], ["400"], body=ref("TeamInput"), write="post")}
+paths["/api/v2/teams/{name}"] = {
+    "get": op("v2 Teams", "Получить команду", [resp("200", "Команда", envelope(ref("Team")), ETAG)], ["404"], params=[name]),
+    "patch": op("v2 Teams", "Изменить команды-партнеры", [resp("200", "Команда", envelope(ref("Team")), ETAG)], ["400", "404", "409"], params=[name], body=ref("V2UpdateTeamRequest"), write="patch"),
+}
+paths["/api/v2/teams/{name}/members"] = {"get": op("v2 Teams", "Участники команды", [resp("200", "Участники", envelope(arr(ref("User"))))], ["404"], params=[name])}
+paths["/api/v2/users/{id}"] = {
+    "get": op("v2 Users", "Получить пользователя", [resp("200", "Пользователь", envelope(ref("User")), ETAG)], ["404"], params=[uid]),
+    "patch": op("v2 Users", "Изменить флаг активности", [resp("200", "Пользователь", envelope(ref("User")), ETAG)], ["400", "404", "409"], params=[uid], body=ref("V2UpdateUserRequest"), write="patch"),
+}
+paths["/api/v2/users/{id}/reviews"] = {"get": op("v2 Users", "PR, где пользователь назначен ревьювером",
+    [resp("200", "Страница PR", obj({"data": arr(ref("PullRequest")), "meta": ref("PageMeta")}, ["data"]))], ["400", "404"], params=[uid] + prListParams,
+    desc="Параметры - как у /pullRequest/list, курсор следующей страницы - в meta.next_cursor")}
+paths["/api/v2/pull-requests"] = {"post": op("v2 PullRequests", "Создать PR", [resp("201", "PR создан", envelope(ref("PullRequest")), dict(ETAG, **LOCATION))], ["400", "404"], body=ref("CreatePRRequest"), write="post")}
+paths["/api/v2/pull-requests/{id}"] = {"get": op("v2 PullRequests", "Получить PR", [resp("200", "PR", envelope(ref("PullRequest")), ETAG)], ["404"], params=[prid])}
+paths["/api/v2/pull-requests/{id}:merge"] = {"post": op("v2 PullRequests", "Пометить PR как MERGED (идемпотентно)", [resp("200", "PR", envelope(ref("PullRequest")), ETAG)], ["404"], params=[prid], write="post")}
+paths["/api/v2/pull-requests/{id}/reviewers"] = {
+    "get": op("v2 PullRequests", "Ревьюверы PR", [resp("200", "Назначения", assignments, ETAG)], ["404"], params=[prid]),
+    "post": op("v2 PullRequests", "Назначить ревьювера", [resp("201", "Назначения", assignments, ETAG)], ["400", "404"], params=[prid], body=ref("V2AddReviewerRequest"), write="post"),
+}
+paths["/api/v2/pull-requests/{id}/reviewers/{user_id}"] = {"delete": op("v2 PullRequests", "Снять ревьювера", [resp("200", "Назначения", assignments, ETAG)], ["404", "409"], params=[prid, rid], write="delete")}
+paths["/api/v2/pull-requests/{id}/reviewers/{user_id}:reassign"] = {"post": op("v2 PullRequests", "Заменить ревьювера",
+    [resp("200", "Новый ревьювер и назначения", envelope(ref("V2ReassignResponse")), ETAG)], ["400", "404"], params=[prid, rid], body=ref("V2ReassignRequest"), body_required=False, write="post")}
+paths["/api/v2/pull-requests/{id}/reviewers/{user_id}:review"] = {"post": op("v2 PullRequests", "Отметить ревью", [resp("200", "Назначения", assignments, ETAG)], ["404"], params=[prid, rid], write="post")}
+
+responses = O()
+for code, desc in sorted(ERRORS.items()):
+    responses["E" + code] = {"description": desc, "content": jcontent(ref("ErrorResponse"))}
+
+spec = O()
+spec["openapi"] = "3.0.3"
+spec["info"] = {"title": "Reviewer Appointment Service", "version": "2.0.0",
+    "description": "Сервис назначения ревьюверов на pull request'ы. API v1 - RPC-эндпоинты в корне, API v2 - ресурсы под /api/v2 с единым конвертом ответа {data} или {error}."}
+spec["servers"] = [{"url": "/"}]
+spec["security"] = [{"bearerAuth": []}]
+spec["tags"] = [{"name": t} for t in ["Teams", "Users", "PullRequests", "Stats", "Admin", "v2 Teams", "v2 Users", "v2 PullRequests", "Health", "Docs"]]
+spec["paths"] = paths
+spec["components"] = O([
+    ("securitySchemes", {"bearerAuth": {"type": "http", "scheme": "bearer", "description": "Токен API (ras_...)"}}),
+    ("parameters", O([
+        ("IfMatch", {"name": "If-Match", "in": "header", "required": False, "description": "ETag ресурса: изменение выполнится, только если версия не менялась", "schema": S}),
+        ("IdempotencyKey", {"name": "Idempotency-Key", "in": "header", "required": False, "description": "Ключ идемпотентности (1-255 символов)", "schema": {"type": "string", "minLength": 1, "maxLength": 255}}),
+        ("XOrg", {"name": "X-Org", "in": "header", "required": False, "description": "Slug организации", "schema": S}),
+    ])),
+    ("headers", {"ETag": {"description": "Версия ресурса для If-Match", "schema": S}}),
+    ("responses", responses),
+    ("schemas", schemas),
+])
+
+OUTPUT = os.path.join(os.path.dirname(os.path.abspath(__file__)), "..", "api", "openapi.json")
+
+with open(OUTPUT, "w") as f:
+    json.dump(spec, f, ensure_ascii=False, indent=2)
+    f.write("\n")
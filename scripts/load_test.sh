@@ -4,6 +4,12 @@
 # Requires: Apache Bench (ab) or hey
 
 BASE_URL="${BASE_URL:-http://localhost:8081}"
+# Token with team:write, pr:write and read scopes (or admin):
+#   reviewer-appointment-service token create -name loadtest -scopes admin
+API_TOKEN="${API_TOKEN:-}"
+# The service rate-limits each token; start it with RATE_LIMIT_ENABLED=false
+# (or raise RATE_LIMIT_RATE/RATE_LIMIT_BURST) to measure raw throughput.
+AUTH_HEADER="Authorization: Bearer $API_TOKEN"
 
 echo "=== Load Testing Reviewer Appointment Service ==="
 echo "Base URL: $BASE_URL"
@@ -32,6 +38,7 @@ echo -e "${YELLOW}Setting up test data...${NC}"
 # Create a test team
 curl -s -X POST "$BASE_URL/team/add" \
   -H "Content-Type: application/json" \
+  -H "$AUTH_HEADER" \
   -d '{
     "team_name": "loadtest",
     "members": [
@@ -47,6 +54,7 @@ curl -s -X POST "$BASE_URL/team/add" \
 for i in {1..10}; do
   curl -s -X POST "$BASE_URL/pullRequest/create" \
     -H "Content-Type: application/json" \
+    -H "$AUTH_HEADER" \
     -d "{
       \"pull_request_id\": \"pr-load-$i\",
       \"pull_request_name\": \"Load Test PR $i\",
@@ -68,23 +76,23 @@ echo ""
 
 echo -e "${YELLOW}=== Test 2: Get Team (Moderate Load) ===${NC}"
 if [ "$TOOL" = "hey" ]; then
-    hey -n 500 -c 5 -m GET "$BASE_URL/team/get?team_name=loadtest"
+    hey -n 500 -c 5 -m GET -H "$AUTH_HEADER" "$BASE_URL/team/get?team_name=loadtest"
 else
-    ab -n 500 -c 5 "$BASE_URL/team/get?team_name=loadtest"
+    ab -n 500 -c 5 -H "$AUTH_HEADER" "$BASE_URL/team/get?team_name=loadtest"
 fi
 echo ""
 
 echo -e "${YELLOW}=== Test 3: Get Statistics (Low RPS) ===${NC}"
 if [ "$TOOL" = "hey" ]; then
-    hey -n 100 -c 2 -m GET "$BASE_URL/statistics"
+    hey -n 100 -c 2 -m GET -H "$AUTH_HEADER" "$BASE_URL/statistics"
 else
-    ab -n 100 -c 2 "$BASE_URL/statistics"
+    ab -n 100 -c 2 -H "$AUTH_HEADER" "$BASE_URL/statistics"
 fi
 echo ""
 
 echo -e "${YELLOW}=== Test 4: Create PR (Target: 5 RPS) ===${NC}"
 if [ "$TOOL" = "hey" ]; then
-    hey -n 50 -c 1 -m POST -H "Content-Type: application/json" \
+    hey -n 50 -c 1 -m POST -H "Content-Type: application/json" -H "$AUTH_HEADER" \
       -d '{"pull_request_id":"pr-load-test","pull_request_name":"Load Test","author_id":"lt2"}' \
       "$BASE_URL/pullRequest/create"
 else